@@ -4,7 +4,13 @@ CREATE TABLE IF NOT EXISTS entries (
   `id`         int          NOT NULL AUTO_INCREMENT,
   `title`      varchar(256) NOT NULL,
   `content`    text         NOT NULL,
+  `source`     text         NULL,
   `status`     int          NOT NULL,
+  `slug`       varchar(256) NULL UNIQUE,
+  `summary`    varchar(512) NOT NULL DEFAULT '',
+  `publish_at` DATETIME     NULL,
+  `author_id`  int          NOT NULL DEFAULT 0,
+  `version`    int          NOT NULL DEFAULT 1,
   `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (id)
@@ -13,7 +19,96 @@ CREATE TABLE IF NOT EXISTS entries (
 CREATE TABLE IF NOT EXISTS tokens (
   `id`         int          NOT NULL AUTO_INCREMENT,
   `value`      varchar(256) NOT NULL UNIQUE,
+  `prefix`     varchar(16)  NOT NULL DEFAULT '',
+  `user_id`    int          NOT NULL DEFAULT 0,
+  `label`      varchar(64)  NOT NULL DEFAULT '',
+  `last_used_at` DATETIME   NULL,
+  `expires_at` DATETIME     NULL,
   `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX tokens_user_id (user_id),
+  INDEX tokens_prefix (prefix)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS entry_revisions (
+  `id`         int          NOT NULL AUTO_INCREMENT,
+  `entry_id`   int          NOT NULL,
+  `title`      varchar(256) NOT NULL,
+  `content`    text         NOT NULL,
+  `source`     text         NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX entry_revisions_entry_id (entry_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS tags (
+  `id`         int          NOT NULL AUTO_INCREMENT,
+  `name`       varchar(64)  NOT NULL UNIQUE,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS entry_tags (
+  `entry_id`   int          NOT NULL,
+  `tag_id`     int          NOT NULL,
+  PRIMARY KEY (entry_id, tag_id),
+  INDEX entry_tags_tag_id (tag_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS entry_slug_redirects (
+  `slug`       varchar(256) NOT NULL,
+  `entry_id`   int          NOT NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (slug),
+  INDEX entry_slug_redirects_entry_id (entry_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS assets (
+  `id`           int          NOT NULL AUTO_INCREMENT,
+  `hash`         varchar(64)  NOT NULL UNIQUE,
+  `name`         varchar(256) NOT NULL,
+  `content_type` varchar(128) NOT NULL,
+  `size`         bigint       NOT NULL,
+  `created_at`   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
   PRIMARY KEY (id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS entry_assets (
+  `entry_id`   int          NOT NULL,
+  `link`       varchar(256) NOT NULL,
+  `hash`       varchar(64)  NOT NULL,
+  PRIMARY KEY (entry_id, link)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS entry_paths (
+  `path`       varchar(256) NOT NULL,
+  `entry_id`   int          NOT NULL,
+  PRIMARY KEY (path),
+  INDEX entry_paths_entry_id (entry_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS users (
+  `id`         int          NOT NULL AUTO_INCREMENT,
+  `name`       varchar(64)  NOT NULL UNIQUE,
+  `role`       varchar(16)  NOT NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+  `id`          int          NOT NULL AUTO_INCREMENT,
+  `action`      varchar(16)  NOT NULL,
+  `entry_id`    int          NOT NULL,
+  `user_id`     int          NOT NULL DEFAULT 0,
+  `token_id`    int          NOT NULL DEFAULT 0,
+  `remote_addr` varchar(64)  NOT NULL DEFAULT '',
+  `before_hash` varchar(64)  NOT NULL DEFAULT '',
+  `after_hash`  varchar(64)  NOT NULL DEFAULT '',
+  `created_at`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX audit_logs_entry_id (entry_id),
+  INDEX audit_logs_user_id (user_id),
+  INDEX audit_logs_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
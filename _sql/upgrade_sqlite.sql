@@ -0,0 +1,100 @@
+-- Upgrades the database created by the schema with only the entries and the tokens to the current schema.
+-- Run "lumber token migrate" after this to hash the plain values of the tokens.
+ALTER TABLE entries ADD COLUMN `source` text NULL;
+ALTER TABLE entries ADD COLUMN `slug` varchar(256) NULL;
+ALTER TABLE entries ADD COLUMN `summary` varchar(512) NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN `publish_at` DATETIME NULL;
+ALTER TABLE entries ADD COLUMN `author_id` int NOT NULL DEFAULT 0;
+ALTER TABLE entries ADD COLUMN `version` int NOT NULL DEFAULT 1;
+CREATE UNIQUE INDEX IF NOT EXISTS entries_slug ON entries (slug);
+
+ALTER TABLE tokens ADD COLUMN `prefix` varchar(16) NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN `user_id` int NOT NULL DEFAULT 0;
+ALTER TABLE tokens ADD COLUMN `label` varchar(64) NOT NULL DEFAULT '';
+ALTER TABLE tokens ADD COLUMN `last_used_at` DATETIME NULL;
+ALTER TABLE tokens ADD COLUMN `expires_at` DATETIME NULL;
+CREATE INDEX IF NOT EXISTS tokens_user_id ON tokens (user_id);
+CREATE INDEX IF NOT EXISTS tokens_prefix ON tokens (prefix);
+
+CREATE TABLE IF NOT EXISTS entry_revisions (
+  `id`         integer      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `entry_id`   int          NOT NULL,
+  `title`      varchar(256) NOT NULL,
+  `content`    text         NOT NULL,
+  `source`     text         NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS entry_revisions_entry_id ON entry_revisions (entry_id);
+
+CREATE TABLE IF NOT EXISTS tags (
+  `id`         integer      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `name`       varchar(64)  NOT NULL UNIQUE,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS entry_tags (
+  `entry_id`   int          NOT NULL,
+  `tag_id`     int          NOT NULL,
+  PRIMARY KEY (entry_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS entry_tags_tag_id ON entry_tags (tag_id);
+
+CREATE TABLE IF NOT EXISTS entry_slug_redirects (
+  `slug`       varchar(256) NOT NULL PRIMARY KEY,
+  `entry_id`   int          NOT NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS entry_slug_redirects_entry_id ON entry_slug_redirects (entry_id);
+
+CREATE TABLE IF NOT EXISTS assets (
+  `id`           integer      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `hash`         varchar(64)  NOT NULL UNIQUE,
+  `name`         varchar(256) NOT NULL,
+  `content_type` varchar(128) NOT NULL,
+  `size`         bigint       NOT NULL,
+  `created_at`   DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS entry_assets (
+  `entry_id`   int          NOT NULL,
+  `link`       varchar(256) NOT NULL,
+  `hash`       varchar(64)  NOT NULL,
+  PRIMARY KEY (entry_id, link)
+);
+
+CREATE TABLE IF NOT EXISTS entry_paths (
+  `path`       varchar(256) NOT NULL PRIMARY KEY,
+  `entry_id`   int          NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+  `id`         integer      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `name`       varchar(64)  NOT NULL UNIQUE,
+  `role`       varchar(16)  NOT NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER IF NOT EXISTS users_updated_at AFTER UPDATE ON users
+BEGIN
+  UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+CREATE TABLE IF NOT EXISTS audit_logs (
+  `id`          integer      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  `action`      varchar(16)  NOT NULL,
+  `entry_id`    int          NOT NULL,
+  `user_id`     int          NOT NULL DEFAULT 0,
+  `token_id`    int          NOT NULL DEFAULT 0,
+  `remote_addr` varchar(64)  NOT NULL DEFAULT '',
+  `before_hash` varchar(64)  NOT NULL DEFAULT '',
+  `after_hash`  varchar(64)  NOT NULL DEFAULT '',
+  `created_at`  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_entry_id ON audit_logs (entry_id);
+CREATE INDEX IF NOT EXISTS audit_logs_user_id ON audit_logs (user_id);
+CREATE INDEX IF NOT EXISTS audit_logs_created_at ON audit_logs (created_at);
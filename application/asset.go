@@ -0,0 +1,127 @@
+package application
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// assetContentTypes is the allowed types of the uploading files
+// The types are sniffed from the content, not trusted the client
+var assetContentTypes = map[string]bool{
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+	"video/mp4":       true,
+	"video/webm":      true,
+	"audio/mpeg":      true,
+	"audio/wave":      true,
+	"application/ogg": true,
+}
+
+// AssetInteractor provides the operation of the uploaded files
+type AssetInteractor struct {
+	assetRepo repository.AssetRepository
+	storage   repository.AssetStorage
+	maxBytes  int64
+}
+
+// NewAssetInteractor returns initialized AssetInteractor
+func NewAssetInteractor(a repository.AssetRepository, s repository.AssetStorage, maxBytes int64) *AssetInteractor {
+	return &AssetInteractor{
+		assetRepo: a,
+		storage:   s,
+		maxBytes:  maxBytes,
+	}
+}
+
+// Upload stores the file and returns the asset
+// Returns the existing asset when the same content has already been uploaded
+func (i *AssetInteractor) Upload(name string, r io.Reader) (*domain.Asset, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, i.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, config.ErrEmptyAsset
+	}
+	if int64(len(data)) > i.maxBytes {
+		return nil, config.ErrAssetSizeLimitExceeded
+	}
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !assetContentTypes[contentType] {
+		return nil, errors.Wrapf(config.ErrUnsupportedAssetType, "content type: %s", contentType)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	if asset, err := i.assetRepo.Get(hash); err != domain.ErrNotFoundAsset {
+		return asset, err
+	}
+
+	if err := i.storage.Put(hash, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "failed to store the asset")
+	}
+	_, err = i.assetRepo.Save(&domain.Asset{
+		Hash:        hash,
+		Name:        assetName(name),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	})
+	if err != nil {
+		// the same content may be uploaded concurrently
+		if asset, getErr := i.assetRepo.Get(hash); getErr == nil {
+			return asset, nil
+		}
+		return nil, err
+	}
+	return i.assetRepo.Get(hash)
+}
+
+// Find returns the asset matched by the hash without the content
+func (i *AssetInteractor) Find(hash string) (*domain.Asset, error) {
+	return i.assetRepo.Get(hash)
+}
+
+// Get returns the asset and the content matched by the hash
+// The content must be closed by the caller
+func (i *AssetInteractor) Get(hash string) (*domain.Asset, io.ReadCloser, error) {
+	asset, err := i.assetRepo.Get(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := i.storage.Open(hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return asset, body, nil
+}
+
+// assetName returns the base name of the file shortened within MaxAssetNameBytes
+func assetName(name string) string {
+	// the client may send the full path including the backslashes
+	name = path.Base(strings.Replace(name, "\\", "/", -1))
+	if name == "." || name == "/" {
+		return ""
+	}
+	for len(name) > config.MaxAssetNameBytes {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,74 @@
+package application
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/infrastructure/memory"
+)
+
+func TestUploadAsset(t *testing.T) {
+	png, err := ioutil.ReadFile("testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	interactor := NewAssetInteractor(memory.NewAssetRepository(), memory.NewAssetStorage(), 1024)
+
+	cases := []struct {
+		name              string
+		data              []byte
+		expectName        string
+		expectContentType string
+		expectErr         error
+	}{
+		{"image.png", png, "image.png", "image/png", nil},
+		{`C:\Users\foo\image.png`, png, "image.png", "image/png", nil},
+		{"image.gif", []byte("GIF89a..."), "image.gif", "image/gif", nil},
+		{"index.html", []byte("<html><script>alert(1)</script></html>"), "", "", config.ErrUnsupportedAssetType},
+		{"image.svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`), "", "", config.ErrUnsupportedAssetType},
+		{"empty.png", []byte{}, "", "", config.ErrEmptyAsset},
+		{"large.png", append(png, make([]byte, 1024)...), "", "", config.ErrAssetSizeLimitExceeded},
+	}
+	for i, c := range cases {
+		asset, err := interactor.Upload(c.name, bytes.NewReader(c.data))
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if asset.Name != c.expectName || asset.ContentType != c.expectContentType || asset.Size != int64(len(c.data)) {
+			t.Errorf("#%d: want name %q, type %q and size %d, got %#v", i, c.expectName, c.expectContentType, len(c.data), asset)
+		}
+
+		got, body, err := interactor.Get(asset.Hash)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		data, _ := ioutil.ReadAll(body)
+		body.Close()
+		if got.ID != asset.ID || !bytes.Equal(data, c.data) {
+			t.Errorf("#%d: want id %d and data %q, got %d and %q", i, asset.ID, c.data, got.ID, data)
+		}
+	}
+}
+
+func TestUploadDuplicateAsset(t *testing.T) {
+	interactor := NewAssetInteractor(memory.NewAssetRepository(), memory.NewAssetStorage(), 1024)
+
+	first, err := interactor.Upload("first.gif", strings.NewReader("GIF89a"))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	second, err := interactor.Upload("second.gif", strings.NewReader("GIF89a"))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if first.ID != second.ID || second.Name != "first.gif" {
+		t.Errorf("want the same asset %#v, got %#v", first, second)
+	}
+}
@@ -0,0 +1,38 @@
+package application
+
+import (
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// AuditInteractor provides operation for the audit logs
+type AuditInteractor struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditInteractor returns initialized AuditInteractor
+func NewAuditInteractor(a repository.AuditRepository) *AuditInteractor {
+	return &AuditInteractor{
+		auditRepo: a,
+	}
+}
+
+// Find returns the audit logs matched by the filter ordered by the newest
+// The limit is DefaultAuditLimit unless specified
+func (i *AuditInteractor) Find(f repository.AuditFilter) ([]*domain.AuditLog, error) {
+	if len(f.Action) != 0 && !f.Action.IsValid() {
+		return nil, errors.Wrapf(config.ErrInvalidAuditFilter, "action: %s", f.Action)
+	}
+	if f.Limit < 0 || f.Limit > config.MaxAuditLimit {
+		return nil, errors.Wrapf(config.ErrInvalidAuditFilter, "limit: %d", f.Limit)
+	}
+	if f.Since != nil && f.Until != nil && !f.Since.Before(*f.Until) {
+		return nil, errors.Wrapf(config.ErrInvalidAuditFilter, "since: %s, until: %s", f.Since, f.Until)
+	}
+	if f.Limit == 0 {
+		f.Limit = config.DefaultAuditLimit
+	}
+	return i.auditRepo.Find(f)
+}
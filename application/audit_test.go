@@ -0,0 +1,103 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/memory"
+)
+
+func TestAuditEntry(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+	getAuditRepository(t).(*memory.AuditRepositoryImpl).Reset()
+
+	actor := domain.Actor{UserID: 2, TokenID: 3, RemoteAddr: "192.0.2.1"}
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t)).WithActor(actor)
+	element, err := NewEntryElement([]byte("# title\n\ncontent"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	element, err = NewEntryElement([]byte("# title\n\nedited"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := interactor.Edit(id, element); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	revs, err := interactor.GetRevisions(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := interactor.Revert(id, revs[0].ID, 0); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := interactor.Delete(id, 0); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// not recorded when the entry doesn't exist
+	if err := interactor.Delete(id, 0); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	logs, err := NewAuditInteractor(getAuditRepository(t)).Find(repository.AuditFilter{EntryID: id})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	var actions []domain.AuditAction
+	for _, l := range logs {
+		actions = append(actions, l.Action)
+		if l.UserID != actor.UserID || l.TokenID != actor.TokenID || l.RemoteAddr != actor.RemoteAddr || l.CreatedAt.IsZero() {
+			t.Errorf("want the log of the actor %#v, got %#v", actor, l)
+		}
+	}
+	expectActions := []domain.AuditAction{domain.AuditActionDelete, domain.AuditActionRevert, domain.AuditActionEdit, domain.AuditActionPost}
+	if !reflect.DeepEqual(actions, expectActions) {
+		t.Fatalf("want actions %v, got %v", expectActions, actions)
+	}
+
+	// the hashes are chained from the post to the delete
+	deleted, reverted, edited, posted := logs[0], logs[1], logs[2], logs[3]
+	if len(posted.BeforeHash) != 0 || len(posted.AfterHash) == 0 {
+		t.Errorf("want only after hash of the post, got %#v", posted)
+	}
+	if edited.BeforeHash != posted.AfterHash || edited.AfterHash == edited.BeforeHash {
+		t.Errorf("want changed hash by the edit, got %#v", edited)
+	}
+	if reverted.BeforeHash != edited.AfterHash || reverted.AfterHash != posted.AfterHash {
+		t.Errorf("want reverted hash to the post, got %#v", reverted)
+	}
+	if deleted.BeforeHash != reverted.AfterHash || len(deleted.AfterHash) != 0 {
+		t.Errorf("want only before hash of the delete, got %#v", deleted)
+	}
+}
+
+func TestFindAudit(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	cases := []struct {
+		input     repository.AuditFilter
+		expectErr error
+	}{
+		{repository.AuditFilter{}, nil},
+		{repository.AuditFilter{Action: domain.AuditActionEdit, Since: &past, Until: &now, Limit: config.MaxAuditLimit}, nil},
+		{repository.AuditFilter{Action: "unknown"}, config.ErrInvalidAuditFilter},
+		{repository.AuditFilter{Limit: -1}, config.ErrInvalidAuditFilter},
+		{repository.AuditFilter{Limit: config.MaxAuditLimit + 1}, config.ErrInvalidAuditFilter},
+		{repository.AuditFilter{Since: &now, Until: &past}, config.ErrInvalidAuditFilter},
+	}
+	for i, c := range cases {
+		_, err := NewAuditInteractor(getAuditRepository(t)).Find(c.input)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+	}
+}
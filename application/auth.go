@@ -1,31 +1,63 @@
 package application
 
 import (
+	"log"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/domain/repository"
 )
 
 // AuthInteractor provides authentication
 type AuthInteractor struct {
 	tokenRepo repository.TokenRepository
+	userRepo  repository.UserRepository
 }
 
 // NewAuthInteractor returns initialized Auth object
-func NewAuthInteractor(t repository.TokenRepository) *AuthInteractor {
+func NewAuthInteractor(t repository.TokenRepository, u repository.UserRepository) *AuthInteractor {
 	return &AuthInteractor{
 		tokenRepo: t,
+		userRepo:  u,
 	}
 }
 
 // AuthenticateByToken provides validate of a token.
 // Returns non-nil error when failed to authenticate.
 func (i *AuthInteractor) AuthenticateByToken(token string) error {
-	// TODO: Now process of the authenticate, only compare to exist a token.
-	//       Want to add management of the user and authenticate each by user.
-	_, err := i.tokenRepo.FindByValue(token)
+	_, err := i.Authenticate(token)
+	return err
+}
+
+// Authenticate returns the user owned the token, and records the time the token is used at
+// Tokens without the user are issued before the users, and authenticated as the admin
+func (i *AuthInteractor) Authenticate(token string) (*domain.User, error) {
+	u, _, err := i.AuthenticateToken(token)
+	return u, err
+}
+
+// AuthenticateToken behaves like Authenticate, and returns the matched token as well
+func (i *AuthInteractor) AuthenticateToken(token string) (*domain.User, *domain.Token, error) {
+	t, err := findToken(i.tokenRepo, token)
 	if err != nil {
-		return errors.Wrapf(config.ErrInsufficientPrivileges, "error: %#v", err)
+		return nil, nil, errors.Wrapf(config.ErrInsufficientPrivileges, "error: %#v", err)
+	}
+	now := time.Now()
+	if t.IsExpired(now) {
+		return nil, nil, errors.Wrapf(config.ErrInsufficientPrivileges, "expired token. id: %d", t.ID)
+	}
+	u := &domain.User{Role: domain.RoleAdmin}
+	if t.UserID != 0 {
+		u, err = i.userRepo.Get(t.UserID)
+		if err != nil {
+			return nil, nil, errors.Wrapf(config.ErrInsufficientPrivileges, "error: %#v", err)
+		}
+	}
+	// the usage is informational, must not fail the authentication
+	if err := i.tokenRepo.Touch(t.ID, now); err != nil {
+		log.Printf("failed to record the usage of the token: %v", err)
 	}
-	return nil
+	return u, t, nil
 }
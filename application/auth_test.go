@@ -0,0 +1,46 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestAuthenticate(t *testing.T) {
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	cases := []struct {
+		input     string
+		expect    *domain.User
+		expectErr error
+	}{
+		{"foo", &domain.User{Role: domain.RoleAdmin}, nil},
+		{"baz", &domain.User{ID: 2, Name: "bob", Role: domain.RoleViewer}, nil},
+		{"deleted", nil, config.ErrInsufficientPrivileges},
+		{"expired", nil, config.ErrInsufficientPrivileges},
+		{"unknown", nil, config.ErrInsufficientPrivileges},
+	}
+	for i, c := range cases {
+		interactor := NewAuthInteractor(tokenRepository, userRepository)
+		user, err := interactor.Authenticate(c.input)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if !reflect.DeepEqual(user, c.expect) {
+			t.Errorf("#%d: want %#v, got %#v", i, c.expect, user)
+		}
+	}
+
+	// records the usage of the authenticated token
+	token, err := tokenRepository.FindByPlainValue("baz")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if token.LastUsedAt == nil {
+		t.Errorf("want last_used_at, got nil")
+	}
+}
@@ -0,0 +1,122 @@
+package application
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is number of unchanged lines around changes in the unified diff
+const diffContextLines = 3
+
+// diffOp represent an operation of the line diff
+// kind is ' ' (unchanged), '-' (deleted) or '+' (inserted)
+type diffOp struct {
+	kind byte
+	line string
+	// positions of the line before applying the operation
+	aIdx, bIdx int
+}
+
+// diffLines returns the shortest line edit script from a to b based on LCS
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], i, j})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', b[j], i, j})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', a[i], i, j})
+			i++
+		}
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// unifiedDiff returns the unified diff format text from a to b
+// Returns empty string when there are no differences
+func unifiedDiff(fromName, toName, a, b string) string {
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	changes := []int{}
+	for idx, op := range ops {
+		if op.kind != ' ' {
+			changes = append(changes, idx)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", fromName, toName)
+	for c := 0; c < len(changes); {
+		start := changes[c] - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		// merge changes which are close enough to share the context lines
+		last := changes[c]
+		for c++; c < len(changes) && changes[c]-last <= 2*diffContextLines; c++ {
+			last = changes[c]
+		}
+		end := last + diffContextLines + 1
+		if end > len(ops) {
+			end = len(ops)
+		}
+		writeHunk(&buf, ops[start:end])
+	}
+	return buf.String()
+}
+
+func writeHunk(buf *strings.Builder, ops []diffOp) {
+	aCount, bCount := 0, 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			aCount++
+		}
+		if op.kind != '-' {
+			bCount++
+		}
+	}
+	hunkStart := func(idx, count int) int {
+		if count == 0 {
+			return idx
+		}
+		return idx + 1
+	}
+	fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n",
+		hunkStart(ops[0].aIdx, aCount), aCount, hunkStart(ops[0].bIdx, bCount), bCount)
+	for _, op := range ops {
+		fmt.Fprintf(buf, "%c%s\n", op.kind, op.line)
+	}
+}
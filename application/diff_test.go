@@ -0,0 +1,37 @@
+package application
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		a, b   string
+		expect string
+	}{
+		{
+			"title\n\ncontent",
+			"title\n\ncontent",
+			"",
+		},
+		{
+			"title\n\ncontent",
+			"title\n\nedited\nadded",
+			"--- a\n+++ b\n@@ -1,3 +1,4 @@\n title\n \n-content\n+edited\n+added\n",
+		},
+		{
+			"",
+			"title",
+			"--- a\n+++ b\n@@ -0,0 +1,1 @@\n+title\n",
+		},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11",
+			"--- a\n+++ b\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+	for i, c := range cases {
+		act := unifiedDiff("a", "b", c.a, c.b)
+		if act != c.expect {
+			t.Errorf("#%d: want %q, got %q", i, c.expect, act)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"regexp"
+)
+
+// emojis is the shortcodes of the commonly used emoji
+var emojis = map[string]string{
+	"+1":                 "\U0001F44D",
+	"-1":                 "\U0001F44E",
+	"100":                "\U0001F4AF",
+	"bug":                "\U0001F41B",
+	"bulb":               "\U0001F4A1",
+	"books":              "\U0001F4DA",
+	"boom":               "\U0001F4A5",
+	"clap":               "\U0001F44F",
+	"coffee":             "☕",
+	"construction":       "\U0001F6A7",
+	"cry":                "\U0001F622",
+	"eyes":               "\U0001F440",
+	"fire":               "\U0001F525",
+	"gear":               "⚙️",
+	"heart":              "❤️",
+	"heavy_check_mark":   "✔️",
+	"hourglass":          "⌛",
+	"information_source": "ℹ️",
+	"joy":                "\U0001F602",
+	"laughing":           "\U0001F606",
+	"link":               "\U0001F517",
+	"lock":               "\U0001F512",
+	"memo":               "\U0001F4DD",
+	"ok_hand":            "\U0001F44C",
+	"pencil":             "\U0001F4DD",
+	"pray":               "\U0001F64F",
+	"question":           "❓",
+	"rocket":             "\U0001F680",
+	"see_no_evil":        "\U0001F648",
+	"smile":              "\U0001F604",
+	"smiley":             "\U0001F603",
+	"sparkles":           "✨",
+	"star":               "⭐",
+	"sweat_smile":        "\U0001F605",
+	"tada":               "\U0001F389",
+	"thinking":           "\U0001F914",
+	"thumbsdown":         "\U0001F44E",
+	"thumbsup":           "\U0001F44D",
+	"warning":            "⚠️",
+	"wave":               "\U0001F44B",
+	"white_check_mark":   "✅",
+	"wink":               "\U0001F609",
+	"wrench":             "\U0001F527",
+	"x":                  "❌",
+	"zap":                "⚡",
+}
+
+var emojiRegexp = regexp.MustCompile(`:([a-z0-9_+\-]+):`)
+
+// replaceEmoji replaces the known shortcodes like ":tada:" with the emoji
+func replaceEmoji(text []byte) []byte {
+	return emojiRegexp.ReplaceAllFunc(text, func(code []byte) []byte {
+		if e, ok := emojis[string(code[1:len(code)-1])]; ok {
+			return []byte(e)
+		}
+		return code
+	})
+}
@@ -3,47 +3,59 @@ package application
 import (
 	"database/sql"
 	"fmt"
+	"html"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
-	"github.com/russross/blackfriday"
 	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/domain/repository"
 )
 
 // EntryInteractor provides operation for entries
+// Post, Edit, Delete and Revert are recorded as the audit logs of the actor,
+// and Rerender and Sanitize are recorded as the edits of the server itself
 type EntryInteractor struct {
-	entryRepo repository.EntryRepository
+	entryRepo  repository.EntryRepository
+	searchRepo repository.SearchRepository
+	auditRepo  repository.AuditRepository
+	actor      domain.Actor
 }
 
 // NewEntryInteractor returns initialized Entry object
-func NewEntryInteractor(e repository.EntryRepository) *EntryInteractor {
+func NewEntryInteractor(e repository.EntryRepository, s repository.SearchRepository, a repository.AuditRepository) *EntryInteractor {
 	return &EntryInteractor{
-		entryRepo: e,
+		entryRepo:  e,
+		searchRepo: s,
+		auditRepo:  a,
 	}
 }
 
-func extractTitleAndContent(data []byte) (title, content string) {
-	if len(data) == 0 {
-		return "", ""
-	}
+// WithActor returns the copy of the interactor operating as the actor
+func (i *EntryInteractor) WithActor(actor domain.Actor) *EntryInteractor {
+	c := *i
+	c.actor = actor
+	return &c
+}
 
-	// Title is regarded as a first line
-	titleIdx := 0
-	html := blackfriday.Run(data)
-	for i, d := range html {
-		if d == '\n' {
-			titleIdx = i
-			break
-		}
+// audit records the action to the entry, before and after are nil when the entry didn't exist
+// The operation has already succeeded, therefore the failure is only logged
+func (i *EntryInteractor) audit(action domain.AuditAction, id int, before, after *domain.Entry) {
+	l := domain.NewAuditLog(action, i.actor, id, before, after)
+	l.CreatedAt = time.Now()
+	if _, err := i.auditRepo.Save(l); err != nil {
+		log.Printf("failed to record the audit log. action: %s, entry: %d, error: %v", action, id, err)
 	}
-	title = string(html[:titleIdx])
-	title = trimHTMLTag(title)
+}
 
-	content = string(html[titleIdx:])
-	content = strings.TrimSpace(content)
-	return title, content
+// extractTitleAndContent regards the first block as the title
+func extractTitleAndContent(data []byte, r Renderer) (title, content string) {
+	if len(data) == 0 {
+		return "", ""
+	}
+	return r.RenderWithTitle(data)
 }
 
 func trimHTMLTag(s string) string {
@@ -52,19 +64,43 @@ func trimHTMLTag(s string) string {
 	return s[openIdx+1 : closeIdx]
 }
 
-// Get returns entry when matched id
-func (i *EntryInteractor) Get(id int) (*domain.Entry, error) {
+// Get returns entry when matched id and the filter
+// Scheduled entries are not found until published
+func (i *EntryInteractor) Get(id int, f repository.EntryFilter) (*domain.Entry, error) {
+	entry, err := i.entryRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if !f.Match(entry) {
+		return nil, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+// Lookup returns entry when matched id regardless of the status
+// Only used by the authorized operations, e.g. preview the drafts
+func (i *EntryInteractor) Lookup(id int) (*domain.Entry, error) {
 	return i.entryRepo.Get(id)
 }
 
-// GetIDs returns entry ids
-func (i *EntryInteractor) GetIDs() ([]int, error) {
-	return i.entryRepo.GetIDs()
+// GetIDs returns entry ids matched by the filter
+func (i *EntryInteractor) GetIDs(f repository.EntryFilter) ([]int, error) {
+	return i.entryRepo.GetIDs(f)
+}
+
+// GetTitles returns entries with contain id and title matched by the filter
+func (i *EntryInteractor) GetTitles(f repository.EntryFilter, start, n int) ([]*domain.Entry, error) {
+	return i.entryRepo.GetTitles(f, start, n)
+}
+
+// GetTags returns tags with the number of the entries matched by the filter
+func (i *EntryInteractor) GetTags(f repository.EntryFilter) ([]*domain.Tag, error) {
+	return i.entryRepo.GetTags(f)
 }
 
-// GetTitles returns entries with contain id and title
-func (i *EntryInteractor) GetTitles(start, n int) ([]*domain.Entry, error) {
-	return i.entryRepo.GetTitles(start, n)
+// GetArchives returns the number of the entries matched by the filter in each month
+func (i *EntryInteractor) GetArchives(f repository.EntryFilter) ([]*domain.Archive, error) {
+	return i.entryRepo.GetArchives(f)
 }
 
 // Post saves the posted data in the background datastore
@@ -77,8 +113,29 @@ func (i *EntryInteractor) Post(e *EntryElement) (int, error) {
 		return 0, errors.Wrapf(config.ErrDuplicatedTitle, fmt.Sprintf("title: %s", e.Title))
 	}
 
+	if err := i.assignSlug(entry); err != nil {
+		return 0, err
+	}
+
 	entry.UpdateStatusByTitle()
-	return i.entryRepo.Save(entry)
+	if err := validateSchedule(entry); err != nil {
+		return 0, err
+	}
+	id, err := i.entryRepo.Save(entry)
+	if err != nil {
+		return 0, err
+	}
+	entry.ID = id
+	i.audit(domain.AuditActionPost, id, nil, entry)
+	return id, i.searchRepo.Index(entry)
+}
+
+// validateSchedule returns an error when the scheduled entry has no publish date
+func validateSchedule(entry *domain.Entry) error {
+	if entry.IsScheduled() && entry.PublishAt == nil {
+		return config.ErrEmptyPublishAt
+	}
+	return nil
 }
 
 func (i *EntryInteractor) duplicateTitle(entry *domain.Entry) bool {
@@ -87,45 +144,383 @@ func (i *EntryInteractor) duplicateTitle(entry *domain.Entry) bool {
 	return err != nil && errors.Cause(err) == sql.ErrNoRows
 }
 
+// assignSlug sets the unique slug to the entry
+// Generates from the title with a numbered suffix unless specified, the specified slug must be unique
+func (i *EntryInteractor) assignSlug(entry *domain.Entry) error {
+	if len(entry.Slug) != 0 {
+		slug := domain.Slugify(entry.Slug)
+		ok, err := i.availableSlug(slug, entry.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Wrapf(config.ErrDuplicatedSlug, "slug: %s", slug)
+		}
+		entry.Slug = slug
+		return nil
+	}
+
+	// the title is HTML, entities must not leak into the slug
+	title, _ := entry.TrimPrivateTitle()
+	base := domain.Slugify(html.UnescapeString(title))
+	if len(base) == 0 {
+		return nil
+	}
+	slug := base
+	for n := 2; ; n++ {
+		ok, err := i.availableSlug(slug, entry.ID)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+	entry.Slug = slug
+	return nil
+}
+
+// availableSlug returns whether the slug is unused by other entries
+func (i *EntryInteractor) availableSlug(slug string, id int) (bool, error) {
+	e, err := i.entryRepo.GetBySlug(slug)
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return true, nil
+		}
+		return false, err
+	}
+	return e.ID == id, nil
+}
+
+// GetBySlug returns the entry matched by the current slug and the filter
+// Returns the current slug as well when the slug has been changed
+func (i *EntryInteractor) GetBySlug(slug string, f repository.EntryFilter) (*domain.Entry, string, error) {
+	entry, err := i.entryRepo.GetBySlug(slug)
+	if err == nil {
+		if !f.Match(entry) {
+			return nil, "", sql.ErrNoRows
+		}
+		return entry, slug, nil
+	}
+	if errors.Cause(err) != sql.ErrNoRows {
+		return nil, "", err
+	}
+
+	current, err := i.entryRepo.GetRedirectedSlug(slug)
+	if err != nil {
+		return nil, "", err
+	}
+	// avoid to leak the current slug of the hidden entry
+	entry, err = i.entryRepo.GetBySlug(current)
+	if err != nil {
+		return nil, "", err
+	}
+	if !f.Match(entry) {
+		return nil, "", sql.ErrNoRows
+	}
+	return nil, current, nil
+}
+
 // Edit changes entry the title and content
+// Fails with ErrEntryVersionConflict when the entry has been changed since the version of the element
 func (i *EntryInteractor) Edit(id int, e *EntryElement) error {
+	current, err := i.entryRepo.Get(id)
+	if err != nil {
+		return err
+	}
+	if e.Version != 0 && e.Version != current.Version {
+		return errors.Wrapf(config.ErrEntryVersionConflict, "id: %d, version: %d, current: %d", id, e.Version, current.Version)
+	}
 	entry := e.Entity()
 	entry.ID = id
+	// the entry may be changed by the others while editing
+	entry.Version = current.Version
+	// keep the slug and the publish date unless specified
+	if len(entry.Slug) == 0 {
+		entry.Slug = current.Slug
+	}
+	if entry.PublishAt == nil {
+		entry.PublishAt = current.PublishAt
+	}
+	if err := i.assignSlug(entry); err != nil {
+		return err
+	}
 	entry.UpdateStatusByTitle()
-	return i.entryRepo.Edit(entry)
+	if err := validateSchedule(entry); err != nil {
+		return err
+	}
+	if err := i.entryRepo.Edit(entry); err != nil {
+		return err
+	}
+	e.Version = entry.Version
+	i.audit(domain.AuditActionEdit, id, current, entry)
+	return i.searchRepo.Index(entry)
 }
 
 // Delete deletes entry
-func (i *EntryInteractor) Delete(id int) error {
-	_, err := i.entryRepo.Delete(id)
-	return err
+// Fails with ErrEntryVersionConflict when the entry has been changed since the version, zero deletes any version
+func (i *EntryInteractor) Delete(id, version int) error {
+	current, err := i.entryRepo.Get(id)
+	if err != nil && errors.Cause(err) != sql.ErrNoRows {
+		return err
+	}
+	deleted, err := i.entryRepo.Delete(id, version)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		i.audit(domain.AuditActionDelete, id, current, nil)
+	}
+	return i.searchRepo.Remove(id)
+}
+
+// GetRevisions returns revisions of the entry
+func (i *EntryInteractor) GetRevisions(id int) ([]*domain.Revision, error) {
+	if _, err := i.entryRepo.Get(id); err != nil {
+		return nil, err
+	}
+	return i.entryRepo.GetRevisions(id)
+}
+
+// GetRevision returns a revision of the entry
+func (i *EntryInteractor) GetRevision(id, revisionID int) (*domain.Revision, error) {
+	return i.entryRepo.GetRevision(id, revisionID)
+}
+
+// Diff returns the unified diff between two revisions of the entry
+func (i *EntryInteractor) Diff(id, from, to int) (string, error) {
+	a, err := i.entryRepo.GetRevision(id, from)
+	if err != nil {
+		return "", err
+	}
+	b, err := i.entryRepo.GetRevision(id, to)
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(
+		fmt.Sprintf("revision/%d", a.ID),
+		fmt.Sprintf("revision/%d", b.ID),
+		revisionText(a),
+		revisionText(b),
+	), nil
+}
+
+func revisionText(r *domain.Revision) string {
+	return fmt.Sprintf("%s\n\n%s", r.Title, r.Content)
+}
+
+// Revert changes the title and content of the entry to the revision, and returns the new version
+// Reverting is recorded as a new revision as well as Edit
+// Fails with ErrEntryVersionConflict when the entry has been changed since the version, zero reverts any version
+func (i *EntryInteractor) Revert(id, revisionID, version int) (int, error) {
+	entry, err := i.entryRepo.Get(id)
+	if err != nil {
+		return 0, err
+	}
+	if version != 0 && version != entry.Version {
+		return 0, errors.Wrapf(config.ErrEntryVersionConflict, "id: %d, version: %d, current: %d", id, version, entry.Version)
+	}
+	rev, err := i.entryRepo.GetRevision(id, revisionID)
+	if err != nil {
+		return 0, err
+	}
+	before := *entry
+	entry.Title = rev.Title
+	entry.Content = rev.Content
+	entry.Source = rev.Source
+	if err := i.entryRepo.Edit(entry); err != nil {
+		return 0, err
+	}
+	i.audit(domain.AuditActionRevert, id, &before, entry)
+	return entry.Version, i.searchRepo.Index(entry)
+}
+
+// Rerender renders the content of all entries again from the source markdown by the renderer
+// Entries saved without the source and unchanged entries are skipped
+// Returns the number of the updated entries
+func (i *EntryInteractor) Rerender(r Renderer) (int, error) {
+	return i.rewrite(func(entry *domain.Entry) error {
+		if len(entry.Source) == 0 {
+			return nil
+		}
+		e, err := NewEntryElementWithAssets([]byte(entry.Source), entry.Assets, r)
+		if err != nil {
+			return errors.Wrapf(err, "failed to render entry %d", entry.ID)
+		}
+		entry.Title = e.Title
+		entry.Content = e.Content
+		return nil
+	})
+}
+
+// Sanitize removes the HTML which is not allowed by the sanitizer from the title and content of all entries
+// Returns the number of the updated entries
+func (i *EntryInteractor) Sanitize(s *Sanitizer) (int, error) {
+	return i.rewrite(func(entry *domain.Entry) error {
+		entry.Title = sanitizeTitle(entry, s)
+		entry.Content = s.Sanitize(entry.Content)
+		return nil
+	})
+}
+
+// sanitizeTitle returns the escaped title of the front matter in the source
+// Otherwise the title is the HTML rendered from the heading, and is sanitized
+func sanitizeTitle(entry *domain.Entry, s *Sanitizer) string {
+	meta, _, err := splitFrontMatter([]byte(entry.Source))
+	if err == nil && meta != nil && len(meta.Title) != 0 {
+		return html.EscapeString(meta.Title)
+	}
+	return s.Sanitize(entry.Title)
+}
+
+// rewrite changes the title and content of all entries by the function
+// Saves only the changed entries, and returns the number of them
+// The changes are recorded as the edits of the server itself
+func (i *EntryInteractor) rewrite(fn func(entry *domain.Entry) error) (int, error) {
+	system := i.WithActor(domain.Actor{})
+	ids, err := i.entryRepo.GetIDs(repository.EntryFilter{IncludePrivate: true, IncludeScheduled: true})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, id := range ids {
+		entry, err := i.entryRepo.Get(id)
+		if err != nil {
+			return n, err
+		}
+		before := *entry
+		if err := fn(entry); err != nil {
+			return n, err
+		}
+		if entry.Title == before.Title && entry.Content == before.Content {
+			continue
+		}
+		if err := i.entryRepo.Edit(entry); err != nil {
+			return n, err
+		}
+		system.audit(domain.AuditActionEdit, entry.ID, &before, entry)
+		if err := i.searchRepo.Index(entry); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
 }
 
 // EntryElement represent element of the entry operation method
 type EntryElement struct {
-	Title   string
-	Content string
-	Status  domain.EntryStatus
+	Title     string
+	Content   string
+	Source    string
+	Status    domain.EntryStatus
+	Slug      string
+	Summary   string
+	Tags      []string
+	PublishAt *time.Time
+	// Assets maps the local links of the images to the hashes of the assets
+	Assets map[string]string
+	// AuthorID is the user posting the entry, ignored by the edit
+	AuthorID int
+	// Version is the version of the entry the edit is based on, zero edits any version
+	// Updated to the new version of the entry after the edit
+	Version int
+
+	// whether the status is specified by the front matter
+	hasStatus bool
 }
 
-// NewEntryElement returns initialized an EntryElement object
-func NewEntryElement(data []byte) (*EntryElement, error) {
-	title, content := extractTitleAndContent(data)
+// NewEntryElement returns initialized an EntryElement object rendered by the renderer
+// Metadata is read from the front matter when the data begins with it
+func NewEntryElement(data []byte, r Renderer) (*EntryElement, error) {
+	meta, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var title, content string
+	if meta != nil && len(meta.Title) != 0 {
+		// the title is the text, escaped the same as the title of the heading
+		title = html.EscapeString(meta.Title)
+		content = r.Render(body)
+	} else {
+		title, content = extractTitleAndContent(body, r)
+	}
 	if len(title) == 0 || len(content) == 0 {
 		return nil, config.ErrEmptyEntry
 	}
-	return &EntryElement{
+
+	e := &EntryElement{
 		Title:   title,
 		Content: content,
+		Source:  string(data),
 		Status:  domain.EntryStatusPublic,
-	}, nil
+	}
+	if meta != nil {
+		if err := e.applyFrontMatter(meta); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// NewEntryElementWithAssets returns initialized an EntryElement object rendered with the links to the assets
+// The local links of the images in the data are replaced with the paths of the assets, but the source keeps them
+func NewEntryElementWithAssets(data []byte, assets map[string]string, r Renderer) (*EntryElement, error) {
+	for link, hash := range assets {
+		if len(link) == 0 || len(link) > config.MaxAssetLinkBytes || !domain.IsAssetHash(hash) {
+			return nil, errors.Wrapf(config.ErrInvalidAssetLink, "link: %s, hash: %s", link, hash)
+		}
+	}
+	e, err := NewEntryElement(domain.ReplaceAssetLinks(data, assets), r)
+	if err != nil {
+		return nil, err
+	}
+	e.Source = string(data)
+	e.Assets = assets
+	return e, nil
+}
+
+func (e *EntryElement) applyFrontMatter(meta *FrontMatter) error {
+	if len(meta.Status) != 0 {
+		status, err := domain.ParseEntryStatus(meta.Status)
+		if err != nil {
+			return errors.Wrap(config.ErrInvalidFrontMatter, err.Error())
+		}
+		e.Status = status
+		e.hasStatus = true
+	}
+	publishAt, err := meta.PublishAt()
+	if err != nil {
+		return err
+	}
+
+	e.Slug = meta.Slug
+	e.Summary = meta.Summary
+	e.Tags = meta.Tags
+	e.PublishAt = publishAt
+	return nil
+}
+
+// SetDefaultStatus changes the status unless the front matter specifies it
+func (e *EntryElement) SetDefaultStatus(status domain.EntryStatus) {
+	if !e.hasStatus {
+		e.Status = status
+	}
 }
 
 // Entity returns the entity from creating by the EntryElement
 func (e *EntryElement) Entity() *domain.Entry {
 	return &domain.Entry{
-		Title:   e.Title,
-		Content: e.Content,
-		Status:  e.Status,
+		Title:     e.Title,
+		Content:   e.Content,
+		Source:    e.Source,
+		Status:    e.Status,
+		Slug:      e.Slug,
+		Summary:   e.Summary,
+		PublishAt: e.PublishAt,
+		Tags:      domain.NormalizeTags(e.Tags),
+		Assets:    e.Assets,
+		AuthorID:  e.AuthorID,
 	}
 }
@@ -6,11 +6,13 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
-	"github.com/takashabe/lumber/helper"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/memory"
 )
 
 func TestNewEntryElement(t *testing.T) {
@@ -48,7 +50,7 @@ func TestNewEntryElement(t *testing.T) {
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		entry, err := NewEntryElement(data)
+		entry, err := NewEntryElement(data, getRenderer(t))
 		if err != c.expectErr {
 			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
 		}
@@ -69,7 +71,7 @@ func TestNewEntryElement(t *testing.T) {
 }
 
 func TestGetEntry(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		input     int
 		expectID  int
@@ -79,10 +81,10 @@ func TestGetEntry(t *testing.T) {
 		{0, 0, sql.ErrNoRows},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/entries.yml")
 
-		interactor := NewEntryInteractor(getEntryRepository(t))
-		act, err := interactor.Get(c.input)
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		act, err := interactor.Get(c.input, authorized)
 		if err != c.expectErr {
 			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
 		}
@@ -97,7 +99,7 @@ func TestGetEntry(t *testing.T) {
 }
 
 func TestGetIDsEntry(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		fixture   string
 		expectIDs []int
@@ -108,10 +110,10 @@ func TestGetIDsEntry(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, c.fixture)
+		loadFixture(t, c.fixture)
 
-		interactor := NewEntryInteractor(getEntryRepository(t))
-		act, err := interactor.GetIDs()
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		act, err := interactor.GetIDs(authorized)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
@@ -122,7 +124,7 @@ func TestGetIDsEntry(t *testing.T) {
 }
 
 func TestGetTitlesEntry(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		fixture string
 		start   int
@@ -140,21 +142,21 @@ func TestGetTitlesEntry(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, c.fixture)
+		loadFixture(t, c.fixture)
 
-		interactor := NewEntryInteractor(getEntryRepository(t))
-		act, err := interactor.GetTitles(c.start, c.length)
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		act, err := interactor.GetTitles(authorized, c.start, c.length)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
 		if !reflect.DeepEqual(act, c.expect) {
-			t.Fatalf("#%d: want %q, got %q", i, c.expect, act)
+			t.Fatalf("#%d: want %v, got %v", i, c.expect, act)
 		}
 	}
 }
 
 func TestPostEntry(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		inputFilePath string
 		expectErr     error
@@ -168,11 +170,11 @@ func TestPostEntry(t *testing.T) {
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		element, err := NewEntryElement(data)
+		element, err := NewEntryElement(data, getRenderer(t))
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		interactor := NewEntryInteractor(getEntryRepository(t))
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
 		_, err = interactor.Post(element)
 		if errors.Cause(err) != c.expectErr {
 			t.Fatalf("#%d: want %#v, got %#v", i, c.expectErr, err)
@@ -181,7 +183,7 @@ func TestPostEntry(t *testing.T) {
 }
 
 func TestPostWithPrivateTitle(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		inputFilePath string
 		expectEntry   *domain.Entry
@@ -191,7 +193,12 @@ func TestPostWithPrivateTitle(t *testing.T) {
 			&domain.Entry{
 				Title:   "title",
 				Content: "<p>content</p>",
+				Source:  "title\n\ncontent\n",
 				Status:  0,
+				Slug:    "title",
+				Tags:    []string{},
+				Assets:  map[string]string{},
+				Version: 1,
 			},
 		},
 		{
@@ -199,24 +206,33 @@ func TestPostWithPrivateTitle(t *testing.T) {
 			&domain.Entry{
 				Title:   "[wip] wip_title",
 				Content: "<p>content</p>",
+				Source:  "[wip] wip_title\n\ncontent\n",
 				Status:  1,
+				Slug:    "wip-title",
+				Tags:    []string{},
+				Assets:  map[string]string{},
+				Version: 1,
 			},
 		},
 	}
 	for i, c := range cases {
 		data, _ := ioutil.ReadFile(c.inputFilePath)
-		element, _ := NewEntryElement(data)
-		interactor := NewEntryInteractor(getEntryRepository(t))
+		element, _ := NewEntryElement(data, getRenderer(t))
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
 		id, err := interactor.Post(element)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
 
 		c.expectEntry.ID = id
-		actual, err := interactor.Get(id)
+		actual, err := interactor.Get(id, authorized)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
+		if actual.CreatedAt.IsZero() || actual.UpdatedAt.IsZero() {
+			t.Errorf("#%d: want timestamps, got %v and %v", i, actual.CreatedAt, actual.UpdatedAt)
+		}
+		actual.CreatedAt, actual.UpdatedAt = time.Time{}, time.Time{}
 		if !reflect.DeepEqual(actual, c.expectEntry) {
 			t.Errorf("#%d: want %#v, got %#v", i, c.expectEntry, actual)
 		}
@@ -224,7 +240,7 @@ func TestPostWithPrivateTitle(t *testing.T) {
 }
 
 func TestEditEntry(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		inputID   int
 		inputData []byte
@@ -234,19 +250,19 @@ func TestEditEntry(t *testing.T) {
 		{0, []byte("# title\n\n## content"), sql.ErrNoRows},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/entries.yml")
 
-		element, err := NewEntryElement(c.inputData)
+		element, err := NewEntryElement(c.inputData, getRenderer(t))
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		interactor := NewEntryInteractor(getEntryRepository(t))
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
 		err = interactor.Edit(c.inputID, element)
 		if err != nil {
 			continue
 		}
 
-		entry, err := interactor.Get(c.inputID)
+		entry, err := interactor.Get(c.inputID, authorized)
 		if errors.Cause(err) != c.err {
 			t.Fatalf("#%d: want error %#v, got %#v", i, c.err, err)
 		}
@@ -261,20 +277,53 @@ func TestEditEntry(t *testing.T) {
 	}
 }
 
+func TestEditEntryVersion(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/entries.yml")
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+
+	cases := []struct {
+		version       int
+		expectVersion int
+		expectErr     error
+	}{
+		{1, 2, nil},
+		{1, 1, config.ErrEntryVersionConflict},
+		{0, 3, nil},
+	}
+	for i, c := range cases {
+		element, err := NewEntryElement([]byte("# title\n\ncontent"), getRenderer(t))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		element.Version = c.version
+		err = interactor.Edit(1, element)
+		if errors.Cause(err) != c.expectErr {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if element.Version != c.expectVersion {
+			t.Errorf("#%d: want version %d, got %d", i, c.expectVersion, element.Version)
+		}
+	}
+}
+
 func TestDelete(t *testing.T) {
-	helper.LoadFixture(t, "testdata/clean.sql")
+	loadFixture(t, "testdata/clean.sql")
 	cases := []struct {
 		input     int
+		version   int
 		expectErr error
 	}{
-		{1, nil},
-		{0, nil},
+		{1, 0, nil},
+		{0, 0, nil},
+		{1, 1, nil},
+		{1, 2, config.ErrEntryVersionConflict},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/entries.yml")
 
-		interactor := NewEntryInteractor(getEntryRepository(t))
-		err := interactor.Delete(c.input)
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		err := interactor.Delete(c.input, c.version)
 		if errors.Cause(err) != c.expectErr {
 			t.Fatalf("#%d: want %#v, got %#v", i, c.expectErr, err)
 		}
@@ -282,9 +331,503 @@ func TestDelete(t *testing.T) {
 			continue
 		}
 
-		_, err = interactor.Get(c.input)
+		_, err = interactor.Get(c.input, authorized)
 		if err != sql.ErrNoRows {
 			t.Fatalf("#%d: want error sql.ErrNoRows, got %#v", i, err)
 		}
 	}
 }
+
+func TestRevertEntry(t *testing.T) {
+	loadFixture(t, "testdata/entries.yml")
+
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+	element, err := NewEntryElement([]byte("# edited\n\nedited content"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := interactor.Edit(1, element); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	revs, err := interactor.GetRevisions(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("want revisions %d, got %d", 2, len(revs))
+	}
+	diff, err := interactor.Diff(1, revs[0].ID, revs[1].ID)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if !strings.Contains(diff, "-bar\n") || !strings.Contains(diff, "+<p>edited content</p>\n") {
+		t.Errorf("want diff of the content, got %q", diff)
+	}
+
+	// the version has been changed by the edit
+	if _, err := interactor.Revert(1, revs[0].ID, 1); errors.Cause(err) != config.ErrEntryVersionConflict {
+		t.Fatalf("want error %#v, got %#v", config.ErrEntryVersionConflict, err)
+	}
+	version, err := interactor.Revert(1, revs[0].ID, element.Version)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if version != element.Version+1 {
+		t.Errorf("want version %d, got %d", element.Version+1, version)
+	}
+	entry, err := interactor.Get(1, authorized)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if entry.Title != "foo" || entry.Content != "bar" {
+		t.Errorf("want title foo and content bar, got title %s and content %s", entry.Title, entry.Content)
+	}
+	if _, err := interactor.Revert(1, 0, 0); errors.Cause(err) != domain.ErrNotFoundRevision {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundRevision, err)
+	}
+}
+
+func TestRerenderEntry(t *testing.T) {
+	loadFixture(t, "testdata/entries.yml")
+	getAuditRepository(t).(*memory.AuditRepositoryImpl).Reset()
+
+	source := "# baz\n\n*qux*\n"
+	repo := getEntryRepository(t)
+	// the changes are recorded as the server regardless of the actor
+	interactor := NewEntryInteractor(repo, getSearchRepository(t), getAuditRepository(t)).WithActor(domain.Actor{UserID: 2})
+	element, err := NewEntryElement([]byte(source), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	// pretend to be rendered by the previous renderer
+	entry, err := repo.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if entry.Source != source {
+		t.Fatalf("want source %q, got %q", source, entry.Source)
+	}
+	entry.Content = "<p>stale</p>"
+	if err := repo.Edit(entry); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		expectUpdated int
+	}{
+		{1},
+		// unchanged entries are skipped
+		{0},
+	}
+	for i, c := range cases {
+		n, err := interactor.Rerender(getRenderer(t))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expectUpdated {
+			t.Errorf("#%d: want updated %d, got %d", i, c.expectUpdated, n)
+		}
+		entry, err := repo.Get(id)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if entry.Title != "baz" || entry.Content != "<p><em>qux</em></p>" {
+			t.Errorf("#%d: want title baz and content <p><em>qux</em></p>, got title %s and content %s", i, entry.Title, entry.Content)
+		}
+		// the entry without the source is kept as it is
+		entry, err = repo.Get(1)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if entry.Content != "bar" {
+			t.Errorf("#%d: want content bar, got %s", i, entry.Content)
+		}
+	}
+
+	logs, err := NewAuditInteractor(getAuditRepository(t)).Find(repository.AuditFilter{EntryID: id, Action: domain.AuditActionEdit})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(logs) != 1 || logs[0].UserID != 0 || logs[0].BeforeHash == logs[0].AfterHash {
+		t.Errorf("want an edit log by the server, got %#v", logs)
+	}
+}
+
+func TestNewEntryElementWithAssets(t *testing.T) {
+	hash := strings.Repeat("a", 64)
+	source := "# title\n\n![image](./images/a.png) ![missing](images/b.png)\n"
+	expectContent := `<p><img src="/assets/` + hash + `" alt="image" /> <img src="images/b.png" alt="missing" /></p>`
+
+	cases := []struct {
+		assets    map[string]string
+		expectErr error
+	}{
+		{map[string]string{"images/a.png": hash}, nil},
+		{map[string]string{"images/a.png": "../../etc/passwd"}, config.ErrInvalidAssetLink},
+		{map[string]string{"": hash}, config.ErrInvalidAssetLink},
+	}
+	for i, c := range cases {
+		element, err := NewEntryElementWithAssets([]byte(source), c.assets, getRenderer(t))
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if element.Content != expectContent || element.Source != source {
+			t.Errorf("#%d: want content %q and source %q, got %q and %q", i, expectContent, source, element.Content, element.Source)
+		}
+	}
+}
+
+func TestRerenderEntryWithAssets(t *testing.T) {
+	loadFixture(t, "testdata/entries.yml")
+
+	hash := strings.Repeat("a", 64)
+	repo := getEntryRepository(t)
+	interactor := NewEntryInteractor(repo, getSearchRepository(t), getAuditRepository(t))
+	element, err := NewEntryElementWithAssets([]byte("# image\n\n![a](images/a.png)\n"), map[string]string{"images/a.png": hash}, getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	if _, err := interactor.Rerender(getRenderer(t)); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := repo.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := `<p><img src="/assets/` + hash + `" alt="a" /></p>`; entry.Content != expect {
+		t.Errorf("want content %q, got %q", expect, entry.Content)
+	}
+}
+
+func TestSanitizeEntry(t *testing.T) {
+	loadFixture(t, "testdata/entries.yml")
+
+	repo := getEntryRepository(t)
+	interactor := NewEntryInteractor(repo, getSearchRepository(t), getAuditRepository(t))
+	// posted before introduced the sanitizer
+	element, err := NewEntryElement([]byte("# title\n\ncontent <img src=\"x\" onerror=\"alert(1)\">"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// posted before escaped the title of the front matter
+	element, err = NewEntryElement([]byte("---\ntitle: <img src=x onerror=alert(1)>\n---\n\ncontent"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	element.Title = "<img src=x onerror=alert(1)>"
+	titleID, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	sanitizer := NewSanitizer(DefaultSanitizePolicy())
+	cases := []struct {
+		expectUpdated int
+	}{
+		{2},
+		{0},
+	}
+	for i, c := range cases {
+		n, err := interactor.Sanitize(sanitizer)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expectUpdated {
+			t.Errorf("#%d: want updated %d, got %d", i, c.expectUpdated, n)
+		}
+		entry, err := repo.Get(id)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if expect := `<p>content <img src="x"></p>`; entry.Content != expect {
+			t.Errorf("#%d: want content %s, got %s", i, expect, entry.Content)
+		}
+		entry, err = repo.Get(titleID)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if expect := "&lt;img src=x onerror=alert(1)&gt;"; entry.Title != expect {
+			t.Errorf("#%d: want title %s, got %s", i, expect, entry.Title)
+		}
+	}
+}
+
+func TestNewEntryElementWithFrontMatter(t *testing.T) {
+	publishAt := time.Date(2018, 3, 1, 1, 0, 0, 0, time.UTC)
+	cases := []struct {
+		inputFilePath string
+		expect        *EntryElement
+		expectErr     error
+	}{
+		{
+			"testdata/frontmatter_yaml.md",
+			&EntryElement{
+				Title:     "yaml title",
+				Content:   "<h1>heading</h1>\n\n<p>content</p>",
+				Status:    domain.EntryStatusPrivate,
+				Slug:      "yaml-title",
+				Summary:   "summary of the entry",
+				Tags:      []string{"go", "blog"},
+				PublishAt: &publishAt,
+			},
+			nil,
+		},
+		{
+			"testdata/frontmatter_toml.md",
+			&EntryElement{
+				Title:     "toml title",
+				Content:   "<p>content</p>",
+				Status:    domain.EntryStatusPublic,
+				Slug:      "toml-title",
+				Tags:      []string{"go"},
+				PublishAt: &publishAt,
+			},
+			nil,
+		},
+		{
+			"testdata/frontmatter_without_title.md",
+			&EntryElement{
+				Title:   "title",
+				Content: "<p>content</p>",
+				Status:  domain.EntryStatusPublic,
+				Summary: "summary of the entry",
+			},
+			nil,
+		},
+		{
+			"testdata/frontmatter_html_title.md",
+			&EntryElement{
+				Title:   "Tom &amp; Jerry &lt;img src=x onerror=alert(1)&gt;",
+				Content: "<p>content</p>",
+				Status:  domain.EntryStatusPublic,
+			},
+			nil,
+		},
+		{
+			"testdata/frontmatter_unclosed.md",
+			nil,
+			config.ErrInvalidFrontMatter,
+		},
+	}
+	for i, c := range cases {
+		data, err := ioutil.ReadFile(c.inputFilePath)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		element, err := NewEntryElement(data, getRenderer(t))
+		if errors.Cause(err) != c.expectErr {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		if element.PublishAt != nil && c.expect.PublishAt != nil && element.PublishAt.Equal(*c.expect.PublishAt) {
+			element.PublishAt = c.expect.PublishAt
+		}
+		element.hasStatus = false
+		if element.Source != string(data) {
+			t.Errorf("#%d: want source %q, got %q", i, data, element.Source)
+		}
+		element.Source = ""
+		if !reflect.DeepEqual(element, c.expect) {
+			t.Errorf("#%d: want %#v, got %#v", i, c.expect, element)
+		}
+	}
+}
+
+func TestSetDefaultStatus(t *testing.T) {
+	cases := []struct {
+		input  []byte
+		expect domain.EntryStatus
+	}{
+		{[]byte("# title\n\ncontent"), domain.EntryStatusPrivate},
+		{[]byte("---\nstatus: public\n---\n# title\n\ncontent"), domain.EntryStatusPublic},
+	}
+	for i, c := range cases {
+		element, err := NewEntryElement(c.input, getRenderer(t))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		element.SetDefaultStatus(domain.EntryStatusPrivate)
+		if element.Status != c.expect {
+			t.Errorf("#%d: want status %s, got %s", i, c.expect, element.Status)
+		}
+	}
+}
+
+func TestGetTitlesByTagsEntry(t *testing.T) {
+	cases := []struct {
+		tags   []string
+		expect []*domain.Entry
+	}{
+		{
+			[]string{"go"},
+			[]*domain.Entry{
+				&domain.Entry{ID: 1, Title: "foo"},
+				&domain.Entry{ID: 2, Title: "foo"},
+			},
+		},
+		{
+			[]string{"Go", "blog"},
+			[]*domain.Entry{
+				&domain.Entry{ID: 1, Title: "foo"},
+			},
+		},
+		{
+			[]string{"unknown"},
+			[]*domain.Entry{},
+		},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/tags.yml")
+		loadFixture(t, "testdata/entry_tags.yml")
+
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		act, err := interactor.GetTitles(repository.EntryFilter{Tags: c.tags, IncludePrivate: true}, 0, 0)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(act, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, act)
+		}
+	}
+}
+
+func TestPostEntryWithTags(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+
+	element, err := NewEntryElement([]byte("---\ntags: [Go, blog, go]\n---\n# title\n\ncontent"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := interactor.Get(id, authorized)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := []string{"blog", "go"}; !reflect.DeepEqual(entry.Tags, expect) {
+		t.Errorf("want tags %v, got %v", expect, entry.Tags)
+	}
+
+	tags, err := interactor.GetTags(authorized)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expect := []*domain.Tag{
+		&domain.Tag{Name: "blog", Count: 1},
+		&domain.Tag{Name: "go", Count: 1},
+	}
+	if !reflect.DeepEqual(tags, expect) {
+		t.Errorf("want tags %v, got %v", expect, tags)
+	}
+}
+
+func TestPostEntrySlug(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+	cases := []struct {
+		input      []byte
+		expectSlug string
+		err        error
+	}{
+		{[]byte("# Hello, World\n\ncontent"), "hello-world", nil},
+		{[]byte("# hello world\n\ncontent"), "hello-world-2", nil},
+		{[]byte("# [wip] hello world!\n\ncontent"), "hello-world-3", nil},
+		{[]byte("---\nslug: My Slug\n---\n# other\n\ncontent"), "my-slug", nil},
+		{[]byte("---\nslug: hello-world\n---\n# another\n\ncontent"), "", config.ErrDuplicatedSlug},
+		{[]byte("# Don't & panic\n\ncontent"), "don-t-panic", nil},
+		{[]byte("---\ntitle: Don't & panic\n---\ncontent"), "don-t-panic-2", nil},
+		{[]byte("# a < b\n\ncontent"), "a-b", nil},
+	}
+	for i, c := range cases {
+		element, err := NewEntryElement(c.input, getRenderer(t))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		id, err := interactor.Post(element)
+		if errors.Cause(err) != c.err {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.err, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		entry, err := interactor.Get(id, authorized)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if entry.Slug != c.expectSlug {
+			t.Errorf("#%d: want slug %s, got %s", i, c.expectSlug, entry.Slug)
+		}
+	}
+}
+
+func TestEditEntrySlug(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+	element, _ := NewEntryElement([]byte("# title\n\ncontent"), getRenderer(t))
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	// keep the slug unless specified
+	element, _ = NewEntryElement([]byte("# changed title\n\ncontent"), getRenderer(t))
+	if err := interactor.Edit(id, element); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, current, err := interactor.GetBySlug("title", authorized)
+	if err != nil || entry == nil || entry.ID != id || current != "title" {
+		t.Fatalf("want entry id %d by slug, got %#v, %s, %#v", id, entry, current, err)
+	}
+
+	element, _ = NewEntryElement([]byte("---\nslug: renamed\n---\n# changed title\n\ncontent"), getRenderer(t))
+	if err := interactor.Edit(id, element); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	cases := []struct {
+		input         string
+		expectFound   bool
+		expectCurrent string
+		err           error
+	}{
+		{"renamed", true, "renamed", nil},
+		{"title", false, "renamed", nil},
+		{"unknown", false, "", sql.ErrNoRows},
+	}
+	for i, c := range cases {
+		entry, current, err := interactor.GetBySlug(c.input, authorized)
+		if errors.Cause(err) != c.err {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.err, err)
+		}
+		if (entry != nil) != c.expectFound || current != c.expectCurrent {
+			t.Errorf("#%d: want found %t and current slug %s, got %#v and %s",
+				i, c.expectFound, c.expectCurrent, entry, current)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// Content types of the feeds
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=UTF-8"
+	ContentTypeAtom = "application/atom+xml; charset=UTF-8"
+)
+
+// Site represent the metadata of the blog
+type Site struct {
+	Title       string
+	Description string
+	URL         string
+	Author      string
+}
+
+// EntryURL returns the permalink of the entry
+func (s Site) EntryURL(id int) string {
+	return fmt.Sprintf("%s/entry/%d", strings.TrimRight(s.URL, "/"), id)
+}
+
+// Feed represent the generated feed document
+type Feed struct {
+	Body         []byte
+	ContentType  string
+	LastModified time.Time
+}
+
+// FeedInteractor generates the feeds of the public entries
+type FeedInteractor struct {
+	entryRepo repository.EntryRepository
+	site      Site
+	length    int
+}
+
+// NewFeedInteractor returns initialized FeedInteractor
+func NewFeedInteractor(e repository.EntryRepository, site Site, length int) *FeedInteractor {
+	return &FeedInteractor{
+		entryRepo: e,
+		site:      site,
+		length:    length,
+	}
+}
+
+// recent returns the public entries in order of newest, and the last modified time
+func (i *FeedInteractor) recent() ([]*domain.Entry, time.Time, error) {
+	entries, err := i.entryRepo.GetRecent(repository.EntryFilter{}, i.length)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var last time.Time
+	for _, e := range entries {
+		if e.UpdatedAt.After(last) {
+			last = e.UpdatedAt
+		}
+	}
+	return entries, last.UTC(), nil
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Categories  []string `xml:"category"`
+	Description string   `xml:"description"`
+}
+
+// RSS returns the RSS 2.0 feed
+func (i *FeedInteractor) RSS() (*Feed, error) {
+	entries, last, err := i.recent()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := rss{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       i.site.Title,
+			Link:        i.site.URL,
+			Description: i.site.Description,
+			Items:       make([]*rssItem, 0, len(entries)),
+		},
+	}
+	if !last.IsZero() {
+		doc.Channel.LastBuildDate = last.Format(time.RFC1123Z)
+	}
+	for _, e := range entries {
+		link := i.site.EntryURL(e.ID)
+		doc.Channel.Items = append(doc.Channel.Items, &rssItem{
+			Title:       html.UnescapeString(e.Title),
+			Link:        link,
+			GUID:        link,
+			PubDate:     e.CreatedAt.UTC().Format(time.RFC1123Z),
+			Categories:  e.Tags,
+			Description: e.Content,
+		})
+	}
+	return marshalFeed(doc, ContentTypeRSS, last)
+}
+
+type atom struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Link    atomLink     `xml:"link"`
+	Updated string       `xml:"updated"`
+	Author  *atomAuthor  `xml:"author,omitempty"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string          `xml:"title"`
+	ID         string          `xml:"id"`
+	Link       atomLink        `xml:"link"`
+	Published  string          `xml:"published"`
+	Updated    string          `xml:"updated"`
+	Categories []*atomCategory `xml:"category"`
+	Summary    *atomText       `xml:"summary,omitempty"`
+	Content    atomText        `xml:"content"`
+}
+
+// Atom returns the Atom feed
+func (i *FeedInteractor) Atom() (*Feed, error) {
+	entries, last, err := i.recent()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := atom{
+		Title:   i.site.Title,
+		ID:      i.site.URL,
+		Link:    atomLink{Href: i.site.URL},
+		Updated: last.Format(time.RFC3339),
+		Entries: make([]*atomEntry, 0, len(entries)),
+	}
+	if len(i.site.Author) != 0 {
+		doc.Author = &atomAuthor{Name: i.site.Author}
+	}
+	for _, e := range entries {
+		link := i.site.EntryURL(e.ID)
+		entry := &atomEntry{
+			Title:     html.UnescapeString(e.Title),
+			ID:        link,
+			Link:      atomLink{Href: link},
+			Published: e.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   e.UpdatedAt.UTC().Format(time.RFC3339),
+			Content:   atomText{Type: "html", Body: e.Content},
+		}
+		for _, t := range e.Tags {
+			entry.Categories = append(entry.Categories, &atomCategory{Term: t})
+		}
+		if len(e.Summary) != 0 {
+			entry.Summary = &atomText{Type: "text", Body: e.Summary}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshalFeed(doc, ContentTypeAtom, last)
+}
+
+func marshalFeed(doc interface{}, contentType string, lastModified time.Time) (*Feed, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &Feed{
+		Body:         append([]byte(xml.Header), body...),
+		ContentType:  contentType,
+		LastModified: lastModified,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFeed(t *testing.T) {
+	loadFixture(t, "testdata/feed_entries.yml")
+
+	site := Site{Title: "blog", URL: "http://example.com/", Author: "author"}
+	interactor := NewFeedInteractor(getEntryRepository(t), site, 10)
+	expectLastModified := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	rssFeed, err := interactor.RSS()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if rssFeed.ContentType != ContentTypeRSS || !rssFeed.LastModified.Equal(expectLastModified) {
+		t.Errorf("want %s and %v, got %s and %v", ContentTypeRSS, expectLastModified, rssFeed.ContentType, rssFeed.LastModified)
+	}
+	rssDoc := rss{}
+	if err := xml.Unmarshal(rssFeed.Body, &rssDoc); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectItems := []rssItem{
+		{
+			Title:       "second",
+			Link:        "http://example.com/entry/2",
+			GUID:        "http://example.com/entry/2",
+			PubDate:     "Fri, 02 Mar 2018 00:00:00 +0000",
+			Description: "<p>second content</p>",
+		},
+		{
+			Title:       "first",
+			Link:        "http://example.com/entry/1",
+			GUID:        "http://example.com/entry/1",
+			PubDate:     "Thu, 01 Mar 2018 00:00:00 +0000",
+			Description: "<p>first content</p>",
+		},
+	}
+	if len(rssDoc.Channel.Items) != len(expectItems) {
+		t.Fatalf("want %d items, got %d", len(expectItems), len(rssDoc.Channel.Items))
+	}
+	for i, item := range rssDoc.Channel.Items {
+		if !reflect.DeepEqual(*item, expectItems[i]) {
+			t.Errorf("#%d: want %v, got %v", i, expectItems[i], *item)
+		}
+	}
+
+	atomFeed, err := interactor.Atom()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	atomDoc := atom{}
+	if err := xml.Unmarshal(atomFeed.Body, &atomDoc); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if atomDoc.Updated != "2018-03-05T00:00:00Z" || atomDoc.Author == nil || atomDoc.Author.Name != "author" {
+		t.Errorf("want updated and author, got %s and %v", atomDoc.Updated, atomDoc.Author)
+	}
+	if len(atomDoc.Entries) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(atomDoc.Entries))
+	}
+	second := atomDoc.Entries[0]
+	if second.ID != "http://example.com/entry/2" || second.Summary == nil || second.Summary.Body != "summary of the second" ||
+		second.Content.Type != "html" || second.Content.Body != "<p>second content</p>" {
+		t.Errorf("want the second entry, got %#v", second)
+	}
+	if first := atomDoc.Entries[1]; first.Published != "2018-03-01T00:00:00Z" || first.Updated != "2018-03-05T00:00:00Z" {
+		t.Errorf("want the timestamps, got %s and %s", first.Published, first.Updated)
+	}
+}
+
+func TestFeedTitle(t *testing.T) {
+	loadFixture(t, "testdata/feed_html_title.yml")
+
+	interactor := NewFeedInteractor(getEntryRepository(t), Site{Title: "blog", URL: "http://example.com/"}, 10)
+	expect := "Don't Tom & Jerry"
+
+	rssFeed, err := interactor.RSS()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	rssDoc := rss{}
+	if err := xml.Unmarshal(rssFeed.Body, &rssDoc); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(rssDoc.Channel.Items) != 1 || rssDoc.Channel.Items[0].Title != expect {
+		t.Errorf("want title %s, got %s", expect, rssFeed.Body)
+	}
+
+	atomFeed, err := interactor.Atom()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	atomDoc := atom{}
+	if err := xml.Unmarshal(atomFeed.Body, &atomDoc); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(atomDoc.Entries) != 1 || atomDoc.Entries[0].Title != expect {
+		t.Errorf("want title %s, got %s", expect, atomFeed.Body)
+	}
+}
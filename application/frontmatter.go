@@ -0,0 +1,91 @@
+package application
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FrontMatter represent metadata at the top of the markdown
+// Surrounded by "---" as YAML, or "+++" as TOML
+type FrontMatter struct {
+	Title   string      `yaml:"title" toml:"title"`
+	Slug    string      `yaml:"slug" toml:"slug"`
+	Tags    []string    `yaml:"tags" toml:"tags"`
+	Status  string      `yaml:"status" toml:"status"`
+	Date    interface{} `yaml:"date" toml:"date"`
+	Summary string      `yaml:"summary" toml:"summary"`
+}
+
+// acceptable layouts of the date in the front matter
+var frontMatterDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// PublishAt returns the parsed date
+// Returns nil when the date is not specified
+func (f *FrontMatter) PublishAt() (*time.Time, error) {
+	switch d := f.Date.(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &d, nil
+	case string:
+		for _, layout := range frontMatterDateLayouts {
+			if t, err := time.ParseInLocation(layout, d, time.Local); err == nil {
+				return &t, nil
+			}
+		}
+	}
+	return nil, errors.Wrapf(config.ErrInvalidFrontMatter, "unsupported date format: %v", f.Date)
+}
+
+// splitFrontMatter returns the front matter and the rest of the markdown
+// Returns nil front matter when the data doesn't begin with the front matter
+func splitFrontMatter(data []byte) (*FrontMatter, []byte, error) {
+	var delim []byte
+	switch {
+	case bytes.HasPrefix(data, []byte("---")):
+		delim = []byte("---")
+	case bytes.HasPrefix(data, []byte("+++")):
+		delim = []byte("+++")
+	default:
+		return nil, data, nil
+	}
+
+	// delimiters must be a whole line
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if !bytes.Equal(bytes.TrimSpace(lines[0]), delim) {
+		return nil, data, nil
+	}
+	offset := len(lines[0])
+	for _, l := range lines[1:] {
+		if !bytes.Equal(bytes.TrimSpace(l), delim) {
+			offset += len(l)
+			continue
+		}
+
+		raw := data[len(lines[0]):offset]
+		body := data[offset+len(l):]
+		meta := &FrontMatter{}
+		var err error
+		if delim[0] == '-' {
+			err = yaml.Unmarshal(raw, meta)
+		} else {
+			err = toml.Unmarshal(raw, meta)
+		}
+		if err != nil {
+			return nil, nil, errors.Wrap(config.ErrInvalidFrontMatter, err.Error())
+		}
+		return meta, body, nil
+	}
+	return nil, nil, errors.Wrap(config.ErrInvalidFrontMatter, fmt.Sprintf("not found closing %q", delim))
+}
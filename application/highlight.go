@@ -0,0 +1,250 @@
+package application
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// syntax represent the lexical rules of a language to highlight
+type syntax struct {
+	keywords      map[string]bool
+	lineComments  []string
+	blockComments [][2]string
+	quotes        string
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	syntaxGo = &syntax{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var
+			true false nil iota`),
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "\"'`",
+	}
+	syntaxC = &syntax{
+		keywords: words(`auto break case char const continue default do double else enum extern float for goto
+			if inline int long register return short signed sizeof static struct switch typedef union
+			unsigned void volatile while class namespace template typename public private protected
+			virtual new delete this true false nullptr bool`),
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "\"'",
+	}
+	syntaxJava = &syntax{
+		keywords: words(`abstract boolean break byte case catch char class const continue default do double
+			else enum extends final finally float for if implements import instanceof int interface long
+			new package private protected public return short static super switch synchronized this
+			throw throws try void volatile while true false null var`),
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "\"'",
+	}
+	syntaxJavaScript = &syntax{
+		keywords: words(`async await break case catch class const continue default delete do else export
+			extends finally for from function if import in instanceof let new of return static super
+			switch this throw try typeof var void while yield true false null undefined
+			interface type enum implements`),
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "\"'`",
+	}
+	syntaxRust = &syntax{
+		keywords: words(`as async await break const continue crate else enum extern false fn for if impl in
+			let loop match mod move mut pub ref return self Self static struct super trait true type
+			unsafe use where while`),
+		lineComments:  []string{"//"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "\"",
+	}
+	syntaxPython = &syntax{
+		keywords: words(`and as assert async await break class continue def del elif else except finally for
+			from global if import in is lambda nonlocal not or pass raise return try while with yield
+			True False None`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	syntaxRuby = &syntax{
+		keywords: words(`alias and begin break case class def do else elsif end ensure false for if
+			in module next nil not or redo rescue retry return self super then true undef unless until
+			when while yield`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	syntaxShell = &syntax{
+		keywords: words(`case do done elif else esac export fi for function if in local readonly return
+			select then until while`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	syntaxSQL = &syntax{
+		keywords: words(`select from where and or not insert into values update set delete create table
+			drop alter index primary key foreign references join left right inner outer on as order by
+			group having limit offset union distinct null is in like between exists case when then else
+			end default unique int integer varchar text datetime
+			SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE
+			DROP ALTER INDEX PRIMARY KEY FOREIGN REFERENCES JOIN LEFT RIGHT INNER OUTER ON AS ORDER BY
+			GROUP HAVING LIMIT OFFSET UNION DISTINCT NULL IS IN LIKE BETWEEN EXISTS CASE WHEN THEN ELSE
+			END DEFAULT UNIQUE INT INTEGER VARCHAR TEXT DATETIME`),
+		lineComments:  []string{"--"},
+		blockComments: [][2]string{{"/*", "*/"}},
+		quotes:        "'\"`",
+	}
+	syntaxJSON = &syntax{
+		keywords: words(`true false null`),
+		quotes:   "\"",
+	}
+)
+
+// syntaxes is the languages to highlight by the info string of the code blocks
+var syntaxes = map[string]*syntax{
+	"go":         syntaxGo,
+	"golang":     syntaxGo,
+	"c":          syntaxC,
+	"cpp":        syntaxC,
+	"c++":        syntaxC,
+	"java":       syntaxJava,
+	"javascript": syntaxJavaScript,
+	"js":         syntaxJavaScript,
+	"typescript": syntaxJavaScript,
+	"ts":         syntaxJavaScript,
+	"rust":       syntaxRust,
+	"rs":         syntaxRust,
+	"python":     syntaxPython,
+	"py":         syntaxPython,
+	"ruby":       syntaxRuby,
+	"rb":         syntaxRuby,
+	"sh":         syntaxShell,
+	"bash":       syntaxShell,
+	"shell":      syntaxShell,
+	"zsh":        syntaxShell,
+	"sql":        syntaxSQL,
+	"json":       syntaxJSON,
+}
+
+// Classes of the highlighted tokens
+const (
+	classKeyword = "hl-keyword"
+	classString  = "hl-string"
+	classComment = "hl-comment"
+	classNumber  = "hl-number"
+)
+
+// highlight returns the HTML of the code surrounded the tokens by <span> tags
+// Returns false when the language is not supported
+func highlight(info, code string) (string, bool) {
+	lang := strings.ToLower(strings.Fields(info + " ")[0])
+	s, ok := syntaxes[lang]
+	if !ok {
+		return "", false
+	}
+
+	var b strings.Builder
+	token := func(class, text string) {
+		b.WriteString(`<span class="` + class + `">`)
+		b.WriteString(html.EscapeString(text))
+		b.WriteString("</span>")
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+		if n := s.comment(rest); n > 0 {
+			token(classComment, rest[:n])
+			i += n
+			continue
+		}
+		if strings.IndexByte(s.quotes, rest[0]) >= 0 {
+			n := quoted(rest)
+			token(classString, rest[:n])
+			i += n
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(rest)
+		if isIdentRune(r) {
+			n := identLength(rest)
+			w := rest[:n]
+			switch {
+			case s.keywords[w]:
+				token(classKeyword, w)
+			case unicode.IsDigit(r):
+				token(classNumber, w)
+			default:
+				b.WriteString(html.EscapeString(w))
+			}
+			i += n
+			continue
+		}
+		b.WriteString(html.EscapeString(rest[:size]))
+		i += size
+	}
+	return b.String(), true
+}
+
+// comment returns the length of the comment at the beginning of the code, 0 when not a comment
+func (s *syntax) comment(code string) int {
+	for _, c := range s.lineComments {
+		if strings.HasPrefix(code, c) {
+			if n := strings.IndexByte(code, '\n'); n >= 0 {
+				return n
+			}
+			return len(code)
+		}
+	}
+	for _, c := range s.blockComments {
+		if strings.HasPrefix(code, c[0]) {
+			if n := strings.Index(code[len(c[0]):], c[1]); n >= 0 {
+				return len(c[0]) + n + len(c[1])
+			}
+			return len(code)
+		}
+	}
+	return 0
+}
+
+// quoted returns the length of the string literal at the beginning of the code
+// The string is closed by the same quote, or the end of the line except for the back quote
+func quoted(code string) int {
+	quote := code[0]
+	for i := 1; i < len(code); i++ {
+		switch code[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(code)
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// identLength returns the length of the identifier or the number, including "." in the number
+func identLength(code string) int {
+	first, _ := utf8.DecodeRuneInString(code)
+	number := unicode.IsDigit(first)
+	for i, r := range code {
+		if !isIdentRune(r) && !(number && r == '.') {
+			return i
+		}
+	}
+	return len(code)
+}
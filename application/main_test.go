@@ -1,29 +1,47 @@
 package application
 
 import (
-	"os"
 	"testing"
 
 	"github.com/takashabe/lumber/domain/repository"
 	"github.com/takashabe/lumber/helper"
-	"github.com/takashabe/lumber/infrastructure/persistence"
+	"github.com/takashabe/lumber/infrastructure/memory"
+	"github.com/takashabe/lumber/infrastructure/search"
 )
 
-func TestMain(m *testing.M) {
-	helper.SetupTables()
-	os.Exit(m.Run())
+var (
+	entryRepository  = memory.NewEntryRepository()
+	tokenRepository  = memory.NewTokenRepository()
+	userRepository   = memory.NewUserRepository()
+	searchRepository = search.NewSearchRepository()
+	auditRepository  = memory.NewAuditRepository()
+
+	// authorized matches the private entries of the fixtures as well
+	authorized = repository.EntryFilter{IncludePrivate: true}
+)
+
+func loadFixture(t *testing.T, file string) {
+	helper.LoadMemoryFixture(t, file, entryRepository, tokenRepository, userRepository)
 }
 
 func getEntryRepository(t *testing.T) repository.EntryRepository {
-	r, err := persistence.NewEntryRepository()
-	if err != nil {
-		t.Fatalf("want non error, got %#v", err)
-	}
-	return r
+	return entryRepository
+}
+
+func getSearchRepository(t *testing.T) repository.SearchRepository {
+	return searchRepository
+}
+
+func getAuditRepository(t *testing.T) repository.AuditRepository {
+	return auditRepository
 }
 
 func getTokenRepository(t *testing.T) repository.TokenRepository {
-	r, err := persistence.NewTokenRepository()
+	return tokenRepository
+}
+
+func getRenderer(t *testing.T) Renderer {
+	r, err := NewRenderer(ExtensionTables)
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
 	}
@@ -0,0 +1,128 @@
+package application
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// TitlePage represent a page of the entries with contain id and title
+// Next and Prev are the opaque cursors of the adjacent pages, empty when no more entries
+type TitlePage struct {
+	Entries []*domain.Entry
+	Total   int
+	Next    string
+	Prev    string
+}
+
+// pageCursor is the content of the opaque cursor
+type pageCursor struct {
+	Key    repository.EntrySortKey `json:"k"`
+	Desc   bool                    `json:"d,omitempty"`
+	Before bool                    `json:"b,omitempty"`
+	ID     int                     `json:"id"`
+	Title  string                  `json:"t,omitempty"`
+	Time   time.Time               `json:"at"`
+}
+
+func encodeCursor(s repository.EntrySort, before bool, e *domain.Entry) string {
+	c := repository.NewEntryCursor(s, e)
+	data, _ := json.Marshal(pageCursor{
+		Key:    s.Key,
+		Desc:   s.Desc,
+		Before: before,
+		ID:     c.ID,
+		Title:  c.Title,
+		Time:   c.Time,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor returns the query of the cursor
+// The cursor must be issued in the same order
+func decodeCursor(s repository.EntrySort, cursor string) (repository.EntryPageQuery, error) {
+	q := repository.EntryPageQuery{Sort: s}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return q, errors.Wrapf(config.ErrInvalidPageQuery, "cursor: %s", cursor)
+	}
+	c := pageCursor{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return q, errors.Wrapf(config.ErrInvalidPageQuery, "cursor: %s", cursor)
+	}
+	if c.Key != s.Key || c.Desc != s.Desc {
+		return q, errors.Wrapf(config.ErrInvalidPageQuery, "cursor is issued in the other order: %s", cursor)
+	}
+
+	position := &repository.EntryCursor{ID: c.ID, Title: c.Title, Time: c.Time}
+	if c.Before {
+		q.Before = position
+	} else {
+		q.After = position
+	}
+	return q, nil
+}
+
+// GetTitlePage returns the page of the entries matched by the filter in the order
+// Returns the first page when the cursor is empty, and the limit is DefaultPageLimit unless specified
+func (i *EntryInteractor) GetTitlePage(f repository.EntryFilter, s repository.EntrySort, cursor string, limit int) (*TitlePage, error) {
+	if len(s.Key) == 0 {
+		s.Key = repository.SortByCreatedAt
+	}
+	if !s.Key.IsValid() {
+		return nil, errors.Wrapf(config.ErrInvalidPageQuery, "sort: %s", s.Key)
+	}
+	if limit < 0 || limit > config.MaxPageLimit {
+		return nil, errors.Wrapf(config.ErrInvalidPageQuery, "limit: %d", limit)
+	}
+	if limit == 0 {
+		limit = config.DefaultPageLimit
+	}
+	q := repository.EntryPageQuery{Sort: s}
+	if len(cursor) != 0 {
+		var err error
+		q, err = decodeCursor(s, cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// read one more entry to know whether the next or previous page exists
+	q.Limit = limit + 1
+	entries, err := i.entryRepo.GetTitlePage(f, q)
+	if err != nil {
+		return nil, err
+	}
+	total, err := i.entryRepo.Count(f)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TitlePage{Total: total}
+	more := len(entries) > limit
+	if q.Before != nil {
+		if more {
+			entries = entries[1:]
+		}
+	} else if more {
+		entries = entries[:limit]
+	}
+	page.Entries = entries
+	if len(entries) == 0 {
+		return page, nil
+	}
+
+	// the entry of the cursor is always on the other side
+	if more || q.Before != nil {
+		page.Next = encodeCursor(s, false, entries[len(entries)-1])
+	}
+	if (more && q.Before != nil) || q.After != nil {
+		page.Prev = encodeCursor(s, true, entries[0])
+	}
+	return page, nil
+}
@@ -0,0 +1,114 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+func TestGetTitlePage(t *testing.T) {
+	loadFixture(t, "testdata/page_entries.yml")
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+
+	pageIDs := func(p *TitlePage) []int {
+		ids := make([]int, 0)
+		for _, e := range p.Entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	cases := []struct {
+		sort         repository.EntrySort
+		expectFirst  []int
+		expectSecond []int
+		expectLast   []int
+	}{
+		{repository.EntrySort{}, []int{1, 2}, []int{3, 4}, []int{5}},
+		{repository.EntrySort{Desc: true}, []int{5, 4}, []int{3, 2}, []int{1}},
+		{repository.EntrySort{Key: repository.SortByTitle}, []int{2, 4}, []int{1, 5}, []int{3}},
+		{repository.EntrySort{Key: repository.SortByUpdatedAt, Desc: true}, []int{1, 3}, []int{5, 4}, []int{2}},
+	}
+	for i, c := range cases {
+		// forward to the last page
+		first, err := interactor.GetTitlePage(authorized, c.sort, "", 2)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		second, err := interactor.GetTitlePage(authorized, c.sort, first.Next, 2)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		last, err := interactor.GetTitlePage(authorized, c.sort, second.Next, 2)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		for j, p := range []struct {
+			page   *TitlePage
+			expect []int
+		}{{first, c.expectFirst}, {second, c.expectSecond}, {last, c.expectLast}} {
+			if ids := pageIDs(p.page); !reflect.DeepEqual(ids, p.expect) {
+				t.Errorf("#%d-%d: want ids %v, got %v", i, j, p.expect, ids)
+			}
+			if p.page.Total != 5 {
+				t.Errorf("#%d-%d: want total 5, got %d", i, j, p.page.Total)
+			}
+		}
+		if len(first.Prev) != 0 || len(last.Next) != 0 {
+			t.Errorf("#%d: want no cursors at the ends, got prev %q, next %q", i, first.Prev, last.Next)
+		}
+
+		// backward to the first page
+		back, err := interactor.GetTitlePage(authorized, c.sort, last.Prev, 2)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if ids := pageIDs(back); !reflect.DeepEqual(ids, c.expectSecond) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectSecond, ids)
+		}
+		back, err = interactor.GetTitlePage(authorized, c.sort, back.Prev, 2)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if ids := pageIDs(back); !reflect.DeepEqual(ids, c.expectFirst) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectFirst, ids)
+		}
+		if len(back.Prev) != 0 || len(back.Next) == 0 {
+			t.Errorf("#%d: want only the next cursor, got prev %q, next %q", i, back.Prev, back.Next)
+		}
+	}
+}
+
+func TestGetTitlePageInvalidQuery(t *testing.T) {
+	loadFixture(t, "testdata/page_entries.yml")
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+
+	first, err := interactor.GetTitlePage(authorized, repository.EntrySort{}, "", 2)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		sort   repository.EntrySort
+		cursor string
+		limit  int
+	}{
+		{repository.EntrySort{Key: "unknown"}, "", 0},
+		{repository.EntrySort{}, "", -1},
+		{repository.EntrySort{}, "", config.MaxPageLimit + 1},
+		{repository.EntrySort{}, "!", 0},
+		{repository.EntrySort{}, "e30", 0},
+		// the cursor is issued in the other order
+		{repository.EntrySort{Desc: true}, first.Next, 0},
+		{repository.EntrySort{Key: repository.SortByTitle}, first.Next, 0},
+	}
+	for i, c := range cases {
+		_, err := interactor.GetTitlePage(authorized, c.sort, c.cursor, c.limit)
+		if errors.Cause(err) != config.ErrInvalidPageQuery {
+			t.Errorf("#%d: want %v, got %v", i, config.ErrInvalidPageQuery, err)
+		}
+	}
+}
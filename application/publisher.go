@@ -0,0 +1,59 @@
+package application
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// DefaultPublishInterval is the interval to check the scheduled entries
+const DefaultPublishInterval = time.Minute
+
+// Publisher publishes the scheduled entries when the publish date has come
+type Publisher struct {
+	entryRepo repository.EntryRepository
+	interval  time.Duration
+
+	// Now returns the current time, replaceable for testing
+	Now func() time.Time
+}
+
+// NewPublisher returns initialized Publisher
+func NewPublisher(e repository.EntryRepository, interval time.Duration) *Publisher {
+	if interval <= 0 {
+		interval = DefaultPublishInterval
+	}
+	return &Publisher{
+		entryRepo: e,
+		interval:  interval,
+		Now:       time.Now,
+	}
+}
+
+// Publish changes the due scheduled entries to public
+// Returns number of published entries
+func (p *Publisher) Publish() (int, error) {
+	return p.entryRepo.PublishScheduled(p.Now())
+}
+
+// Run publishes the scheduled entries at every interval until the context is done
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.Publish(); err != nil {
+			log.Printf("failed to publish scheduled entries: %v", err)
+		} else if n > 0 {
+			log.Printf("published %d scheduled entries", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
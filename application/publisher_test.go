@@ -0,0 +1,97 @@
+package application
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+func TestPublisher(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+
+	interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+	element, err := NewEntryElement([]byte("---\nstatus: scheduled\ndate: 2018-03-01T10:00:00+09:00\n---\n# title\n\ncontent"), getRenderer(t))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := interactor.Post(element)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	publishAt := time.Date(2018, 3, 1, 1, 0, 0, 0, time.UTC)
+	publisher := NewPublisher(getEntryRepository(t), 0)
+	cases := []struct {
+		now         time.Time
+		expectCount int
+		expectIDs   []int
+		err         error
+	}{
+		{publishAt.Add(-time.Second), 0, []int{}, sql.ErrNoRows},
+		{publishAt, 1, []int{id}, nil},
+		{publishAt.Add(time.Second), 0, []int{id}, nil},
+	}
+	for i, c := range cases {
+		publisher.Now = func() time.Time { return c.now }
+		n, err := publisher.Publish()
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expectCount {
+			t.Errorf("#%d: want %d published, got %d", i, c.expectCount, n)
+		}
+
+		ids, err := interactor.GetIDs(repository.EntryFilter{})
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+		if _, err := interactor.Get(id, repository.EntryFilter{}); errors.Cause(err) != c.err {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.err, err)
+		}
+	}
+}
+
+func TestPostScheduledEntry(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+
+	cases := []struct {
+		input []byte
+		err   error
+	}{
+		{[]byte("---\nstatus: scheduled\ndate: 2018-03-01\n---\n# title\n\ncontent"), nil},
+		{[]byte("---\nstatus: scheduled\n---\n# without date\n\ncontent"), config.ErrEmptyPublishAt},
+	}
+	for i, c := range cases {
+		element, err := NewEntryElement(c.input, getRenderer(t))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		interactor := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+		id, err := interactor.Post(element)
+		if errors.Cause(err) != c.err {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.err, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		// keep the publish date unless specified
+		element, _ = NewEntryElement([]byte("# title\n\nchanged content"), getRenderer(t))
+		entry, err := interactor.Lookup(id)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		element.SetDefaultStatus(entry.Status)
+		if err := interactor.Edit(id, element); err != nil {
+			t.Errorf("#%d: want non error, got %#v", i, err)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/russross/blackfriday"
+	"github.com/takashabe/lumber/config"
+)
+
+// Renderer converts the markdown into HTML
+type Renderer interface {
+	// Render returns the HTML of the whole markdown
+	Render(markdown []byte) string
+	// RenderWithTitle returns the first block as the title and the HTML of the rest
+	RenderWithTitle(markdown []byte) (title, content string)
+}
+
+// Names of the markdown extensions
+const (
+	ExtensionTables         = "tables"
+	ExtensionFootnotes      = "footnotes"
+	ExtensionHeadingAnchors = "anchors"
+	ExtensionTOC            = "toc"
+	ExtensionHighlight      = "highlight"
+	ExtensionEmoji          = "emoji"
+)
+
+// MarkdownRenderer implements the Renderer by blackfriday
+type MarkdownRenderer struct {
+	extensions blackfriday.Extensions
+	flags      blackfriday.HTMLFlags
+
+	anchors   bool
+	highlight bool
+	emoji     bool
+}
+
+// NewRenderer returns initialized MarkdownRenderer enabled the extensions
+// Without any extensions, renders the common markdown except for tables
+func NewRenderer(extensions ...string) (Renderer, error) {
+	r := &MarkdownRenderer{
+		extensions: blackfriday.CommonExtensions &^ blackfriday.Tables,
+		flags:      blackfriday.CommonHTMLFlags,
+	}
+	for _, ext := range extensions {
+		switch strings.TrimSpace(ext) {
+		case "":
+		case ExtensionTables:
+			r.extensions |= blackfriday.Tables
+		case ExtensionFootnotes:
+			r.extensions |= blackfriday.Footnotes
+			r.flags |= blackfriday.FootnoteReturnLinks
+		case ExtensionHeadingAnchors:
+			r.extensions |= blackfriday.AutoHeadingIDs
+			r.anchors = true
+		case ExtensionTOC:
+			r.flags |= blackfriday.TOC
+		case ExtensionHighlight:
+			r.highlight = true
+		case ExtensionEmoji:
+			r.emoji = true
+		default:
+			return nil, errors.Wrapf(config.ErrUnknownRenderExtension, "%q", ext)
+		}
+	}
+	return r, nil
+}
+
+// Render returns the HTML of the whole markdown
+func (r *MarkdownRenderer) Render(markdown []byte) string {
+	return r.render(r.parse(markdown))
+}
+
+// RenderWithTitle returns the inner HTML of the first block as the title and the HTML of the rest
+func (r *MarkdownRenderer) RenderWithTitle(markdown []byte) (title, content string) {
+	doc := r.parse(markdown)
+	first := doc.FirstChild
+	if first == nil {
+		return "", ""
+	}
+
+	var buf bytes.Buffer
+	nr := r.nodeRenderer()
+	// the title is shown outside of the content, without the anchor
+	nr.anchors = false
+	first.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		return nr.RenderNode(&buf, node, entering)
+	})
+	first.Unlink()
+	return trimHTMLTag(strings.TrimSpace(buf.String())), r.render(doc)
+}
+
+func (r *MarkdownRenderer) parse(markdown []byte) *blackfriday.Node {
+	doc := blackfriday.New(blackfriday.WithExtensions(r.extensions)).Parse(markdown)
+	if r.emoji {
+		// replace before rendering to be applied to the table of contents as well
+		doc.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+			if node.Type == blackfriday.Text {
+				node.Literal = replaceEmoji(node.Literal)
+			}
+			return blackfriday.GoToNext
+		})
+	}
+	return doc
+}
+
+func (r *MarkdownRenderer) render(doc *blackfriday.Node) string {
+	var buf bytes.Buffer
+	nr := r.nodeRenderer()
+	nr.RenderHeader(&buf, doc)
+	doc.Walk(func(node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+		return nr.RenderNode(&buf, node, entering)
+	})
+	nr.RenderFooter(&buf, doc)
+	return strings.TrimSpace(buf.String())
+}
+
+// nodeRenderer returns the renderer for a document
+// HTMLRenderer keeps the state of the document, e.g. the used heading ids
+func (r *MarkdownRenderer) nodeRenderer() *nodeRenderer {
+	return &nodeRenderer{
+		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: r.flags,
+		}),
+		anchors:   r.anchors,
+		highlight: r.highlight,
+	}
+}
+
+// nodeRenderer decorates the nodes rendered by HTMLRenderer
+type nodeRenderer struct {
+	*blackfriday.HTMLRenderer
+
+	anchors   bool
+	highlight bool
+}
+
+// placeholder is replaced with the decorated HTML after rendered by HTMLRenderer
+// HTMLRenderer writes it as it is, and the markdown never contains it
+var placeholder = []byte("\x00")
+
+var headingIDRegexp = regexp.MustCompile(`id="([^"]*)"`)
+
+// RenderNode renders the node by HTMLRenderer and decorates it by the extensions
+func (r *nodeRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	switch {
+	case node.Type == blackfriday.CodeBlock && r.highlight:
+		code, ok := highlight(string(node.CodeBlockData.Info), string(node.Literal))
+		if !ok {
+			break
+		}
+		var buf bytes.Buffer
+		literal := node.Literal
+		node.Literal = placeholder
+		status := r.HTMLRenderer.RenderNode(&buf, node, entering)
+		node.Literal = literal
+		w.Write(bytes.Replace(buf.Bytes(), placeholder, []byte(code), 1))
+		return status
+
+	case node.Type == blackfriday.Heading && r.anchors && entering:
+		var buf bytes.Buffer
+		status := r.HTMLRenderer.RenderNode(&buf, node, entering)
+		w.Write(buf.Bytes())
+		// link to the id of the output, which has been made unique by HTMLRenderer
+		if m := headingIDRegexp.FindSubmatch(buf.Bytes()); m != nil {
+			io.WriteString(w, `<a class="anchor" href="#`+string(m[1])+`">#</a>`)
+		}
+		return status
+	}
+	return r.HTMLRenderer.RenderNode(w, node, entering)
+}
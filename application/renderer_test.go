@@ -0,0 +1,146 @@
+package application
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+)
+
+func TestNewRenderer(t *testing.T) {
+	cases := []struct {
+		input     []string
+		expectErr error
+	}{
+		{nil, nil},
+		{[]string{"tables", " footnotes ", "anchors", "toc", "highlight", "emoji", ""}, nil},
+		{[]string{"tables", "mermaid"}, config.ErrUnknownRenderExtension},
+	}
+	for i, c := range cases {
+		_, err := NewRenderer(c.input...)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		extensions   []string
+		input        string
+		expectHas    []string
+		expectHasNot []string
+	}{
+		{
+			nil,
+			"| a | b |\n|---|---|\n| 1 | 2 |\n",
+			nil,
+			[]string{"<table>"},
+		},
+		{
+			[]string{ExtensionTables},
+			"| a | b |\n|---|---|\n| 1 | 2 |\n",
+			[]string{"<table>", "<th>a</th>", "<td>2</td>"},
+			nil,
+		},
+		{
+			[]string{ExtensionFootnotes},
+			"text[^1]\n\n[^1]: note\n",
+			[]string{`<a rel="footnote" href="#fn:1">1</a>`, `<li id="fn:1">note`},
+			nil,
+		},
+		{
+			[]string{ExtensionHeadingAnchors},
+			"## Heading\n\n## Heading\n",
+			[]string{
+				`<h2 id="heading"><a class="anchor" href="#heading">#</a>Heading</h2>`,
+				`<h2 id="heading-1"><a class="anchor" href="#heading-1">#</a>Heading</h2>`,
+			},
+			nil,
+		},
+		{
+			[]string{ExtensionTOC},
+			"## First\n\n### Second\n",
+			[]string{"<nav>", `<a href="#toc_0">First</a>`, `<a href="#toc_1">Second</a>`, `<h3 id="toc_1">Second</h3>`},
+			nil,
+		},
+		{
+			[]string{ExtensionHighlight},
+			"```go\nfunc main() { // c\n\treturn \"s<\" + 1\n}\n```\n",
+			[]string{
+				`<pre><code class="language-go"><span class="hl-keyword">func</span> main() { <span class="hl-comment">// c</span>`,
+				`<span class="hl-keyword">return</span> <span class="hl-string">&#34;s&lt;&#34;</span> + <span class="hl-number">1</span>`,
+			},
+			nil,
+		},
+		{
+			[]string{ExtensionHighlight},
+			"```unknown\nif x < y\n```\n",
+			[]string{`<pre><code class="language-unknown">if x &lt; y`},
+			[]string{"<span"},
+		},
+		{
+			[]string{ExtensionEmoji},
+			"ship it :rocket: :unknown: `:tada:`\n",
+			[]string{"ship it \U0001F680 :unknown: <code>:tada:</code>"},
+			nil,
+		},
+	}
+	for i, c := range cases {
+		r, err := NewRenderer(c.extensions...)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		html := r.Render([]byte(c.input))
+		for _, s := range c.expectHas {
+			if !strings.Contains(html, s) {
+				t.Errorf("#%d: want contains %q, got %q", i, s, html)
+			}
+		}
+		for _, s := range c.expectHasNot {
+			if strings.Contains(html, s) {
+				t.Errorf("#%d: want not contains %q, got %q", i, s, html)
+			}
+		}
+	}
+}
+
+func TestRenderWithTitle(t *testing.T) {
+	cases := []struct {
+		extensions    []string
+		input         string
+		expectTitle   string
+		expectContent string
+	}{
+		{
+			nil,
+			"# title\n\ncontent",
+			"title",
+			"<p>content</p>",
+		},
+		{
+			[]string{ExtensionHeadingAnchors, ExtensionEmoji},
+			"# title :tada:\n\n## sub\n\ncontent",
+			"title \U0001F389",
+			"<h2 id=\"sub\"><a class=\"anchor\" href=\"#sub\">#</a>sub</h2>\n\n<p>content</p>",
+		},
+		{
+			[]string{ExtensionTOC},
+			"# title\n\n## sub",
+			"title",
+			"<nav>\n\n<ul>\n<li>\n<ul>\n<li><a href=\"#toc_0\">sub</a></li>\n</ul></li>\n</ul>\n\n</nav>\n\n<h2 id=\"toc_0\">sub</h2>",
+		},
+	}
+	for i, c := range cases {
+		r, err := NewRenderer(c.extensions...)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		title, content := r.RenderWithTitle([]byte(c.input))
+		if title != c.expectTitle || content != c.expectContent {
+			t.Errorf("#%d: want title %q and content %q, got title %q and content %q",
+				i, c.expectTitle, c.expectContent, title, content)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package application
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// SanitizePolicy represent the HTML allowed in the entries
+type SanitizePolicy struct {
+	// Elements is the allowed elements and their attributes
+	Elements map[string][]string
+	// IframeHosts is the hosts allowed as the source of <iframe>, "*.example.com" matches the subdomains
+	IframeHosts []string
+	// EmbedHosts is the hosts allowed as the source of <embed> and <object>
+	EmbedHosts []string
+}
+
+// globalAttributes are allowed for all the allowed elements
+var globalAttributes = []string{"id", "class", "title"}
+
+// DefaultSanitizePolicy returns the policy allowed the elements written by markdown
+func DefaultSanitizePolicy() SanitizePolicy {
+	return SanitizePolicy{
+		Elements: map[string][]string{
+			"a":          {"href", "rel", "name"},
+			"abbr":       nil,
+			"b":          nil,
+			"blockquote": {"cite"},
+			"br":         nil,
+			"caption":    nil,
+			"cite":       nil,
+			"code":       nil,
+			"dd":         nil,
+			"del":        nil,
+			"details":    nil,
+			"div":        nil,
+			"dl":         nil,
+			"dt":         nil,
+			"em":         nil,
+			"figcaption": nil,
+			"figure":     nil,
+			"h1":         nil,
+			"h2":         nil,
+			"h3":         nil,
+			"h4":         nil,
+			"h5":         nil,
+			"h6":         nil,
+			"hr":         nil,
+			"i":          nil,
+			"img":        {"src", "alt", "width", "height"},
+			"ins":        nil,
+			"kbd":        nil,
+			"li":         nil,
+			"mark":       nil,
+			"nav":        nil,
+			"ol":         {"start"},
+			"p":          nil,
+			"pre":        nil,
+			"q":          {"cite"},
+			"s":          nil,
+			"samp":       nil,
+			"small":      nil,
+			"span":       nil,
+			"strong":     nil,
+			"sub":        nil,
+			"summary":    nil,
+			"sup":        nil,
+			"table":      nil,
+			"tbody":      nil,
+			"td":         {"colspan", "rowspan", "align"},
+			"tfoot":      nil,
+			"th":         {"colspan", "rowspan", "align", "scope"},
+			"thead":      nil,
+			"tr":         nil,
+			"u":          nil,
+			"ul":         nil,
+			"var":        nil,
+			"video":      {"src", "poster", "controls", "width", "height", "loop", "muted", "preload"},
+			"audio":      {"src", "controls", "loop", "muted", "preload"},
+			"source":     {"src", "type"},
+		},
+	}
+}
+
+// Elements restricted by the source hosts, and their attribute of the source
+var (
+	iframeElements = map[string]string{"iframe": "src"}
+	embedElements  = map[string]string{"embed": "src", "object": "data"}
+)
+
+var iframeAttributes = []string{"src", "width", "height", "allow", "allowfullscreen", "frameborder", "loading"}
+
+var embedAttributes = map[string][]string{
+	"embed":  {"src", "type", "width", "height"},
+	"object": {"data", "type", "width", "height"},
+}
+
+// rawTextElements are removed with their contents
+var rawTextElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"xmp":      true,
+}
+
+// urlAttributes are allowed only the safe schemes
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"poster": true,
+	"data":   true,
+}
+
+// sanitizedRenderer sanitizes the HTML rendered by the Renderer
+type sanitizedRenderer struct {
+	renderer  Renderer
+	sanitizer *Sanitizer
+}
+
+// NewSanitizedRenderer returns the Renderer which sanitizes the rendered title and content
+func NewSanitizedRenderer(r Renderer, s *Sanitizer) Renderer {
+	return &sanitizedRenderer{
+		renderer:  r,
+		sanitizer: s,
+	}
+}
+
+// Render returns the sanitized HTML of the whole markdown
+func (r *sanitizedRenderer) Render(markdown []byte) string {
+	return r.sanitizer.Sanitize(r.renderer.Render(markdown))
+}
+
+// RenderWithTitle returns the sanitized title and content
+func (r *sanitizedRenderer) RenderWithTitle(markdown []byte) (title, content string) {
+	title, content = r.renderer.RenderWithTitle(markdown)
+	return r.sanitizer.Sanitize(title), r.sanitizer.Sanitize(content)
+}
+
+// Sanitizer removes the elements and attributes which are not allowed by the policy
+type Sanitizer struct {
+	elements    map[string]map[string]bool
+	iframeHosts []string
+	embedHosts  []string
+}
+
+// NewSanitizer returns initialized Sanitizer
+func NewSanitizer(p SanitizePolicy) *Sanitizer {
+	s := &Sanitizer{
+		elements:    make(map[string]map[string]bool),
+		iframeHosts: p.IframeHosts,
+		embedHosts:  p.EmbedHosts,
+	}
+	allow := func(name string, attrs []string) {
+		name = strings.ToLower(name)
+		if s.elements[name] == nil {
+			s.elements[name] = make(map[string]bool)
+		}
+		for _, a := range attrs {
+			s.elements[name][strings.ToLower(a)] = true
+		}
+		for _, a := range globalAttributes {
+			s.elements[name][a] = true
+		}
+	}
+	for name, attrs := range p.Elements {
+		allow(name, attrs)
+	}
+	if len(p.IframeHosts) != 0 {
+		allow("iframe", iframeAttributes)
+	}
+	if len(p.EmbedHosts) != 0 {
+		for name, attrs := range embedAttributes {
+			allow(name, attrs)
+		}
+	}
+	return s
+}
+
+// attribute represent a parsed attribute of the tag
+type attribute struct {
+	name  string
+	value string
+	// whether written without the value like "controls"
+	empty bool
+}
+
+// Sanitize returns the HTML which has only the allowed elements and attributes
+// Disallowed tags are removed and their text is kept, except for the scripts and the rejected embeds
+func (s *Sanitizer) Sanitize(src string) string {
+	var b strings.Builder
+	for i := 0; i < len(src); {
+		lt := strings.IndexByte(src[i:], '<')
+		if lt < 0 {
+			b.WriteString(src[i:])
+			break
+		}
+		b.WriteString(src[i : i+lt])
+		i += lt
+
+		rest := src[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i += skipTo(rest, "-->")
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			i += skipTo(rest, ">")
+		case strings.HasPrefix(rest, "</") && isTagNameStart(rest, 2):
+			name, n := tagName(rest[2:])
+			i += skipTo(rest, ">")
+			if _, ok := s.elements[name]; ok && n > 0 {
+				b.WriteString("</" + name + ">")
+			}
+		case isTagNameStart(rest, 1):
+			n := s.writeStartTag(&b, rest)
+			i += n
+		default:
+			b.WriteString("&lt;")
+			i++
+		}
+	}
+	return b.String()
+}
+
+// writeStartTag writes the sanitized start tag and returns the length of the consumed source
+func (s *Sanitizer) writeStartTag(b *strings.Builder, src string) int {
+	name, n := tagName(src[1:])
+	attrs, selfClosing, end := parseAttributes(src[1+n:])
+	if end < 0 {
+		// unclosed tag is not a tag, keeps the rest as the text
+		b.WriteString("&lt;")
+		return 1
+	}
+	end += 1 + n
+
+	if rawTextElements[name] {
+		return end + skipElement(src[end:], name)
+	}
+	if hostAttr, ok := iframeElements[name]; ok && !s.allowedSource(attrs, hostAttr, s.iframeHosts) {
+		return end + skipElement(src[end:], name)
+	}
+	if hostAttr, ok := embedElements[name]; ok && !s.allowedSource(attrs, hostAttr, s.embedHosts) {
+		// <embed> is a void element
+		if name == "embed" {
+			return end
+		}
+		return end + skipElement(src[end:], name)
+	}
+	allowed, ok := s.elements[name]
+	if !ok {
+		return end
+	}
+
+	b.WriteString("<" + name)
+	seen := make(map[string]bool)
+	for _, a := range attrs {
+		if !allowed[a.name] || seen[a.name] {
+			continue
+		}
+		seen[a.name] = true
+		if urlAttributes[a.name] && !safeURL(a.value) {
+			continue
+		}
+		b.WriteString(" " + a.name)
+		if !a.empty {
+			b.WriteString(`="` + escapeAttribute(a.value) + `"`)
+		}
+	}
+	if selfClosing {
+		b.WriteString(" /")
+	}
+	b.WriteString(">")
+	return end
+}
+
+// allowedSource returns whether the source attribute is the https URL of the allowed hosts
+func (s *Sanitizer) allowedSource(attrs []attribute, name string, hosts []string) bool {
+	for _, a := range attrs {
+		if a.name != name {
+			continue
+		}
+		u, err := url.Parse(strings.TrimSpace(a.value))
+		if err != nil || strings.ToLower(u.Scheme) != "https" {
+			return false
+		}
+		return matchHost(strings.ToLower(u.Hostname()), hosts)
+	}
+	return false
+}
+
+func matchHost(host string, hosts []string) bool {
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if strings.HasPrefix(h, "*.") {
+			if strings.HasSuffix(host, h[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == h {
+			return true
+		}
+	}
+	return false
+}
+
+// safeURL returns whether the URL is relative or has the safe scheme
+func safeURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+func escapeAttribute(s string) string {
+	return strings.NewReplacer(`&`, "&amp;", `<`, "&lt;", `>`, "&gt;", `"`, "&quot;").Replace(s)
+}
+
+func isTagNameStart(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// tagName returns the lower-cased name of the tag at the beginning and its length
+func tagName(s string) (string, int) {
+	n := 0
+	for n < len(s) {
+		c := s[n]
+		if !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c == '-') {
+			break
+		}
+		n++
+	}
+	return strings.ToLower(s[:n]), n
+}
+
+// parseAttributes parses the attributes until the end of the tag
+// Returns the offset just after ">", or -1 when the tag is not closed
+func parseAttributes(s string) (attrs []attribute, selfClosing bool, end int) {
+	i := 0
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '>':
+			return attrs, selfClosing, i + 1
+		case c == '/':
+			selfClosing = true
+			i++
+			continue
+		case isSpace(c):
+			i++
+			continue
+		}
+		selfClosing = false
+
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		a := attribute{name: strings.ToLower(s[start:i]), empty: true}
+		j := i
+		for j < len(s) && isSpace(s[j]) {
+			j++
+		}
+		if j < len(s) && s[j] == '=' {
+			j++
+			for j < len(s) && isSpace(s[j]) {
+				j++
+			}
+			a.empty = false
+			if j < len(s) && (s[j] == '"' || s[j] == '\'') {
+				q := strings.IndexByte(s[j+1:], s[j])
+				if q < 0 {
+					return nil, false, -1
+				}
+				a.value = s[j+1 : j+1+q]
+				j += q + 2
+			} else {
+				v := j
+				for j < len(s) && !isSpace(s[j]) && s[j] != '>' {
+					j++
+				}
+				a.value = s[v:j]
+			}
+			a.value = html.UnescapeString(a.value)
+			i = j
+		}
+		attrs = append(attrs, a)
+	}
+	return nil, false, -1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// skipTo returns the offset just after the delimiter, or the length when not found
+func skipTo(s, delim string) int {
+	if n := strings.Index(s, delim); n >= 0 {
+		return n + len(delim)
+	}
+	return len(s)
+}
+
+// skipElement returns the offset just after the end tag of the element, or the length when not found
+func skipElement(s, name string) int {
+	for i := 0; i < len(s); i++ {
+		if !strings.HasPrefix(s[i:], "</") || len(s) < i+2+len(name) || !strings.EqualFold(s[i+2:i+2+len(name)], name) {
+			continue
+		}
+		end := i + 2 + len(name)
+		if end == len(s) || isSpace(s[end]) || s[end] == '>' || s[end] == '/' {
+			return end + skipTo(s[end:], ">")
+		}
+	}
+	return len(s)
+}
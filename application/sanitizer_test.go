@@ -0,0 +1,84 @@
+package application
+
+import (
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	policy := DefaultSanitizePolicy()
+	policy.Elements["custom-el"] = []string{"data-x"}
+	policy.IframeHosts = []string{"www.youtube.com"}
+	policy.EmbedHosts = []string{"*.example.org"}
+	sanitizer := NewSanitizer(policy)
+
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		// scripts
+		{`<p>hello<script>alert(1)</script></p>`, `<p>hello</p>`},
+		{`<SCRIPT type="text/javascript">alert(1)</SCRIPT >after`, `after`},
+		{`<svg><script>alert(1)</script><text>t</text></svg>`, `t`},
+		{`<img src="x" onerror="alert(1)" />`, `<img src="x" />`},
+		{`<div style="color:red" class="note">x</div>`, `<div class="note">x</div>`},
+
+		// urls
+		{`<a href="javascript:alert(1)">x</a>`, `<a>x</a>`},
+		{`<a href="&#106;avascript:alert(1)">x</a>`, `<a>x</a>`},
+		{"<a href=\"java\tscript:alert(1)\">x</a>", `<a>x</a>`},
+		{`<a href="https://example.com/?a=1&amp;b=2" rel="nofollow">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow">x</a>`},
+		{`<a href="/entry/1#top">x</a>`, `<a href="/entry/1#top">x</a>`},
+
+		// iframes and embeds
+		{
+			`<iframe src="https://www.youtube.com/embed/x" width="560" onload="alert(1)" allowfullscreen></iframe>`,
+			`<iframe src="https://www.youtube.com/embed/x" width="560" allowfullscreen></iframe>`,
+		},
+		{`<iframe src="https://evil.example.com/">fallback</iframe>after`, `after`},
+		{`<iframe src="http://www.youtube.com/embed/x"></iframe>`, ``},
+		{`<iframe>fallback</iframe>`, ``},
+		{`<embed src="https://media.example.org/x.swf" />`, `<embed src="https://media.example.org/x.swf" />`},
+		{`<embed src="https://evil.example.com/x.swf">after`, `after`},
+		{`<object data="https://evil.example.com/x.swf"><p>fallback</p></object>after`, `after`},
+		{`<video controls src="/assets/x.mp4"></video>`, `<video controls src="/assets/x.mp4"></video>`},
+
+		// allowlist
+		{`<custom-el data-x="1" data-y="2">x</custom-el>`, `<custom-el data-x="1">x</custom-el>`},
+		{`<form action="/"><input name="q"></form>`, ``},
+		{`<abbr title="HyperText Markup Language">HTML</abbr>`, `<abbr title="HyperText Markup Language">HTML</abbr>`},
+
+		// syntax
+		{`<!-- comment --><p>x</p>`, `<p>x</p>`},
+		{`<!DOCTYPE html><p>x</p>`, `<p>x</p>`},
+		{`a < b <3`, `a &lt; b &lt;3`},
+		{`<p title='a"b'>x</p>`, `<p title="a&quot;b">x</p>`},
+		{`<p>x</p><img src="x"`, `<p>x</p>&lt;img src="x"`},
+		{`a <b c`, `a &lt;b c`},
+		{
+			`<pre><code class="language-go"><span class="hl-keyword">func</span> main()</code></pre>`,
+			`<pre><code class="language-go"><span class="hl-keyword">func</span> main()</code></pre>`,
+		},
+	}
+	for i, c := range cases {
+		got := sanitizer.Sanitize(c.input)
+		if got != c.expect {
+			t.Errorf("#%d: want %q, got %q", i, c.expect, got)
+		}
+		if again := sanitizer.Sanitize(got); again != got {
+			t.Errorf("#%d: want idempotent %q, got %q", i, got, again)
+		}
+	}
+}
+
+func TestSanitizedRenderer(t *testing.T) {
+	renderer := NewSanitizedRenderer(getRenderer(t), NewSanitizer(DefaultSanitizePolicy()))
+
+	element, err := NewEntryElement([]byte("# title<script>alert(1)</script>\n\ncontent\n\n<script>alert(1)</script>\n\nimage <img src=\"x\" onerror=\"alert(1)\">"), renderer)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectContent := "<p>content</p>\n\n\n\n<p>image <img src=\"x\"></p>"
+	if element.Title != "title" || element.Content != expectContent {
+		t.Errorf("want title %q and content %q, got title %q and content %q", "title", expectContent, element.Title, element.Content)
+	}
+}
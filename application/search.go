@@ -0,0 +1,69 @@
+package application
+
+import (
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// DefaultSearchLength is the number of the search hits in a page
+const DefaultSearchLength = 10
+
+// Search returns a page of the entries matched by the query and the filter
+// 'start' is the offset of the hits
+func (i *EntryInteractor) Search(query string, f repository.EntryFilter, start, n int) (*domain.SearchResult, error) {
+	if start < 0 {
+		return nil, errors.New("invalid start index")
+	}
+	if n < 1 {
+		n = DefaultSearchLength
+	}
+
+	hits, err := i.searchRepo.Search(query)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &domain.SearchResult{Hits: make([]*domain.SearchHit, 0)}
+	if len(hits) == 0 {
+		return res, nil
+	}
+
+	// the index does not know the status, filter the hits by the ids of the current entries
+	ids, err := i.entryRepo.GetIDs(f)
+	if err != nil {
+		return nil, err
+	}
+	matched := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		matched[id] = true
+	}
+	for _, h := range hits {
+		if !matched[h.ID] {
+			continue
+		}
+		if res.Total >= start && len(res.Hits) < n {
+			res.Hits = append(res.Hits, h)
+		}
+		res.Total++
+	}
+	return res, nil
+}
+
+// RebuildIndex indexes all the entries regardless of the status
+func (i *EntryInteractor) RebuildIndex() error {
+	ids, err := i.entryRepo.GetIDs(repository.EntryFilter{IncludePrivate: true, IncludeScheduled: true})
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		entry, err := i.entryRepo.Get(id)
+		if err != nil {
+			return err
+		}
+		if err := i.searchRepo.Index(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package application
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// countingEntryRepository counts the calls of Get
+type countingEntryRepository struct {
+	repository.EntryRepository
+	gets int
+}
+
+func (r *countingEntryRepository) Get(id int) (*domain.Entry, error) {
+	r.gets++
+	return r.EntryRepository.Get(id)
+}
+
+func TestSearchEntry(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+
+	entryRepo := &countingEntryRepository{EntryRepository: getEntryRepository(t)}
+	interactor := NewEntryInteractor(entryRepo, getSearchRepository(t), getAuditRepository(t))
+	ids := make([]int, 0)
+	for _, data := range []string{
+		"# first\n\nsearch engine",
+		"# second\n\nsearch engine, search",
+		"# [wip] draft\n\nsearch engine",
+		"# third\n\nother",
+	} {
+		element, err := NewEntryElement([]byte(data), getRenderer(t))
+		if err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+		id, err := interactor.Post(element)
+		if err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	// the index follows editing and deleting
+	element, _ := NewEntryElement([]byte("# third\n\nsearch engine"), getRenderer(t))
+	if err := interactor.Edit(ids[3], element); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := interactor.Delete(ids[0], 0); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		query       string
+		filter      repository.EntryFilter
+		start       int
+		length      int
+		expectTotal int
+		expectIDs   []int
+	}{
+		{"search engine", repository.EntryFilter{}, 0, 0, 2, []int{ids[1], ids[3]}},
+		{"search engine", authorized, 0, 0, 3, []int{ids[1], ids[3], ids[2]}},
+		{"search engine", authorized, 1, 1, 3, []int{ids[3]}},
+		{"search engine", authorized, 3, 1, 3, []int{}},
+		{"first", authorized, 0, 0, 0, []int{}},
+	}
+	for i, c := range cases {
+		entryRepo.gets = 0
+		res, err := interactor.Search(c.query, c.filter, c.start, c.length)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if entryRepo.gets != 0 {
+			t.Errorf("#%d: want no entry read by the hits, got %d", i, entryRepo.gets)
+		}
+		if res.Total != c.expectTotal {
+			t.Errorf("#%d: want total %d, got %d", i, c.expectTotal, res.Total)
+		}
+		actIDs := make([]int, 0)
+		for _, h := range res.Hits {
+			actIDs = append(actIDs, h.ID)
+		}
+		if !reflect.DeepEqual(actIDs, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, actIDs)
+		}
+	}
+}
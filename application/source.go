@@ -0,0 +1,263 @@
+package application
+
+import (
+	"bytes"
+	"database/sql"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// PushEvent represent the push payload of the git hosting services like GitHub and Gitea
+type PushEvent struct {
+	Ref     string        `json:"ref"`
+	After   string        `json:"after"`
+	Commits []*PushCommit `json:"commits"`
+}
+
+// PushCommit represent the changed files of a commit in the push
+type PushCommit struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []string `json:"modified"`
+}
+
+// changes returns the paths of the markdown files changed by the push
+// The value is whether the file exists after the push, the latest commit takes precedence
+func (e *PushEvent) changes() map[string]bool {
+	changes := make(map[string]bool)
+	for _, c := range e.Commits {
+		for _, p := range c.Added {
+			changes[p] = true
+		}
+		for _, p := range c.Modified {
+			changes[p] = true
+		}
+		for _, p := range c.Removed {
+			changes[p] = false
+		}
+	}
+	for p := range changes {
+		if !isMarkdownPath(p) {
+			delete(changes, p)
+		}
+	}
+	return changes
+}
+
+// deleted returns whether the push deletes the branch
+func (e *PushEvent) deleted() bool {
+	return strings.Trim(e.After, "0") == ""
+}
+
+func isMarkdownPath(p string) bool {
+	switch strings.ToLower(path.Ext(p)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// PushResult represent the number of the entries changed by the push
+// Errors is the mapping of the paths to the reasons of the files failed to apply
+type PushResult struct {
+	Created int               `json:"created"`
+	Edited  int               `json:"edited"`
+	Deleted int               `json:"deleted"`
+	Ignored bool              `json:"ignored"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+// fail records the reason of the file failed to apply
+func (r *PushResult) fail(p string, err error) {
+	if r.Errors == nil {
+		r.Errors = make(map[string]string)
+	}
+	r.Errors[p] = err.Error()
+}
+
+// isInvalidSource reports whether the error is caused by the content of the file
+func isInvalidSource(err error) bool {
+	switch errors.Cause(err) {
+	case config.ErrEmptyEntry,
+		config.ErrEntrySizeLimitExceeded,
+		config.ErrDuplicatedTitle,
+		config.ErrInvalidFrontMatter,
+		config.ErrDuplicatedSlug,
+		config.ErrEmptyPublishAt,
+		config.ErrInvalidAssetLink,
+		config.ErrEmptyAsset,
+		config.ErrAssetSizeLimitExceeded,
+		config.ErrUnsupportedAssetType:
+		return true
+	}
+	return false
+}
+
+// SourceInteractor applies the changes of the entry files in the source repository
+type SourceInteractor struct {
+	entry    *EntryInteractor
+	asset    *AssetInteractor
+	pathRepo repository.EntryPathRepository
+	source   repository.SourceRepository
+	renderer Renderer
+	branch   string
+}
+
+// NewSourceInteractor returns initialized SourceInteractor
+// Only the pushes to the branch are applied
+func NewSourceInteractor(e *EntryInteractor, a *AssetInteractor, p repository.EntryPathRepository, s repository.SourceRepository, r Renderer, branch string) *SourceInteractor {
+	return &SourceInteractor{
+		entry:    e,
+		asset:    a,
+		pathRepo: p,
+		source:   s,
+		renderer: r,
+		branch:   branch,
+	}
+}
+
+// ApplyPush creates, edits and deletes the entries by the markdown files changed by the push
+// The contents are read from the source repository at the pushed revision
+// The invalid files are recorded in the result and the others are still applied
+// Applying the same push again doesn't change the entries
+func (i *SourceInteractor) ApplyPush(e *PushEvent) (*PushResult, error) {
+	res := &PushResult{}
+	if e.Ref != "refs/heads/"+i.branch || e.deleted() {
+		res.Ignored = true
+		return res, nil
+	}
+
+	changes := e.changes()
+	paths := make([]string, 0, len(changes))
+	for p := range changes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		var err error
+		if changes[p] {
+			err = i.apply(res, e.After, p)
+		} else {
+			err = i.remove(res, p)
+		}
+		if isInvalidSource(err) {
+			res.fail(p, err)
+			continue
+		}
+		if err != nil {
+			return res, errors.Wrapf(err, "path: %s", p)
+		}
+	}
+	return res, nil
+}
+
+// apply creates or edits the entry of the file
+func (i *SourceInteractor) apply(res *PushResult, revision, p string) error {
+	data, err := i.source.ReadFile(revision, p)
+	if err == domain.ErrNotFoundSourceFile {
+		return i.remove(res, p)
+	}
+	if err != nil {
+		return err
+	}
+	assets, err := i.uploadAssets(revision, p, data)
+	if err != nil {
+		return err
+	}
+
+	current, err := i.lookup(p)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		element, err := NewEntryElementWithAssets(data, assets, i.renderer)
+		if err != nil {
+			return err
+		}
+		id, err := i.entry.Post(element)
+		if err != nil {
+			return err
+		}
+		res.Created++
+		return i.pathRepo.Save(p, id)
+	}
+
+	element, err := NewEntryElementWithAssets(data, domain.MergeAssetLinks(current.Assets, assets), i.renderer)
+	if err != nil {
+		return err
+	}
+	element.SetDefaultStatus(current.Status)
+	if len(element.Tags) == 0 {
+		element.Tags = current.Tags
+	}
+	if err := i.entry.Edit(current.ID, element); err != nil {
+		return err
+	}
+	res.Edited++
+	return nil
+}
+
+// lookup returns the entry mapped from the path, or nil when the entry doesn't exist
+func (i *SourceInteractor) lookup(p string) (*domain.Entry, error) {
+	id, err := i.pathRepo.GetEntryID(p)
+	if err == domain.ErrNotFoundEntryPath {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	entry, err := i.entry.Lookup(id)
+	if errors.Cause(err) == sql.ErrNoRows {
+		// deleted by the API
+		return nil, nil
+	}
+	return entry, err
+}
+
+// remove deletes the entry mapped from the path
+func (i *SourceInteractor) remove(res *PushResult, p string) error {
+	entry, err := i.lookup(p)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		// the pushed source is authoritative regardless of the version
+		if err := i.entry.Delete(entry.ID, 0); err != nil {
+			return err
+		}
+		res.Deleted++
+	}
+	return i.pathRepo.Delete(p)
+}
+
+// uploadAssets uploads the images linked from the file in the source repository
+// Returns the mapping of the links to the hashes of the assets, the missing files are skipped
+func (i *SourceInteractor) uploadAssets(revision, p string, data []byte) (map[string]string, error) {
+	assets := make(map[string]string)
+	for _, link := range domain.AssetLinks(data) {
+		key := domain.AssetLinkKey(link)
+		if !domain.IsNestedAssetLinkKey(key) {
+			continue
+		}
+		file := path.Join(path.Dir(p), key)
+		content, err := i.source.ReadFile(revision, file)
+		if err == domain.ErrNotFoundSourceFile {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		asset, err := i.asset.Upload(path.Base(file), bytes.NewReader(content))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to upload %s", file)
+		}
+		assets[key] = asset.Hash
+	}
+	return assets, nil
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/helper"
+	"github.com/takashabe/lumber/infrastructure/git"
+	"github.com/takashabe/lumber/infrastructure/memory"
+)
+
+func TestApplyPush(t *testing.T) {
+	loadFixture(t, "testdata/clean.sql")
+	fixture := helper.NewGitFixture(t)
+	defer fixture.Close()
+	source, err := git.NewRepository(fixture.Bare)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	png, err := ioutil.ReadFile("testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	entry := NewEntryInteractor(getEntryRepository(t), getSearchRepository(t), getAuditRepository(t))
+	pathRepo := memory.NewEntryPathRepository()
+	interactor := NewSourceInteractor(
+		entry,
+		NewAssetInteractor(memory.NewAssetRepository(), memory.NewAssetStorage(), 1<<20),
+		pathRepo,
+		source,
+		getRenderer(t),
+		"master",
+	)
+
+	cases := []struct {
+		ref          string
+		files        map[string]string
+		removed      []string
+		commits      []*PushCommit
+		expectResult *PushResult
+		expectTitles map[string]string
+	}{
+		{
+			"refs/heads/master",
+			map[string]string{
+				"a.md":            "a_title\n\n![image](images/a.png)",
+				"images/a.png":    string(png),
+				"posts/b.md":      "b_title\n\n![image](../images/a.png)",
+				"README.txt":      "not an entry",
+				"posts/unused.md": "unused_title\n\ncontent",
+			},
+			nil,
+			[]*PushCommit{
+				{Added: []string{"a.md", "images/a.png", "posts/b.md", "README.txt"}},
+				// added and removed in the same push
+				{Added: []string{"posts/unused.md"}},
+				{Removed: []string{"posts/unused.md"}},
+			},
+			&PushResult{Created: 2},
+			map[string]string{"a.md": "a_title", "posts/b.md": "b_title"},
+		},
+		{
+			"refs/heads/master",
+			map[string]string{"a.md": "a_title_2\n\n![image](images/a.png)"},
+			[]string{"posts/b.md"},
+			[]*PushCommit{{Modified: []string{"a.md"}, Removed: []string{"posts/b.md"}}},
+			&PushResult{Edited: 1, Deleted: 1},
+			map[string]string{"a.md": "a_title_2"},
+		},
+		{
+			"refs/heads/feature",
+			map[string]string{"c.md": "c_title\n\ncontent"},
+			nil,
+			[]*PushCommit{{Added: []string{"c.md"}}},
+			&PushResult{Ignored: true},
+			map[string]string{"a.md": "a_title_2"},
+		},
+		{
+			"refs/heads/master",
+			map[string]string{
+				"posts/d.md": "a_title_2\n\nduplicated",
+				"posts/e.md": "e_title\n\ncontent",
+			},
+			nil,
+			[]*PushCommit{{Added: []string{"posts/d.md", "posts/e.md"}}},
+			&PushResult{Created: 1, Errors: map[string]string{"posts/d.md": "title: a_title_2: duplicated the entry title"}},
+			map[string]string{"a.md": "a_title_2", "posts/e.md": "e_title"},
+		},
+	}
+	for i, c := range cases {
+		revision := fixture.Commit(c.files, c.removed...)
+		res, err := interactor.ApplyPush(&PushEvent{Ref: c.ref, After: revision, Commits: c.commits})
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(res, c.expectResult) {
+			t.Errorf("#%d: want result %#v, got %#v", i, c.expectResult, res)
+		}
+
+		for _, p := range []string{"a.md", "posts/b.md", "posts/unused.md", "c.md", "posts/d.md", "posts/e.md"} {
+			expect, ok := c.expectTitles[p]
+			id, err := pathRepo.GetEntryID(p)
+			if !ok {
+				if err != domain.ErrNotFoundEntryPath {
+					t.Errorf("#%d: want not found the entry of %s, got %#v", i, p, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("#%d: want non error, got %#v", i, err)
+			}
+			e, err := entry.Lookup(id)
+			if err != nil {
+				t.Fatalf("#%d: want non error, got %#v", i, err)
+			}
+			if e.Title != expect {
+				t.Errorf("#%d: want title %s of %s, got %s", i, expect, p, e.Title)
+			}
+			if p == "a.md" && !strings.Contains(e.Content, `<img src="/assets/`) {
+				t.Errorf("#%d: want the link to the asset, got %s", i, e.Content)
+			}
+			// the file outside of the directory of the entry is not uploaded
+			if p == "posts/b.md" && strings.Contains(e.Content, `<img src="/assets/`) {
+				t.Errorf("#%d: want the link outside of the directory, got %s", i, e.Content)
+			}
+		}
+	}
+}
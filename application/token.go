@@ -1,8 +1,13 @@
 package application
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
 	"github.com/pkg/errors"
-	"github.com/satori/go.uuid"
+	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/domain/repository"
 )
@@ -10,11 +15,15 @@ import (
 // TokenInteractor provides operation for tokens
 type TokenInteractor struct {
 	repository repository.TokenRepository
+	userRepo   repository.UserRepository
 }
 
 // NewTokenInteractor returns initialized token object
-func NewTokenInteractor(repo repository.TokenRepository) *TokenInteractor {
-	return &TokenInteractor{repository: repo}
+func NewTokenInteractor(t repository.TokenRepository, u repository.UserRepository) *TokenInteractor {
+	return &TokenInteractor{
+		repository: t,
+		userRepo:   u,
+	}
 }
 
 // Get returns object when matched id
@@ -22,36 +31,156 @@ func (i *TokenInteractor) Get(id int) (*domain.Token, error) {
 	return i.repository.Get(id)
 }
 
-// FindByValue returns object when matched value
-func (i *TokenInteractor) FindByValue(v string) (*domain.Token, error) {
-	return i.repository.FindByValue(v)
+// Find returns the token verified the value
+func (i *TokenInteractor) Find(value string) (*domain.Token, error) {
+	return findToken(i.repository, value)
 }
 
-// New returns a token with a new unique value
-func (i *TokenInteractor) New() (*domain.Token, error) {
-	var maxAttempt = 20
-	for a := 0; a < maxAttempt; a++ {
-		v := generateToken()
-		if _, err := i.FindByValue(v); err != nil {
-			if err == domain.ErrNotFoundToken {
-				token := &domain.Token{Value: v}
-				return i.save(token)
-			}
-			return nil, err
+// findToken returns the token found by the prefix and verified the value in constant time
+// Falls back to the plain value saved before hashing until migrated
+func findToken(repo repository.TokenRepository, value string) (*domain.Token, error) {
+	if len(value) == 0 {
+		return nil, domain.ErrNotFoundToken
+	}
+	tokens, err := repo.FindByPrefix(domain.TokenPrefix(value))
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if t.IsHashed() && t.Verify(value) {
+			return t, nil
 		}
 	}
-	return nil, errors.New("failed to attempt for create a new token")
+
+	t, err := repo.FindByPlainValue(value)
+	if err != nil {
+		return nil, err
+	}
+	// the hash must not be accepted as the value
+	if t.IsHashed() || !t.Verify(value) {
+		return nil, domain.ErrNotFoundToken
+	}
+	log.Printf("authenticated by the plain token. id: %d, run \"lumber token migrate\" to hash the tokens", t.ID)
+	return t, nil
+}
+
+// List returns all tokens
+// The values are not contained since only the hashes are saved
+func (i *TokenInteractor) List() ([]*domain.Token, error) {
+	return i.repository.List()
 }
 
-func (i *TokenInteractor) save(token *domain.Token) (*domain.Token, error) {
+// Create returns a token with a new unique value owned by the user
+// The token without the user is created when the userID is zero, it has the privileges of the admin
+// The token never expires when the expiresAt is nil
+func (i *TokenInteractor) Create(userID int, label string, expiresAt *time.Time) (*domain.Token, error) {
+	if len(label) > config.MaxTokenLabelBytes {
+		return nil, errors.Wrapf(config.ErrInvalidTokenLabel, "label: %s", label)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.Wrapf(config.ErrInvalidTokenExpiry, "expires_at: %v", expiresAt)
+	}
+	if userID != 0 {
+		if _, err := i.userRepo.Get(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	token := &domain.Token{
+		UserID:    userID,
+		Label:     label,
+		ExpiresAt: expiresAt,
+	}
+	if err := i.setNewValue(token); err != nil {
+		return nil, err
+	}
 	id, err := i.repository.Save(token)
 	if err != nil {
 		return nil, err
 	}
-	token.ID = id
-	return token, err
+	saved, err := i.repository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	saved.Value = token.Value
+	return saved, nil
 }
 
-func generateToken() string {
-	return uuid.NewV4().String()
+// Revoke deletes the token
+func (i *TokenInteractor) Revoke(id int) error {
+	ok, err := i.repository.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrNotFoundToken
+	}
+	return nil
+}
+
+// Rotate replaces the value of the token with a new unique value
+// The previous value is no longer authenticated
+func (i *TokenInteractor) Rotate(id int) (*domain.Token, error) {
+	token, err := i.repository.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.setNewValue(token); err != nil {
+		return nil, err
+	}
+	if err := i.repository.Update(token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Migrate replaces the plain values saved before hashing with the hashes
+// Returns the number of the migrated tokens
+func (i *TokenInteractor) Migrate() (int, error) {
+	tokens, err := i.repository.List()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, t := range tokens {
+		if t.IsHashed() {
+			continue
+		}
+		if err := t.SetValue(t.Hash); err != nil {
+			return n, err
+		}
+		if err := i.repository.Update(t); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// setNewValue sets a new value, the prefix is not used by the other tokens
+func (i *TokenInteractor) setNewValue(token *domain.Token) error {
+	var maxAttempt = 20
+	for a := 0; a < maxAttempt; a++ {
+		v, err := generateToken()
+		if err != nil {
+			return err
+		}
+		tokens, err := i.repository.FindByPrefix(domain.TokenPrefix(v))
+		if err != nil {
+			return err
+		}
+		if len(tokens) == 0 {
+			return token.SetValue(v)
+		}
+	}
+	return errors.New("failed to attempt for create a new token")
+}
+
+// generateToken returns a random value formatted "<prefix>.<secret>"
+func generateToken() (string, error) {
+	b := make([]byte, 6+32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate token")
+	}
+	return hex.EncodeToString(b[:6]) + "." + hex.EncodeToString(b[6:]), nil
 }
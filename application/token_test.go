@@ -2,18 +2,16 @@ package application
 
 import (
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
-	"github.com/takashabe/lumber/helper"
-	"github.com/takashabe/lumber/infrastructure/persistence"
 )
 
 func TestGetToken(t *testing.T) {
-	repo, err := persistence.NewTokenRepository()
-	if err != nil {
-		t.Fatalf("want non error, got %#v", err)
-	}
-	helper.LoadFixture(t, "testdata/tokens.yml")
+	repo := getTokenRepository(t)
+	loadFixture(t, "testdata/tokens.yml")
 	cases := []struct {
 		input     int
 		expectID  int
@@ -23,7 +21,7 @@ func TestGetToken(t *testing.T) {
 		{0, 0, domain.ErrNotFoundToken},
 	}
 	for i, c := range cases {
-		interactor := NewTokenInteractor(repo)
+		interactor := NewTokenInteractor(repo, userRepository)
 		token, err := interactor.Get(c.input)
 		if err != c.expectErr {
 			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
@@ -38,23 +36,137 @@ func TestGetToken(t *testing.T) {
 	}
 }
 
-func TestNewToken(t *testing.T) {
-	repo, err := persistence.NewTokenRepository()
+func TestCreateToken(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	cases := []struct {
+		userID    int
+		label     string
+		expiresAt *time.Time
+		expectErr error
+	}{
+		{0, "", nil, nil},
+		{2, "laptop", &future, nil},
+		{3, "laptop", nil, domain.ErrNotFoundUser},
+		{2, "laptop", &past, config.ErrInvalidTokenExpiry},
+		{2, string(make([]byte, config.MaxTokenLabelBytes+1)), nil, config.ErrInvalidTokenLabel},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		interactor := NewTokenInteractor(tokenRepository, userRepository)
+		token, err := interactor.Create(c.userID, c.label, c.expiresAt)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+
+		saved, err := interactor.Find(token.Value)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if saved.ID != token.ID || saved.UserID != c.userID || saved.Label != c.label || token.CreatedAt.IsZero() || !saved.IsHashed() {
+			t.Errorf("#%d: want %#v, got %#v", i, token, saved)
+		}
+		if (saved.ExpiresAt == nil) != (c.expiresAt == nil) {
+			t.Errorf("#%d: want expires_at %v, got %v", i, c.expiresAt, saved.ExpiresAt)
+		}
+	}
+}
+
+func TestListToken(t *testing.T) {
+	loadFixture(t, "testdata/tokens.yml")
+	interactor := NewTokenInteractor(tokenRepository, userRepository)
+
+	tokens, err := interactor.List()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(tokens) != 5 {
+		t.Fatalf("want 5 tokens, got %d", len(tokens))
+	}
+	for i, token := range tokens {
+		if token.ID != i+1 || len(token.Value) != 0 {
+			t.Errorf("#%d: want id %d without the value, got %#v", i, i+1, token)
+		}
+	}
+}
+
+func TestRotateAndRevokeToken(t *testing.T) {
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	interactor := NewTokenInteractor(tokenRepository, userRepository)
+	auth := NewAuthInteractor(tokenRepository, userRepository)
+
+	token, err := interactor.Rotate(3)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := auth.AuthenticateByToken("baz"); errors.Cause(err) != config.ErrInsufficientPrivileges {
+		t.Errorf("want error %#v for the previous value, got %#v", config.ErrInsufficientPrivileges, err)
+	}
+	user, err := auth.Authenticate(token.Value)
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
 	}
+	if user.ID != 2 {
+		t.Errorf("want user id 2, got %#v", user)
+	}
 
-	interactor := NewTokenInteractor(repo)
-	token, err := interactor.New()
+	if err := interactor.Revoke(3); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := auth.AuthenticateByToken(token.Value); errors.Cause(err) != config.ErrInsufficientPrivileges {
+		t.Errorf("want error %#v for the revoked token, got %#v", config.ErrInsufficientPrivileges, err)
+	}
+	if err := interactor.Revoke(3); err != domain.ErrNotFoundToken {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundToken, err)
+	}
+	if _, err := interactor.Rotate(3); err != domain.ErrNotFoundToken {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundToken, err)
+	}
+}
+
+func TestMigrateToken(t *testing.T) {
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	interactor := NewTokenInteractor(tokenRepository, userRepository)
+
+	created, err := interactor.Create(2, "", nil)
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
 	}
+	n, err := interactor.Migrate()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if n != 5 {
+		t.Errorf("want 5 migrated tokens, got %d", n)
+	}
 
-	after, err := interactor.Get(token.ID)
+	// the values before and after the migration are authenticated
+	for i, value := range []string{"foo", "baz", created.Value} {
+		if _, err := interactor.Find(value); err != nil {
+			t.Errorf("#%d: want non error, got %#v", i, err)
+		}
+	}
+	tokens, err := tokenRepository.List()
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
 	}
-	if token.ID != after.ID {
-		t.Errorf("want id %d, got %d", after.ID, token.ID)
+	for i, token := range tokens {
+		if !token.IsHashed() {
+			t.Errorf("#%d: want hashed token, got %#v", i, token)
+		}
+		// the hash must not be accepted as the value
+		if _, err := interactor.Find(token.Hash); err != domain.ErrNotFoundToken {
+			t.Errorf("#%d: want error %#v, got %#v", i, domain.ErrNotFoundToken, err)
+		}
+	}
+
+	if n, err := interactor.Migrate(); err != nil || n != 0 {
+		t.Errorf("want no more migrated tokens, got %d, %#v", n, err)
 	}
 }
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// UserInteractor provides operation for users
+type UserInteractor struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserInteractor returns initialized UserInteractor
+func NewUserInteractor(u repository.UserRepository) *UserInteractor {
+	return &UserInteractor{
+		userRepo: u,
+	}
+}
+
+// Get returns the user matched by the id
+func (i *UserInteractor) Get(id int) (*domain.User, error) {
+	return i.userRepo.Get(id)
+}
+
+// List returns all users
+func (i *UserInteractor) List() ([]*domain.User, error) {
+	return i.userRepo.List()
+}
+
+// Create creates a new user
+func (i *UserInteractor) Create(name string, role domain.Role) (*domain.User, error) {
+	u := &domain.User{
+		Name: strings.TrimSpace(name),
+		Role: role,
+	}
+	if err := validateUser(u); err != nil {
+		return nil, err
+	}
+	id, err := i.userRepo.Save(u)
+	if err != nil {
+		return nil, err
+	}
+	u.ID = id
+	return u, nil
+}
+
+// Update changes the name and the role of the user
+func (i *UserInteractor) Update(id int, name string, role domain.Role) (*domain.User, error) {
+	u, err := i.userRepo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	u.Name = strings.TrimSpace(name)
+	u.Role = role
+	if err := validateUser(u); err != nil {
+		return nil, err
+	}
+	return u, i.userRepo.Update(u)
+}
+
+// Delete deletes the user, the tokens of the user are no longer authenticated
+func (i *UserInteractor) Delete(id int) error {
+	ok, err := i.userRepo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrNotFoundUser
+	}
+	return nil
+}
+
+func validateUser(u *domain.User) error {
+	if len(u.Name) == 0 || len(u.Name) > config.MaxUserNameBytes {
+		return errors.Wrapf(config.ErrInvalidUserName, "name: %s", u.Name)
+	}
+	if !u.Role.IsValid() {
+		return errors.Wrapf(config.ErrInvalidUserRole, "role: %s", u.Role)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestCreateUser(t *testing.T) {
+	cases := []struct {
+		name      string
+		role      domain.Role
+		expectErr error
+	}{
+		{" carol ", domain.RoleAuthor, nil},
+		{"alice", domain.RoleAuthor, domain.ErrUserAlreadyExistSameName},
+		{" ", domain.RoleAuthor, config.ErrInvalidUserName},
+		{"carol", domain.Role("owner"), config.ErrInvalidUserRole},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		interactor := NewUserInteractor(userRepository)
+		user, err := interactor.Create(c.name, c.role)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		saved, err := interactor.Get(user.ID)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if saved.Name != "carol" || saved.Role != c.role {
+			t.Errorf("#%d: want %#v, got %#v", i, user, saved)
+		}
+	}
+}
+
+func TestUpdateUser(t *testing.T) {
+	cases := []struct {
+		id        int
+		name      string
+		role      domain.Role
+		expectErr error
+	}{
+		{2, "bob", domain.RoleEditor, nil},
+		{2, "alice", domain.RoleEditor, domain.ErrUserAlreadyExistSameName},
+		{2, "bob", domain.Role(""), config.ErrInvalidUserRole},
+		{3, "carol", domain.RoleEditor, domain.ErrNotFoundUser},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		interactor := NewUserInteractor(userRepository)
+		_, err := interactor.Update(c.id, c.name, c.role)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		saved, err := interactor.Get(c.id)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if saved.Name != c.name || saved.Role != c.role {
+			t.Errorf("#%d: want name %s and role %s, got %#v", i, c.name, c.role, saved)
+		}
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	interactor := NewUserInteractor(userRepository)
+	auth := NewAuthInteractor(tokenRepository, userRepository)
+
+	if err := interactor.Delete(2); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := interactor.Delete(2); err != domain.ErrNotFoundUser {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundUser, err)
+	}
+	// the tokens of the deleted user are no longer authenticated
+	if err := auth.AuthenticateByToken("baz"); errors.Cause(err) != config.ErrInsufficientPrivileges {
+		t.Errorf("want error %#v, got %#v", config.ErrInsufficientPrivileges, err)
+	}
+}
@@ -0,0 +1,153 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+)
+
+// Asset represent the uploaded file
+type Asset struct {
+	ID          int    `json:"id"`
+	Hash        string `json:"hash"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	URL         string `json:"url"`
+}
+
+// UploadAsset uploads the file and returns the asset
+// The server returns the existing asset when the same content has already been uploaded
+func (c *Client) UploadAsset(ctx context.Context, file string) (*Asset, error) {
+	if len(c.token) == 0 {
+		return nil, ErrRequireToken
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", filepath.Base(file))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%sapi/assets", c.addr), &buf)
+	if err != nil {
+		return nil, err
+	}
+	setToken(req, c.token)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	err = verifyHTTPStatusCode(res, http.StatusCreated)
+	if err != nil {
+		b, _ := ioutil.ReadAll(res.Body)
+		return nil, errors.Wrapf(err, "file: %s, response: %s", file, b)
+	}
+
+	asset := &Asset{}
+	err = json.NewDecoder(res.Body).Decode(asset)
+	return asset, err
+}
+
+// FindAsset returns the uploaded asset matched by the hash of the content
+// Returns ErrNotFoundAsset when the content has not been uploaded yet
+func (c *Client) FindAsset(ctx context.Context, hash string) (*Asset, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%sapi/assets/%s", c.addr, hash), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFoundAsset
+	}
+	if err := verifyHTTPStatusCode(res, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	asset := &Asset{}
+	err = json.NewDecoder(res.Body).Decode(asset)
+	return asset, err
+}
+
+// uploadLinkedAssets uploads the local images linked from the markdown file
+// Returns the mapping of the links to the hashes of the assets
+// The missing files are skipped, since the server keeps the assets of the links posted before
+// The files outside of the directory of the markdown are never uploaded
+func (c *Client) uploadLinkedAssets(ctx context.Context, file string, data []byte) (map[string]string, error) {
+	assets := make(map[string]string)
+	for _, link := range domain.AssetLinks(data) {
+		key := domain.AssetLinkKey(link)
+		if !domain.IsNestedAssetLinkKey(key) {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(file), filepath.FromSlash(key))
+		hash, err := fileHash(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the linked file %s", link)
+		}
+
+		_, err = c.FindAsset(ctx, hash)
+		if err == nil {
+			assets[key] = hash
+			continue
+		}
+		if err != ErrNotFoundAsset {
+			return nil, err
+		}
+		asset, err := c.UploadAsset(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		assets[key] = asset.Hash
+	}
+	return assets, nil
+}
+
+// fileHash returns the hex encoded sha256 of the file content as well as the server
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
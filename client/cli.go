@@ -8,6 +8,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -35,6 +37,27 @@ type param struct {
 	dir   string
 	id    int
 	token string
+	tags  string
+
+	// for the optimistic concurrency control of edit and revert
+	version int
+	force   bool
+
+	// for revisions
+	from     int
+	to       int
+	revision int
+
+	// for sync
+	manifest string
+	dryRun   bool
+}
+
+func (p *param) tagList() []string {
+	if len(p.tags) == 0 {
+		return nil
+	}
+	return strings.Split(p.tags, ",")
 }
 
 // CLI is the command line interface object
@@ -85,10 +108,18 @@ func (c *CLI) parseArgs(args []string, p *param) error {
 	flags.SetOutput(c.ErrStream)
 
 	flags.StringVar(&p.addr, "addr", defaultAddr, "Lumber server address.")
-	flags.StringVar(&p.file, "file", "", "Post or Edit an entry file")
+	flags.StringVar(&p.file, "file", "", "Post or Edit an entry file, Upload a file, or the destination of Pull")
 	flags.StringVar(&p.dir, "dir", "", "Post an entries in the directory")
 	flags.IntVar(&p.id, "id", 0, "Specific ID of an entry")
 	flags.StringVar(&p.token, "token", "", "Server token")
+	flags.StringVar(&p.tags, "tags", "", "Comma separated tags of the entry")
+	flags.IntVar(&p.version, "version", 0, "Specific version of the entry to edit or revert. Default of edit is the version which the file was pulled, posted or edited last")
+	flags.BoolVar(&p.force, "force", false, "Edit or sync the entry even if it has been changed on the server")
+	flags.IntVar(&p.from, "from", 0, "Specific revision ID of the diff source")
+	flags.IntVar(&p.to, "to", 0, "Specific revision ID of the diff destination")
+	flags.IntVar(&p.revision, "rev", 0, "Specific revision ID to revert")
+	flags.StringVar(&p.manifest, "manifest", "", "Manifest file of the sync. Default is "+ManifestFile+" in the directory")
+	flags.BoolVar(&p.dryRun, "dry-run", false, "Show the operations of the sync without applying")
 
 	err := flags.Parse(args)
 	if err != nil {
@@ -120,14 +151,47 @@ func (c *CLI) commands() []command {
 			"edit the entry",
 			c.doEditEntry,
 		},
+		{
+			"pull",
+			"write the source markdown of the entry to the file or stdout",
+			c.doPullEntry,
+		},
+		{
+			"history",
+			"show revisions of the entry",
+			c.doShowHistory,
+		},
+		{
+			"diff",
+			"show diff between revisions of the entry",
+			c.doShowDiff,
+		},
+		{
+			"revert",
+			"revert the entry to the revision",
+			c.doRevertEntry,
+		},
+		{
+			"sync",
+			"create, edit and delete the entries by the changes of the files in the directory",
+			c.doSyncEntries,
+		},
+		{
+			"upload",
+			"upload the image or file and show the URL",
+			c.doUploadAsset,
+		},
 	}
 }
 
 func (c *CLI) doPostEntry(ctx context.Context, p *param) error {
-	id, err := c.client.CreateEntry(ctx, p.file)
+	id, err := c.client.CreateEntry(ctx, p.file, p.tagList()...)
 	if err != nil {
 		return errors.Wrap(err, "failed post entry")
 	}
+	if err := c.saveVersion(p.file, id, firstVersion); err != nil {
+		return err
+	}
 	fmt.Fprintf(c.OutStream, "succeed post entry. id=%d\n", id)
 	return nil
 }
@@ -149,27 +213,195 @@ func (c *CLI) postEntryRecursiveDir(ctx context.Context, p *param) error {
 		return errors.Wrapf(err, "failed to read directory")
 	}
 
-	ids := []int{}
 	for _, f := range fs {
+		path := filepath.Join(p.dir, f.Name())
 		if f.IsDir() {
-			c.postEntryRecursiveDir(ctx, p)
+			sub := *p
+			sub.dir = path
+			if err := c.postEntryRecursiveDir(ctx, &sub); err != nil {
+				return err
+			}
+			continue
+		}
+		// skip the images linked from the entries
+		if !isMarkdownFile(f.Name()) {
+			continue
 		}
 
-		path := filepath.Join(p.dir, f.Name())
-		id, err := c.client.CreateEntry(ctx, path)
+		id, err := c.client.CreateEntry(ctx, path, p.tagList()...)
 		if err != nil {
 			return err
 		}
-		ids = append(ids, id)
+		if err := c.saveVersion(path, id, firstVersion); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+func isMarkdownFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
 func (c *CLI) doEditEntry(ctx context.Context, p *param) error {
 	e := c.client.Entry(p.id)
-	err := e.Edit(ctx, p.file)
+	version := p.version
+	if !p.force {
+		if version == 0 {
+			// the version which the file was pulled, posted or edited last
+			v, err := LoadVersion(p.file, p.id)
+			if err != nil {
+				return errors.Wrap(err, "failed to edit an entry")
+			}
+			if v == 0 {
+				return errors.Errorf("failed to edit an entry: the version of %s is unknown, pull the entry %d, or retry with -version or -force", p.file, p.id)
+			}
+			version = v
+		}
+		e = e.IfMatch(version)
+	}
+
+	edited, err := e.Edit(ctx, p.file, p.tagList()...)
+	if errors.Cause(err) == ErrEntryConflict {
+		return errors.Errorf("failed to edit an entry: the entry %d has been changed on the server since the version %d, pull the latest source or retry with -force", p.id, version)
+	}
 	if err != nil {
 		return errors.Wrap(err, "failed to edit an entry")
 	}
+	return c.saveVersion(p.file, p.id, edited)
+}
+
+func (c *CLI) doPullEntry(ctx context.Context, p *param) error {
+	e := c.client.Entry(p.id)
+	// read the version before the source, the edit based on the older version is detected as the conflict
+	current, err := e.Get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get an entry")
+	}
+	src, err := e.Source(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the source of an entry")
+	}
+	if len(p.file) == 0 {
+		_, err = c.OutStream.Write(src)
+		return err
+	}
+	if err := ioutil.WriteFile(p.file, src, 0644); err != nil {
+		return err
+	}
+	if err := c.saveVersion(p.file, p.id, current.Version); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.OutStream, "succeed pull entry. id=%d, version=%d\n", p.id, current.Version)
+	return nil
+}
+
+// saveVersion records the version of the entry which the file is based on, edit sends it by default
+// Skips the servers which don't respond the version
+func (c *CLI) saveVersion(file string, id, version int) error {
+	if id == 0 || version == 0 {
+		return nil
+	}
+	if err := SaveVersion(file, id, version); err != nil {
+		return errors.Wrap(err, "failed to record the version")
+	}
+	return nil
+}
+
+func (c *CLI) doShowHistory(ctx context.Context, p *param) error {
+	revs, err := c.client.Entry(p.id).Revisions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get revisions")
+	}
+	for _, r := range revs {
+		fmt.Fprintf(c.OutStream, "%d\t%s\t%s\n", r.ID, r.CreatedAt.Format(time.RFC3339), r.Title)
+	}
+	return nil
+}
+
+func (c *CLI) doShowDiff(ctx context.Context, p *param) error {
+	diff, err := c.client.Entry(p.id).Diff(ctx, p.from, p.to)
+	if err != nil {
+		return errors.Wrap(err, "failed to get diff")
+	}
+	fmt.Fprint(c.OutStream, diff)
+	return nil
+}
+
+func (c *CLI) doRevertEntry(ctx context.Context, p *param) error {
+	e := c.client.Entry(p.id)
+	if p.version != 0 {
+		e = e.IfMatch(p.version)
+	}
+	err := e.Revert(ctx, p.revision)
+	if errors.Cause(err) == ErrEntryConflict {
+		return errors.Errorf("failed to revert an entry: the entry %d has been changed on the server since the version %d", p.id, p.version)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to revert an entry")
+	}
+	fmt.Fprintf(c.OutStream, "succeed revert entry. id=%d, revision=%d\n", p.id, p.revision)
+	return nil
+}
+
+func (c *CLI) doUploadAsset(ctx context.Context, p *param) error {
+	asset, err := c.client.UploadAsset(ctx, p.file)
+	if err != nil {
+		return errors.Wrap(err, "failed to upload the file")
+	}
+	fmt.Fprintf(c.OutStream, "succeed upload the file. url=%s\n", asset.URL)
+	return nil
+}
+
+func (c *CLI) doSyncEntries(ctx context.Context, p *param) error {
+	if len(p.dir) == 0 {
+		return errors.New("invalid args: require the directory")
+	}
+	manifestFile := p.manifest
+	if len(manifestFile) == 0 {
+		manifestFile = filepath.Join(p.dir, ManifestFile)
+	}
+	manifest, err := LoadManifest(manifestFile)
+	if err != nil {
+		return err
+	}
+	actions, err := PlanSync(p.dir, manifest)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the directory")
+	}
+	if p.dryRun {
+		for _, a := range actions {
+			fmt.Fprintln(c.OutStream, a)
+		}
+		return nil
+	}
+
+	if p.force {
+		for _, a := range actions {
+			a.Version = 0
+		}
+	}
+
+	err = c.client.Sync(ctx, p.dir, manifest, actions, p.tagList()...)
+	// record the applied actions even if failed on the way
+	if saveErr := manifest.Save(manifestFile); saveErr != nil && err == nil {
+		err = errors.Wrap(saveErr, "failed to save the manifest")
+	}
+	if errors.Cause(err) == ErrEntryConflict {
+		return errors.Errorf("%v, pull the latest source or retry with -force", err)
+	}
+	if err != nil {
+		return err
+	}
+	counts := make(map[SyncOp]int)
+	for _, a := range actions {
+		counts[a.Op]++
+	}
+	fmt.Fprintf(c.OutStream, "succeed sync entries. created=%d, edited=%d, deleted=%d\n",
+		counts[SyncCreate], counts[SyncEdit], counts[SyncDelete])
 	return nil
 }
@@ -46,7 +46,9 @@ func New() (*Client, error) {
 }
 
 // CreateEntry submit markdown file as a new entry
-func (c *Client) CreateEntry(ctx context.Context, file string) (int, error) {
+// The tags take precedence over the tags in the front matter
+// The local images linked from the file are uploaded as the assets
+func (c *Client) CreateEntry(ctx context.Context, file string, tags ...string) (int, error) {
 	if len(c.token) == 0 {
 		return 0, ErrRequireToken
 	}
@@ -55,14 +57,22 @@ func (c *Client) CreateEntry(ctx context.Context, file string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
+	assets, err := c.uploadLinkedAssets(ctx, file, f)
+	if err != nil {
+		return 0, err
+	}
 
 	type payload struct {
-		Data   []byte `json:"data"`
-		Status int    `json:"status"`
+		Data   []byte            `json:"data"`
+		Status int               `json:"status"`
+		Tags   []string          `json:"tags,omitempty"`
+		Assets map[string]string `json:"assets,omitempty"`
 	}
 	raw := payload{
 		Data:   f,
 		Status: 1, // TODO: changeable status
+		Tags:   tags,
+		Assets: assets,
 	}
 	var buf bytes.Buffer
 	err = json.NewEncoder(&buf).Encode(raw)
@@ -70,10 +80,11 @@ func (c *Client) CreateEntry(ctx context.Context, file string) (int, error) {
 		return 0, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%sapi/entry?token=%s", c.addr, c.token), &buf)
+	req, err := http.NewRequest("POST", fmt.Sprintf("%sapi/entry", c.addr), &buf)
 	if err != nil {
 		return 0, err
 	}
+	setToken(req, c.token)
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return 0, err
@@ -104,9 +115,17 @@ func (c *Client) CreateEntry(ctx context.Context, file string) (int, error) {
 // Entry returns initialized Entry
 func (c *Client) Entry(id int) *Entry {
 	return &Entry{
-		id:    id,
-		addr:  c.addr,
-		token: c.token,
+		id:     id,
+		addr:   c.addr,
+		token:  c.token,
+		client: c,
+	}
+}
+
+// setToken sets the token to the Authorization header of the request unless empty
+func setToken(req *http.Request, token string) {
+	if len(token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 }
 
@@ -1,25 +1,29 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io/ioutil"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
-	"github.com/takashabe/lumber/helper"
-	"github.com/takashabe/lumber/infrastructure/persistence"
+	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/interfaces"
 )
 
 func setupServer(t *testing.T) *httptest.Server {
-	er, err := persistence.NewEntryRepository()
-	tr, err := persistence.NewTokenRepository()
-	if err != nil {
-		t.Fatalf("want non error, got %#v", err)
-	}
+	loadFixture(t, "fixture/tokens.yml")
 	server := &interfaces.Server{
-		Entry: interfaces.NewEntryHandler(er, tr),
-		Token: interfaces.NewTokenHandler(tr),
+		Auth:  interfaces.NewAuthMiddleware(tokenRepository, userRepository, false),
+		Entry: interfaces.NewEntryHandler(entryRepository, searchRepository, auditRepository, renderer),
+		Token: interfaces.NewTokenHandler(tokenRepository, userRepository),
+		Asset: interfaces.NewAssetHandler(assetRepository, assetStorage, 1<<20),
 	}
 	ts := httptest.NewServer(server.Routes())
 	os.Setenv(LumberServerAddress, ts.URL)
@@ -29,7 +33,7 @@ func setupServer(t *testing.T) *httptest.Server {
 func TestCreateAndGetEntry(t *testing.T) {
 	ts := setupServer(t)
 	defer ts.Close()
-	helper.InitializeTable()
+	loadFixture(t, "fixture/truncate_entries.sql")
 
 	cases := []struct {
 		input         string
@@ -63,13 +67,25 @@ func TestCreateAndGetEntry(t *testing.T) {
 			t.Errorf("#%d: want title %s and content %s, got %s and %s",
 				i, c.expectTitle, c.expectContent, entry.Title, entry.Content)
 		}
+
+		expectSource, err := ioutil.ReadFile(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		source, err := client.Entry(id).Source(ctx)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(source, expectSource) {
+			t.Errorf("#%d: want source %q, got %q", i, expectSource, source)
+		}
 	}
 }
 
 func TestCreateDuplicateEntry(t *testing.T) {
 	ts := setupServer(t)
 	defer ts.Close()
-	helper.InitializeTable()
+	loadFixture(t, "fixture/truncate_entries.sql")
 
 	cases := []struct {
 		input string
@@ -114,14 +130,14 @@ func TestEditEntry(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "fixture/entries.yml")
+		loadFixture(t, "fixture/entries.yml")
 		ctx := context.Background()
 		client, err := New()
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
 		entryClient := client.Entry(c.inputID)
-		err = entryClient.Edit(ctx, c.inputFile)
+		_, err = entryClient.Edit(ctx, c.inputFile)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
@@ -148,7 +164,7 @@ func TestDeleteEntry(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "fixture/entries.yml")
+		loadFixture(t, "fixture/entries.yml")
 		ctx := context.Background()
 		client, err := New()
 		if err != nil {
@@ -160,3 +176,276 @@ func TestDeleteEntry(t *testing.T) {
 		}
 	}
 }
+
+func TestEditEntryConflict(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/entries.yml")
+
+	ctx := context.Background()
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := client.Entry(1).IfMatch(1).Edit(ctx, "testdata/minimum.md"); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// the version has been changed by the previous edit
+	if _, err := client.Entry(1).IfMatch(1).Edit(ctx, "testdata/minimum2.md"); errors.Cause(err) != ErrEntryConflict {
+		t.Errorf("want error %#v, got %#v", ErrEntryConflict, err)
+	}
+	if err := client.Entry(1).IfMatch(1).Delete(ctx); errors.Cause(err) != ErrEntryConflict {
+		t.Errorf("want error %#v, got %#v", ErrEntryConflict, err)
+	}
+
+	dir, err := ioutil.TempDir("", "lumber-client")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "entry.md")
+
+	cases := []struct {
+		command    string
+		args       []string
+		expectCode int
+	}{
+		// the version of the file is unknown before pulling
+		{"edit", []string{}, ExitCodeApplyCommandError},
+		{"pull", []string{}, ExitCodeOK},
+		// edits the pulled version by default, and records the edited version
+		{"edit", []string{}, ExitCodeOK},
+		{"edit", []string{}, ExitCodeOK},
+		{"edit", []string{"-version", "1"}, ExitCodeApplyCommandError},
+		{"edit", []string{"-version", "1", "-force"}, ExitCodeOK},
+	}
+	for i, c := range cases {
+		var errOut bytes.Buffer
+		cli := &CLI{OutStream: ioutil.Discard, ErrStream: &errOut}
+		args := append([]string{"client", c.command, "-id", "1", "-file", file, "-addr", ts.URL}, c.args...)
+		if code := cli.Run(args); code != c.expectCode {
+			t.Errorf("#%d: want exit code %d, got %d: %s", i, c.expectCode, code, errOut.String())
+		}
+		if c.expectCode != ExitCodeOK && !strings.Contains(errOut.String(), "-force") {
+			t.Errorf("#%d: want the error suggesting -force, got %s", i, errOut.String())
+		}
+	}
+
+	// the stale file doesn't overwrite the entry edited by the other
+	if _, err := client.Entry(1).Edit(ctx, "testdata/minimum.md"); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	var errOut bytes.Buffer
+	cli := &CLI{OutStream: ioutil.Discard, ErrStream: &errOut}
+	if code := cli.Run([]string{"client", "edit", "-id", "1", "-file", file, "-addr", ts.URL}); code != ExitCodeApplyCommandError {
+		t.Errorf("want exit code %d, got %d: %s", ExitCodeApplyCommandError, code, errOut.String())
+	}
+}
+
+func TestRevertEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/entries.yml")
+
+	ctx := context.Background()
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entryClient := client.Entry(1)
+	_, err = entryClient.Edit(ctx, "testdata/minimum.md")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	revs, err := entryClient.Revisions(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(revs) != 2 {
+		t.Fatalf("want revisions %d, got %d", 2, len(revs))
+	}
+	diff, err := entryClient.Diff(ctx, revs[0].ID, revs[1].ID)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(diff) == 0 {
+		t.Errorf("want non empty diff")
+	}
+
+	err = entryClient.Revert(ctx, revs[0].ID)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := entryClient.Get(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if entry.Title != "foo" || entry.Content != "bar" {
+		t.Errorf("want title foo and content bar, got %s and %s", entry.Title, entry.Content)
+	}
+}
+
+func TestCreateEntryWithTags(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/truncate_entries.sql")
+
+	ctx := context.Background()
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	id, err := client.CreateEntry(ctx, "testdata/minimum.md", "go", "blog")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := client.Entry(id).Get(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := []string{"blog", "go"}; !reflect.DeepEqual(entry.Tags, expect) {
+		t.Errorf("want tags %v, got %v", expect, entry.Tags)
+	}
+}
+
+func TestUploadAsset(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	ctx := context.Background()
+	first, err := client.UploadAsset(ctx, "testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if first.Name != "image.png" || first.URL != "/assets/"+first.Hash {
+		t.Errorf("want name image.png and url of the hash, got %#v", first)
+	}
+	second, err := client.UploadAsset(ctx, "testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("want deduplicated id %d, got %d", first.ID, second.ID)
+	}
+
+	if _, err := client.UploadAsset(ctx, "testdata/minimum.md"); err == nil {
+		t.Errorf("want error for the unsupported file, got nil")
+	}
+}
+
+func TestCreateEntryWithLinkedAssets(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/truncate_entries.sql")
+
+	ctx := context.Background()
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	hash, err := fileHash("testdata/linked/images/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectContent := fmt.Sprintf(`<p><img src="/assets/%s" alt="image" /></p>`, hash)
+
+	id, err := client.CreateEntry(ctx, "testdata/linked/entry.md")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := client.Entry(id).Get(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if entry.Content != expectContent {
+		t.Errorf("want content %q, got %q", expectContent, entry.Content)
+	}
+	source, err := client.Entry(id).Source(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if !strings.Contains(string(source), "![image](./images/image.png)") {
+		t.Errorf("want the source keeps the local link, got %q", source)
+	}
+
+	// edit the pulled source without the images, the server keeps the assets of the links
+	dir, err := ioutil.TempDir("", "lumber-client")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "entry.md")
+	if err := ioutil.WriteFile(file, append(source, []byte("\nedited\n")...), 0644); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := client.Entry(id).Edit(ctx, file); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err = client.Entry(id).Get(ctx)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := expectContent + "\n\n<p>edited</p>"; entry.Content != expect {
+		t.Errorf("want content %q, got %q", expect, entry.Content)
+	}
+}
+
+func TestLinkedAssetsOutsideDirectory(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "lumber-client")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	image, err := ioutil.ReadFile("testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "secret.png"), image, 0644); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "posts"), 0755); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	secret := filepath.Join(dir, "secret.png")
+	data := []byte(fmt.Sprintf("# title\n\n![a](../secret.png) ![b](%s)", url.PathEscape(secret)))
+	file := filepath.Join(dir, "posts", "entry.md")
+	if err := ioutil.WriteFile(file, data, 0644); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	assets, err := client.uploadLinkedAssets(context.Background(), file, data)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(assets) != 0 {
+		t.Errorf("want no uploaded assets, got %v", assets)
+	}
+
+	// the hash of the entry doesn't depend on the files outside
+	before, err := entryHash(file)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := os.Remove(secret); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	after, err := entryHash(file)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if before != after {
+		t.Errorf("want the same hash %s, got %s", before, after)
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // Entry provide operations associated with the entry
@@ -16,6 +19,11 @@ type Entry struct {
 	id    int
 	addr  string
 	token string
+	// version is required by Edit and Delete unless zero
+	version int
+
+	// client uploads the assets linked from the entry
+	client *Client
 }
 
 // EntryContent represent fields of the already published entry
@@ -23,16 +31,20 @@ type EntryContent struct {
 	ID        int       `json:"id"`
 	Title     string    `json:"title"`
 	Content   string    `json:"content"`
+	Tags      []string  `json:"tags"`
+	Version   int       `json:"version"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // Get returns existed EntryContent
+// Private entries are returned only when the token is specified
 func (e *Entry) Get(ctx context.Context) (*EntryContent, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf("%sapi/entry/%d", e.addr, e.id), nil)
 	if err != nil {
 		return nil, err
 	}
+	setToken(req, e.token)
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -48,39 +60,111 @@ func (e *Entry) Get(ctx context.Context) (*EntryContent, error) {
 	return buf, err
 }
 
-// Edit submit makrdown file as an entry
-func (e *Entry) Edit(ctx context.Context, file string) error {
+// IfMatch returns the copy of the Entry which edits, deletes and reverts only the version of the entry
+// Edit, Delete and Revert fail with ErrEntryConflict when the entry has been changed since the version
+func (e *Entry) IfMatch(version int) *Entry {
+	c := *e
+	c.version = version
+	return &c
+}
+
+// setIfMatch sets the version required by the Entry to the If-Match header unless zero
+func (e *Entry) setIfMatch(req *http.Request) {
+	if e.version != 0 {
+		req.Header.Set("If-Match", strconv.Quote(strconv.Itoa(e.version)))
+	}
+}
+
+// verifyEntryResponse returns ErrEntryConflict when the version required by the Entry doesn't match
+func (e *Entry) verifyEntryResponse(res *http.Response) error {
+	if res.StatusCode == http.StatusPreconditionFailed {
+		return errors.Wrapf(ErrEntryConflict, "id: %d, version: %d, current: %s", e.id, e.version, res.Header.Get("ETag"))
+	}
+	return verifyHTTPStatusCode(res, http.StatusOK)
+}
+
+// Source returns the markdown which the entry was submitted
+// Private entries are returned only when the token is specified
+func (e *Entry) Source(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%sapi/entry/%d", e.addr, e.id), nil)
+	if err != nil {
+		return nil, err
+	}
+	setToken(req, e.token)
+	req.Header.Set("Accept", "text/markdown")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	err = verifyHTTPStatusCode(res, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+// Edit submit makrdown file as an entry, and returns the new version of the entry
+// The tags take precedence over the tags in the front matter
+// The local images linked from the file are uploaded as the assets
+func (e *Entry) Edit(ctx context.Context, file string, tags ...string) (int, error) {
 	if len(e.token) == 0 {
-		return ErrRequireToken
+		return 0, ErrRequireToken
 	}
 
 	f, err := ioutil.ReadFile(file)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	assets, err := e.client.uploadLinkedAssets(ctx, file, f)
+	if err != nil {
+		return 0, err
 	}
 
 	type payload struct {
-		Data []byte `json:"data"`
+		Data   []byte            `json:"data"`
+		Tags   []string          `json:"tags,omitempty"`
+		Assets map[string]string `json:"assets,omitempty"`
 	}
 	raw := payload{
-		Data: f,
+		Data:   f,
+		Tags:   tags,
+		Assets: assets,
 	}
 	var buf bytes.Buffer
 	err = json.NewEncoder(&buf).Encode(raw)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%sapi/entry/%d?token=%s", e.addr, e.id, e.token), &buf)
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%sapi/entry/%d", e.addr, e.id), &buf)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	setToken(req, e.token)
+	e.setIfMatch(req)
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer res.Body.Close()
-	return verifyHTTPStatusCode(res, http.StatusOK)
+	if err := e.verifyEntryResponse(res); err != nil {
+		return 0, err
+	}
+	return parseETag(res.Header.Get("ETag")), nil
+}
+
+// parseETag returns the version of the ETag, zero when the server doesn't respond it
+func parseETag(tag string) int {
+	s, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return v
 }
 
 // Delete submit makrdown file as an entry
@@ -89,14 +173,101 @@ func (e *Entry) Delete(ctx context.Context) error {
 		return ErrRequireToken
 	}
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%sapi/entry/%d?token=%s", e.addr, e.id, e.token), nil)
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%sapi/entry/%d", e.addr, e.id), nil)
 	if err != nil {
 		return err
 	}
+	setToken(req, e.token)
+	e.setIfMatch(req)
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer res.Body.Close()
-	return verifyHTTPStatusCode(res, http.StatusOK)
+	return e.verifyEntryResponse(res)
+}
+
+// Revision represent a snapshot of the entry
+type Revision struct {
+	ID        int       `json:"id"`
+	EntryID   int       `json:"entry_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Revisions returns revision list of the entry
+func (e *Entry) Revisions(ctx context.Context) ([]*Revision, error) {
+	if len(e.token) == 0 {
+		return nil, ErrRequireToken
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%sapi/entry/%d/revisions", e.addr, e.id), nil)
+	if err != nil {
+		return nil, err
+	}
+	setToken(req, e.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	err = verifyHTTPStatusCode(res, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := struct {
+		Data []*Revision `json:"data"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&buf)
+	return buf.Data, err
+}
+
+// Diff returns the unified diff between two revisions of the entry
+func (e *Entry) Diff(ctx context.Context, from, to int) (string, error) {
+	if len(e.token) == 0 {
+		return "", ErrRequireToken
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%sapi/entry/%d/diff/%d/%d", e.addr, e.id, from, to), nil)
+	if err != nil {
+		return "", err
+	}
+	setToken(req, e.token)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	err = verifyHTTPStatusCode(res, http.StatusOK)
+	if err != nil {
+		return "", err
+	}
+
+	buf := struct {
+		Diff string `json:"diff"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&buf)
+	return buf.Diff, err
+}
+
+// Revert rolls back the entry to the revision
+func (e *Entry) Revert(ctx context.Context, revision int) error {
+	if len(e.token) == 0 {
+		return ErrRequireToken
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%sapi/entry/%d/revert/%d", e.addr, e.id, revision), nil)
+	if err != nil {
+		return err
+	}
+	setToken(req, e.token)
+	e.setIfMatch(req)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return e.verifyEntryResponse(res)
 }
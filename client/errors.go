@@ -4,5 +4,7 @@ import "github.com/pkg/errors"
 
 // errors
 var (
-	ErrRequireToken = errors.New("require a token")
+	ErrRequireToken  = errors.New("require a token")
+	ErrNotFoundAsset = errors.New("not found the asset")
+	ErrEntryConflict = errors.New("the entry has been changed on the server")
 )
@@ -4,7 +4,22 @@ import (
 	"os"
 	"testing"
 
+	"github.com/takashabe/lumber/application"
 	"github.com/takashabe/lumber/helper"
+	"github.com/takashabe/lumber/infrastructure/memory"
+	"github.com/takashabe/lumber/infrastructure/search"
+)
+
+var (
+	entryRepository  = memory.NewEntryRepository()
+	tokenRepository  = memory.NewTokenRepository()
+	userRepository   = memory.NewUserRepository()
+	searchRepository = search.NewSearchRepository()
+	assetRepository  = memory.NewAssetRepository()
+	assetStorage     = memory.NewAssetStorage()
+	auditRepository  = memory.NewAuditRepository()
+
+	renderer, _ = application.NewRenderer(application.ExtensionTables)
 )
 
 func TestMain(m *testing.M) {
@@ -13,6 +28,9 @@ func TestMain(m *testing.M) {
 }
 
 func setup() {
-	helper.SetupTables()
 	os.Setenv(LumberToken, "foo")
 }
+
+func loadFixture(t *testing.T, file string) {
+	helper.LoadMemoryFixture(t, file, entryRepository, tokenRepository, userRepository)
+}
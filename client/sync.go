@@ -0,0 +1,221 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+)
+
+// ManifestFile is the default name of the manifest in the synchronized directory
+const ManifestFile = ".lumber-manifest.json"
+
+// Manifest maps the markdown files to the synchronized entries
+type Manifest struct {
+	// Entries is keyed by the slash separated path relative to the directory
+	Entries map[string]*ManifestEntry `json:"entries"`
+}
+
+// ManifestEntry represent the entry synchronized from the file
+// Version is the version of the entry synchronized last, zero in the manifest saved before recording it
+type ManifestEntry struct {
+	ID      int    `json:"id"`
+	Hash    string `json:"hash"`
+	Version int    `json:"version,omitempty"`
+}
+
+// LoadManifest returns the manifest read from the file, or the empty manifest when the file doesn't exist
+func LoadManifest(file string) (*Manifest, error) {
+	m := &Manifest{Entries: make(map[string]*ManifestEntry)}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the manifest %s", file)
+	}
+	if m.Entries == nil {
+		m.Entries = make(map[string]*ManifestEntry)
+	}
+	return m, nil
+}
+
+// Save writes the manifest to the file atomically
+func (m *Manifest) Save(file string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// SyncOp is the operation to synchronize an entry with the file
+type SyncOp int
+
+// SyncOp types
+const (
+	SyncCreate SyncOp = iota + 1
+	SyncEdit
+	SyncDelete
+)
+
+func (o SyncOp) String() string {
+	switch o {
+	case SyncCreate:
+		return "create"
+	case SyncEdit:
+		return "edit"
+	case SyncDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// SyncAction represent the operation needed to synchronize the file
+// Edit and delete are applied only to the Version of the entry unless zero
+type SyncAction struct {
+	Op      SyncOp
+	Path    string
+	ID      int
+	Hash    string
+	Version int
+}
+
+func (a *SyncAction) String() string {
+	if a.Op == SyncCreate {
+		return fmt.Sprintf("%s %s", a.Op, a.Path)
+	}
+	return fmt.Sprintf("%s %s id=%d", a.Op, a.Path, a.ID)
+}
+
+// PlanSync returns the actions to synchronize the markdown files in the directory with the entries
+// The files are new, changed or removed compared with the manifest
+// Hidden files and directories are ignored, e.g. ".git" and the manifest
+func PlanSync(dir string, m *Manifest) ([]*SyncAction, error) {
+	hashes := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !isMarkdownFile(info.Name()) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hash, err := entryHash(path)
+		if err != nil {
+			return err
+		}
+		hashes[filepath.ToSlash(rel)] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]*SyncAction, 0)
+	for path, hash := range hashes {
+		e, ok := m.Entries[path]
+		switch {
+		case !ok:
+			actions = append(actions, &SyncAction{Op: SyncCreate, Path: path, Hash: hash})
+		case e.Hash != hash:
+			actions = append(actions, &SyncAction{Op: SyncEdit, Path: path, ID: e.ID, Hash: hash, Version: e.Version})
+		}
+	}
+	for path, e := range m.Entries {
+		if _, ok := hashes[path]; !ok {
+			actions = append(actions, &SyncAction{Op: SyncDelete, Path: path, ID: e.ID, Version: e.Version})
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].Path < actions[j].Path
+	})
+	return actions, nil
+}
+
+// Sync applies the actions to the server and records the results in the manifest
+// Stops at the first failure, the manifest keeps the results of the applied actions
+// Fails with ErrEntryConflict when the entry has been changed on the server since the version of the action
+func (c *Client) Sync(ctx context.Context, dir string, m *Manifest, actions []*SyncAction, tags ...string) error {
+	for _, a := range actions {
+		file := filepath.Join(dir, filepath.FromSlash(a.Path))
+		switch a.Op {
+		case SyncCreate:
+			id, err := c.CreateEntry(ctx, file, tags...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to %s", a)
+			}
+			if id == 0 {
+				return errors.Errorf("failed to %s: the entry of the same title already exists", a)
+			}
+			a.ID = id
+			m.Entries[a.Path] = &ManifestEntry{ID: id, Hash: a.Hash, Version: firstVersion}
+		case SyncEdit:
+			version, err := c.Entry(a.ID).IfMatch(a.Version).Edit(ctx, file, tags...)
+			if err != nil {
+				return errors.Wrapf(err, "failed to %s", a)
+			}
+			m.Entries[a.Path] = &ManifestEntry{ID: a.ID, Hash: a.Hash, Version: version}
+		case SyncDelete:
+			if err := c.Entry(a.ID).IfMatch(a.Version).Delete(ctx); err != nil {
+				return errors.Wrapf(err, "failed to %s", a)
+			}
+			delete(m.Entries, a.Path)
+		}
+	}
+	return nil
+}
+
+// entryHash returns the hash of the markdown file and the linked local images
+// Replacing the images changes the hash as well as editing the file
+func entryHash(file string) (string, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write(data)
+	for _, link := range domain.AssetLinks(data) {
+		key := domain.AssetLinkKey(link)
+		if !domain.IsNestedAssetLinkKey(key) {
+			continue
+		}
+		path := filepath.Join(filepath.Dir(file), filepath.FromSlash(key))
+		hash, err := fileHash(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "\x00%s\x00%s", link, hash)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,227 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/truncate_entries.sql")
+
+	dir, err := ioutil.TempDir("", "lumber-sync")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	manifestFile := filepath.Join(dir, ManifestFile)
+
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	ctx := context.Background()
+
+	type expectAction struct {
+		op   SyncOp
+		path string
+	}
+	cases := []struct {
+		write         map[string]string
+		remove        []string
+		expectActions []expectAction
+		expectTitles  map[string]string
+	}{
+		{
+			map[string]string{
+				"a.md":           "a_title\n\ncontent",
+				"sub/b.markdown": "b_title\n\ncontent",
+				"sub/image.png":  "not an entry",
+				".git/c.md":      "c_title\n\ncontent",
+			},
+			nil,
+			[]expectAction{{SyncCreate, "a.md"}, {SyncCreate, "sub/b.markdown"}},
+			map[string]string{"a.md": "a_title", "sub/b.markdown": "b_title"},
+		},
+		{
+			nil,
+			nil,
+			[]expectAction{},
+			map[string]string{"a.md": "a_title", "sub/b.markdown": "b_title"},
+		},
+		{
+			map[string]string{
+				"a.md": "a_title_2\n\ncontent",
+				"d.md": "d_title\n\ncontent",
+			},
+			[]string{"sub/b.markdown"},
+			[]expectAction{{SyncEdit, "a.md"}, {SyncCreate, "d.md"}, {SyncDelete, "sub/b.markdown"}},
+			map[string]string{"a.md": "a_title_2", "d.md": "d_title"},
+		},
+	}
+	for i, c := range cases {
+		writeFiles(t, dir, c.write)
+		for _, name := range c.remove {
+			os.Remove(filepath.Join(dir, filepath.FromSlash(name)))
+		}
+
+		manifest, err := LoadManifest(manifestFile)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		actions, err := PlanSync(dir, manifest)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		got := []expectAction{}
+		for _, a := range actions {
+			got = append(got, expectAction{a.Op, a.Path})
+		}
+		if !reflect.DeepEqual(got, c.expectActions) {
+			t.Errorf("#%d: want actions %v, got %v", i, c.expectActions, got)
+		}
+
+		if err := client.Sync(ctx, dir, manifest, actions); err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if err := manifest.Save(manifestFile); err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+
+		saved, err := LoadManifest(manifestFile)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		titles := make(map[string]string)
+		for path, e := range saved.Entries {
+			entry, err := client.Entry(e.ID).Get(ctx)
+			if err != nil {
+				t.Fatalf("#%d: want non error, got %#v", i, err)
+			}
+			titles[path] = entry.Title
+		}
+		if !reflect.DeepEqual(titles, c.expectTitles) {
+			t.Errorf("#%d: want titles %v, got %v", i, c.expectTitles, titles)
+		}
+	}
+}
+
+func TestSyncDryRun(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/truncate_entries.sql")
+
+	dir, err := ioutil.TempDir("", "lumber-sync")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{"a.md": "a_title\n\ncontent"})
+
+	var out bytes.Buffer
+	cli := &CLI{OutStream: &out, ErrStream: ioutil.Discard}
+	code := cli.Run([]string{"client", "sync", "-dir", dir, "-dry-run", "-addr", ts.URL})
+	if code != ExitCodeOK {
+		t.Fatalf("want exit code %d, got %d", ExitCodeOK, code)
+	}
+	if expect := "create a.md\n"; out.String() != expect {
+		t.Errorf("want output %q, got %q", expect, out.String())
+	}
+	if _, err := os.Stat(filepath.Join(dir, ManifestFile)); !os.IsNotExist(err) {
+		t.Errorf("want no manifest by the dry run, got %#v", err)
+	}
+	ids, err := entryRepository.GetIDs(repository.EntryFilter{IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(ids) != 0 {
+		t.Errorf("want no entries by the dry run, got %v", ids)
+	}
+}
+
+func TestSyncConflict(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "fixture/truncate_entries.sql")
+
+	dir, err := ioutil.TempDir("", "lumber-sync")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+	writeFiles(t, dir, map[string]string{"a.md": "a_title\n\ncontent"})
+
+	client, err := New()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	sync := func(args ...string) (int, string) {
+		var errOut bytes.Buffer
+		cli := &CLI{OutStream: ioutil.Discard, ErrStream: &errOut}
+		code := cli.Run(append([]string{"client", "sync", "-dir", dir, "-addr", ts.URL}, args...))
+		return code, errOut.String()
+	}
+	if code, out := sync(); code != ExitCodeOK {
+		t.Fatalf("want exit code %d, got %d: %s", ExitCodeOK, code, out)
+	}
+	manifest, err := LoadManifest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	e := manifest.Entries["a.md"]
+	if e.Version != firstVersion {
+		t.Errorf("want version %d, got %d", firstVersion, e.Version)
+	}
+
+	// edited by the other after the sync
+	if _, err := client.Entry(e.ID).Edit(context.Background(), "testdata/minimum.md"); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	writeFiles(t, dir, map[string]string{"a.md": "a_title_2\n\ncontent"})
+
+	cases := []struct {
+		args          []string
+		expectCode    int
+		expectVersion int
+	}{
+		{[]string{}, ExitCodeApplyCommandError, firstVersion},
+		{[]string{"-force"}, ExitCodeOK, firstVersion + 2},
+	}
+	for i, c := range cases {
+		code, out := sync(c.args...)
+		if code != c.expectCode {
+			t.Errorf("#%d: want exit code %d, got %d: %s", i, c.expectCode, code, out)
+		}
+		if c.expectCode != ExitCodeOK && !strings.Contains(out, "-force") {
+			t.Errorf("#%d: want the error suggesting -force, got %s", i, out)
+		}
+		manifest, err := LoadManifest(filepath.Join(dir, ManifestFile))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if v := manifest.Entries["a.md"].Version; v != c.expectVersion {
+			t.Errorf("#%d: want version %d, got %d", i, c.expectVersion, v)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// firstVersion is the version of the created entry
+const firstVersion = 1
+
+// fileVersion is the entry and the version which the markdown file is based on
+type fileVersion struct {
+	ID      int `json:"id"`
+	Version int `json:"version"`
+}
+
+// versionFile returns the hidden file next to the markdown file, which records the version
+func versionFile(file string) string {
+	return filepath.Join(filepath.Dir(file), "."+filepath.Base(file)+".lumber")
+}
+
+// LoadVersion returns the version of the entry which the file was pulled, posted or edited last
+// Returns zero when not recorded, or recorded for the other entry
+func LoadVersion(file string, id int) (int, error) {
+	data, err := ioutil.ReadFile(versionFile(file))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	v := fileVersion{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, errors.Wrapf(err, "failed to parse the version of %s", file)
+	}
+	if v.ID != id {
+		return 0, nil
+	}
+	return v.Version, nil
+}
+
+// SaveVersion records the version of the entry which the file is based on
+func SaveVersion(file string, id, version int) error {
+	data, err := json.Marshal(fileVersion{ID: id, Version: version})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(versionFile(file), append(data, '\n'), 0644)
+}
@@ -5,4 +5,33 @@ const (
 	MaxTitleBytes = 1 << 8
 	// max size of mysql text type
 	MaxContentBytes = 1<<16 - 1
+	MaxSlugBytes    = 1 << 8
+	MaxSummaryBytes = 1 << 9
+	MaxTagBytes     = 1 << 6
+	// max size of the file path in the source repository
+	MaxEntryPathBytes = 1 << 8
+)
+
+// Constants for the pages of the entries
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// Constants for assets model
+const (
+	MaxAssetNameBytes = 1 << 8
+	MaxAssetLinkBytes = 1 << 8
+)
+
+// Constants for users model
+const (
+	MaxUserNameBytes   = 1 << 6
+	MaxTokenLabelBytes = 1 << 6
+)
+
+// Constants for audit logs
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 1000
 )
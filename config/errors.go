@@ -8,4 +8,19 @@ var (
 	ErrEmptyEntry             = errors.New("posting entry is empty")
 	ErrEntrySizeLimitExceeded = errors.New("posting entry size is limit exceeded")
 	ErrDuplicatedTitle        = errors.New("duplicated the entry title")
+	ErrInvalidFrontMatter     = errors.New("invalid front matter")
+	ErrDuplicatedSlug         = errors.New("duplicated the entry slug")
+	ErrEmptyPublishAt         = errors.New("scheduled entry requires the publish date")
+	ErrUnknownRenderExtension = errors.New("unknown markdown extension")
+	ErrEmptyAsset             = errors.New("uploading asset is empty")
+	ErrAssetSizeLimitExceeded = errors.New("uploading asset size is limit exceeded")
+	ErrUnsupportedAssetType   = errors.New("unsupported asset type")
+	ErrInvalidAssetLink       = errors.New("invalid link to the asset")
+	ErrInvalidUserName        = errors.New("invalid user name")
+	ErrInvalidUserRole        = errors.New("invalid user role")
+	ErrInvalidTokenLabel      = errors.New("invalid token label")
+	ErrInvalidTokenExpiry     = errors.New("token expiry must be in the future")
+	ErrInvalidAuditFilter     = errors.New("invalid audit log filter")
+	ErrInvalidPageQuery       = errors.New("invalid page query")
+	ErrEntryVersionConflict   = errors.New("the entry has been changed since the version")
 )
@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// Archive represent the number of the entries created in the month
+type Archive struct {
+	Year  int        `json:"year"`
+	Month time.Month `json:"month"`
+	Count int        `json:"count"`
+}
+
+// NewArchives returns the archives of the created times ordered by the newest month
+// The months are in UTC
+func NewArchives(times []time.Time) []*Archive {
+	counts := make(map[time.Time]int)
+	for _, t := range times {
+		t = t.UTC()
+		counts[time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)]++
+	}
+	months := make([]time.Time, 0, len(counts))
+	for m := range counts {
+		months = append(months, m)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].After(months[j]) })
+
+	archives := make([]*Archive, 0, len(months))
+	for _, m := range months {
+		archives = append(archives, &Archive{Year: m.Year(), Month: m.Month(), Count: counts[m]})
+	}
+	return archives
+}
+
+// MonthRange returns the beginning of the month and the next month in UTC
+func MonthRange(year int, month time.Month) (since, until time.Time) {
+	since = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return since, since.AddDate(0, 1, 0)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewArchives(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	cases := []struct {
+		input  []time.Time
+		expect []*Archive
+	}{
+		{nil, []*Archive{}},
+		{
+			[]time.Time{
+				time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2017, 12, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, 3, 31, 23, 59, 59, 0, time.UTC),
+				// 2018-02-28 in UTC
+				time.Date(2018, 3, 1, 8, 0, 0, 0, jst),
+			},
+			[]*Archive{
+				{Year: 2018, Month: time.March, Count: 2},
+				{Year: 2018, Month: time.February, Count: 1},
+				{Year: 2017, Month: time.December, Count: 1},
+			},
+		},
+	}
+	for i, c := range cases {
+		act := NewArchives(c.input)
+		if !reflect.DeepEqual(act, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, act)
+		}
+	}
+}
+
+func TestMonthRange(t *testing.T) {
+	since, until := MonthRange(2018, time.December)
+	if expect := time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC); !since.Equal(expect) {
+		t.Errorf("want %v, got %v", expect, since)
+	}
+	if expect := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC); !until.Equal(expect) {
+		t.Errorf("want %v, got %v", expect, until)
+	}
+}
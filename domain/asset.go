@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Asset represent the metadata of the uploaded file
+// The file is identified by the sha256 hash of the content
+type Asset struct {
+	ID          int       `json:"id"`
+	Hash        string    `json:"hash"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Path returns the URL path to serve the file
+func (a *Asset) Path() string {
+	return "/assets/" + a.Hash
+}
+
+// IsAssetHash reports whether the hash is a hex encoded sha256 as the identifier of the asset
+func IsAssetHash(hash string) bool {
+	if len(hash) != 64 {
+		return false
+	}
+	for _, c := range hash {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Asset errors
+var (
+	ErrNotFoundAsset = errors.New("failed to not found asset")
+)
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// imageLinkRegexp matches the destinations of the markdown images and the src of the <img> tags
+// The first group is the prefix of the destination, the second group is the destination
+var imageLinkRegexp = regexp.MustCompile(`(!\[[^\]\n]*\]\(\s*<?|(?i:<img\s[^>]*?\bsrc\s*=\s*["']))([^)\s>"']+)`)
+
+// AssetLinks returns the local links of the images in the markdown like "./images/foo.png"
+// The links are unique and ordered by the appearance
+func AssetLinks(markdown []byte) []string {
+	links := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, m := range imageLinkRegexp.FindAllSubmatch(markdown, -1) {
+		link := string(m[2])
+		if !isLocalLink(link) || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// AssetLinkKey returns the normalized link as the key of the mapping to the asset
+// "./images/foo.png" and "images/foo.png" are the same key
+func AssetLinkKey(link string) string {
+	if unescaped, err := url.PathUnescape(link); err == nil {
+		link = unescaped
+	}
+	return path.Clean(link)
+}
+
+// IsNestedAssetLinkKey returns whether the key refers to the file in the directory of the markdown or the subdirectories
+// The absolute paths and the paths to the parent directories are not
+func IsNestedAssetLinkKey(key string) bool {
+	return !path.IsAbs(key) && key != ".." && !strings.HasPrefix(key, "../")
+}
+
+// MergeAssetLinks returns the mapping of the links to the assets updated by the new mapping
+// The links of the new mapping are normalized by AssetLinkKey
+func MergeAssetLinks(current, assets map[string]string) map[string]string {
+	merged := make(map[string]string, len(current)+len(assets))
+	for link, hash := range current {
+		merged[link] = hash
+	}
+	for link, hash := range assets {
+		merged[AssetLinkKey(link)] = hash
+	}
+	return merged
+}
+
+// ReplaceAssetLinks replaces the local links of the images with the paths of the assets
+// The assets maps the keys of the links to the hashes of the assets, the unknown links are kept
+func ReplaceAssetLinks(markdown []byte, assets map[string]string) []byte {
+	if len(assets) == 0 {
+		return markdown
+	}
+	return imageLinkRegexp.ReplaceAllFunc(markdown, func(m []byte) []byte {
+		sub := imageLinkRegexp.FindSubmatch(m)
+		link := string(sub[2])
+		if !isLocalLink(link) {
+			return m
+		}
+		hash, ok := assets[AssetLinkKey(link)]
+		if !ok {
+			return m
+		}
+		a := &Asset{Hash: hash}
+		return append(append([]byte{}, sub[1]...), a.Path()...)
+	})
+}
+
+// isLocalLink reports whether the link is the relative path to the local file
+func isLocalLink(link string) bool {
+	if strings.HasPrefix(link, "/") || strings.HasPrefix(link, "#") {
+		return false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	return len(u.Scheme) == 0 && len(u.Host) == 0 && len(u.Path) != 0
+}
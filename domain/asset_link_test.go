@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAssetLinks(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect []string
+	}{
+		{
+			"![a](./images/a.png) ![b](images/b.png \"title\") ![a again](./images/a.png)",
+			[]string{"./images/a.png", "images/b.png"},
+		},
+		{
+			`<img src="../shared/c.gif" alt="c"> <IMG class="x" src='d.jpg'>`,
+			[]string{"../shared/c.gif", "d.jpg"},
+		},
+		{
+			"![remote](https://example.com/a.png) ![abs](/assets/x) ![anchor](#top) ![proto](//example.com/a.png) [link](./doc.pdf)",
+			[]string{},
+		},
+		{
+			"![angle](<images/a.png>)",
+			[]string{"images/a.png"},
+		},
+	}
+	for i, c := range cases {
+		if got := AssetLinks([]byte(c.input)); !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, got)
+		}
+	}
+}
+
+func TestReplaceAssetLinks(t *testing.T) {
+	hash := strings.Repeat("a", 64)
+	assets := MergeAssetLinks(nil, map[string]string{"./images/a.png": hash, "images/my%20b.png": hash})
+
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"![a](./images/a.png \"t\")", "![a](/assets/" + hash + " \"t\")"},
+		{"![a](images/a.png)", "![a](/assets/" + hash + ")"},
+		{"![b](images/my%20b.png)", "![b](/assets/" + hash + ")"},
+		{`<img src="images/a.png">`, `<img src="/assets/` + hash + `">`},
+		{"![c](images/c.png) [a](images/a.png)", "![c](images/c.png) [a](images/a.png)"},
+	}
+	for i, c := range cases {
+		if got := string(ReplaceAssetLinks([]byte(c.input), assets)); got != c.expect {
+			t.Errorf("#%d: want %q, got %q", i, c.expect, got)
+		}
+	}
+}
+
+func TestMergeAssetLinks(t *testing.T) {
+	hashA := strings.Repeat("a", 64)
+	hashB := strings.Repeat("b", 64)
+	current := map[string]string{"images/a.png": hashA, "images/b.png": hashA}
+
+	got := MergeAssetLinks(current, map[string]string{"./images/b.png": hashB})
+	expect := map[string]string{"images/a.png": hashA, "images/b.png": hashB}
+	if !reflect.DeepEqual(got, expect) {
+		t.Errorf("want %v, got %v", expect, got)
+	}
+	if current["images/b.png"] != hashA {
+		t.Errorf("want not to change the current mapping, got %v", current)
+	}
+}
+
+func TestIsNestedAssetLinkKey(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect bool
+	}{
+		{"./images/a.png", true},
+		{"images/../a.png", true},
+		{"..a.png", true},
+		{"../a.png", false},
+		{"images/../../a.png", false},
+		{"%2Fetc%2Fpasswd", false},
+		{"..", false},
+	}
+	for i, c := range cases {
+		if got := IsNestedAssetLinkKey(AssetLinkKey(c.input)); got != c.expect {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, got)
+		}
+	}
+}
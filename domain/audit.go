@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// AuditAction is the kind of the audited operation
+type AuditAction string
+
+// AuditAction variables
+const (
+	AuditActionPost   AuditAction = "post"
+	AuditActionEdit   AuditAction = "edit"
+	AuditActionDelete AuditAction = "delete"
+	AuditActionRevert AuditAction = "revert"
+)
+
+// IsValid returns whether the action is known
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionPost, AuditActionEdit, AuditActionDelete, AuditActionRevert:
+		return true
+	}
+	return false
+}
+
+// Actor represent who operates the entries
+// The zero value is the server itself, e.g. the webhook
+type Actor struct {
+	UserID     int
+	TokenID    int
+	RemoteAddr string
+}
+
+// AuditLog represent the record of the operation to the entry
+type AuditLog struct {
+	ID         int         `json:"id"`
+	Action     AuditAction `json:"action"`
+	EntryID    int         `json:"entry_id"`
+	UserID     int         `json:"user_id"`
+	TokenID    int         `json:"token_id"`
+	RemoteAddr string      `json:"remote_addr"`
+	// BeforeHash and AfterHash are the content hashes of the entry, empty when the entry didn't exist
+	BeforeHash string    `json:"before_hash"`
+	AfterHash  string    `json:"after_hash"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// NewAuditLog returns the AuditLog of the action by the actor
+// The entries are the states before and after the action, nil when the entry didn't exist
+func NewAuditLog(action AuditAction, actor Actor, entryID int, before, after *Entry) *AuditLog {
+	return &AuditLog{
+		Action:     action,
+		EntryID:    entryID,
+		UserID:     actor.UserID,
+		TokenID:    actor.TokenID,
+		RemoteAddr: actor.RemoteAddr,
+		BeforeHash: before.ContentHash(),
+		AfterHash:  after.ContentHash(),
+	}
+}
+
+// ContentHash returns the hex encoded sha256 of the title and the content, empty for nil
+func (e *Entry) ContentHash() string {
+	if e == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(e.Title + "\n" + e.Content))
+	return hex.EncodeToString(sum[:])
+}
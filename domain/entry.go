@@ -1,13 +1,38 @@
 package domain
 
-import "strings"
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
 
 // Entry represent the entry entity
 type Entry struct {
-	ID      int         `json:"id"`
-	Title   string      `json:"title"`
-	Content string      `json:"content"`
-	Status  EntryStatus `json:"status"`
+	ID        int         `json:"id"`
+	Title     string      `json:"title"`
+	Content   string      `json:"content"`
+	Status    EntryStatus `json:"status"`
+	Slug      string      `json:"slug"`
+	Summary   string      `json:"summary,omitempty"`
+	PublishAt *time.Time  `json:"publish_at,omitempty"`
+	Tags      []string    `json:"tags"`
+	// AuthorID is the user who posted the entry, zero when posted without the user
+	AuthorID int `json:"author_id,omitempty"`
+	// Version is incremented by every change of the entry, starts from 1
+	Version int `json:"version"`
+	// Source is the raw markdown the content is rendered from, served by the content negotiation
+	Source string `json:"-"`
+	// Assets maps the local links of the images in the source to the hashes of the uploaded assets
+	Assets    map[string]string `json:"-"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ETag returns the entity tag of the version of the entry
+func (e *Entry) ETag() string {
+	return strconv.Quote(strconv.Itoa(e.Version))
 }
 
 // UpdateStatusByTitle update entry status by title
@@ -18,6 +43,21 @@ func (e *Entry) UpdateStatusByTitle() {
 	}
 }
 
+// IsScheduled returns whether the entry is waiting to be published
+func (e *Entry) IsScheduled() bool {
+	return e.Status == EntryStatusScheduled
+}
+
+// HasTag returns whether the entry has the tag
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // TrimPrivateTitle returns contain private keyword in the title, and trimmed title
 func (e *Entry) TrimPrivateTitle() (string, bool) {
 	if len(e.Title) < 6 {
@@ -44,6 +84,8 @@ type EntryStatus int
 const (
 	EntryStatusPublic EntryStatus = iota
 	EntryStatusPrivate
+	// EntryStatusScheduled becomes public when the PublishAt time comes
+	EntryStatusScheduled
 )
 
 func (es EntryStatus) String() string {
@@ -52,6 +94,8 @@ func (es EntryStatus) String() string {
 		return "public"
 	case EntryStatusPrivate:
 		return "private"
+	case EntryStatusScheduled:
+		return "scheduled"
 	default:
 		return "unknown"
 	}
@@ -61,3 +105,13 @@ func (es EntryStatus) String() string {
 func (es EntryStatus) IsValid() bool {
 	return es.String() != "unknown"
 }
+
+// ParseEntryStatus returns the EntryStatus matched by the name
+func ParseEntryStatus(s string) (EntryStatus, error) {
+	for _, es := range []EntryStatus{EntryStatusPublic, EntryStatusPrivate, EntryStatusScheduled} {
+		if strings.EqualFold(s, es.String()) {
+			return es, nil
+		}
+	}
+	return 0, errors.Errorf("invalid entry status: %s", s)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"io"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+// AssetRepository represent repository of the asset metadata
+type AssetRepository interface {
+	Get(hash string) (*domain.Asset, error)
+	Save(*domain.Asset) (int, error)
+}
+
+// AssetStorage represent storage of the asset files
+type AssetStorage interface {
+	Put(hash string, r io.Reader) error
+	Open(hash string) (io.ReadCloser, error)
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+// AuditRepository represent reopsitory of the audit logs
+// Find must return the logs matched by the AuditFilter ordered by the newest
+type AuditRepository interface {
+	Save(*domain.AuditLog) (int, error)
+	Find(f AuditFilter) ([]*domain.AuditLog, error)
+}
+
+// AuditFilter represent conditions to read the audit logs
+// The zero value of each field matches any logs
+type AuditFilter struct {
+	Action  domain.AuditAction
+	EntryID int
+	UserID  int
+	TokenID int
+	// Since and Until are the range of the created time, Until is exclusive
+	Since *time.Time
+	Until *time.Time
+	// Limit is the maximum number of the logs
+	Limit int
+}
+
+// Match returns whether the log is matched by the filter, except for the limit
+func (f AuditFilter) Match(l *domain.AuditLog) bool {
+	switch {
+	case len(f.Action) != 0 && l.Action != f.Action:
+		return false
+	case f.EntryID != 0 && l.EntryID != f.EntryID:
+		return false
+	case f.UserID != 0 && l.UserID != f.UserID:
+		return false
+	case f.TokenID != 0 && l.TokenID != f.TokenID:
+		return false
+	case f.Since != nil && l.CreatedAt.Before(*f.Since):
+		return false
+	case f.Until != nil && !l.CreatedAt.Before(*f.Until):
+		return false
+	}
+	return true
+}
@@ -1,14 +1,186 @@
 package repository
 
-import "github.com/takashabe/lumber/domain"
+import (
+	"strings"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+)
 
 // EntryRepository represent reopsitory of the entry
+// Save and Edit must record the saved title and content as a revision,
+// and replace the tags of the entry
+// Edit must keep the previous slug as a redirect when the slug is changed
+// Edit and Delete must fail with ErrEntryVersionConflict unless the version of the entry is the specified one,
+// the zero version skips the check. Edit increments the version, and sets it to the edited entry
+// GetIDs, GetTitles, GetTitlePage, GetTags, GetArchives and GetRecent must only return the entries matched by the EntryFilter
 type EntryRepository interface {
 	Get(id int) (*domain.Entry, error)
 	GetByTitle(title string) (*domain.Entry, error)
-	GetIDs() ([]int, error)
-	GetTitles(start, n int) ([]*domain.Entry, error)
+	GetBySlug(slug string) (*domain.Entry, error)
+	GetRedirectedSlug(slug string) (string, error)
+	GetIDs(f EntryFilter) ([]int, error)
+	// GetTitles returns the entries with the id, title and timestamps
+	GetTitles(f EntryFilter, start, n int) ([]*domain.Entry, error)
+	// GetTitlePage returns the entries with the id, title and timestamps in the page
+	GetTitlePage(f EntryFilter, q EntryPageQuery) ([]*domain.Entry, error)
+	// Count returns the number of the entries matched by the filter
+	Count(f EntryFilter) (int, error)
+	GetTags(f EntryFilter) ([]*domain.Tag, error)
+	// GetArchives returns the number of the entries in each month ordered by the newest
+	GetArchives(f EntryFilter) ([]*domain.Archive, error)
+	GetRecent(f EntryFilter, n int) ([]*domain.Entry, error)
 	Save(*domain.Entry) (int, error)
 	Edit(*domain.Entry) error
-	Delete(id int) (bool, error)
+	Delete(id, version int) (bool, error)
+	PublishScheduled(now time.Time) (int, error)
+
+	GetRevisions(entryID int) ([]*domain.Revision, error)
+	GetRevision(entryID, revisionID int) (*domain.Revision, error)
+}
+
+// EntryFilter represent conditions to read the entries
+// The zero value matches the public entries
+type EntryFilter struct {
+	// Tags matches the entries which have all the tags
+	Tags []string
+	// IncludePrivate matches the private entries as well, only for the authorized requests
+	IncludePrivate bool
+	// IncludeScheduled matches the scheduled entries as well, only for the internal operations
+	IncludeScheduled bool
+	// Since and Until are the range of the created time, Until is exclusive
+	Since *time.Time
+	Until *time.Time
+}
+
+// Statuses returns the entry statuses matched by the filter
+func (f EntryFilter) Statuses() []domain.EntryStatus {
+	statuses := []domain.EntryStatus{domain.EntryStatusPublic}
+	if f.IncludePrivate {
+		statuses = append(statuses, domain.EntryStatusPrivate)
+	}
+	if f.IncludeScheduled {
+		statuses = append(statuses, domain.EntryStatusScheduled)
+	}
+	return statuses
+}
+
+// Match returns whether the entry matched by the filter
+func (f EntryFilter) Match(e *domain.Entry) bool {
+	found := false
+	for _, s := range f.Statuses() {
+		if e.Status == s {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+
+	if f.Since != nil && e.CreatedAt.Before(*f.Since) {
+		return false
+	}
+	if f.Until != nil && !e.CreatedAt.Before(*f.Until) {
+		return false
+	}
+
+	for _, t := range domain.NormalizeTags(f.Tags) {
+		if !e.HasTag(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// EntrySortKey is the field to sort the entries
+type EntrySortKey string
+
+// EntrySortKey variables
+const (
+	SortByCreatedAt EntrySortKey = "created_at"
+	SortByUpdatedAt EntrySortKey = "updated_at"
+	SortByTitle     EntrySortKey = "title"
+)
+
+// IsValid returns whether the key is known
+func (k EntrySortKey) IsValid() bool {
+	switch k {
+	case SortByCreatedAt, SortByUpdatedAt, SortByTitle:
+		return true
+	}
+	return false
+}
+
+// EntrySort represent the order of the entries, the id breaks the ties in the same direction
+type EntrySort struct {
+	Key  EntrySortKey
+	Desc bool
+}
+
+// Less returns whether the entry a is ordered before the entry b
+func (s EntrySort) Less(a, b *domain.Entry) bool {
+	var cmp int
+	switch s.Key {
+	case SortByTitle:
+		cmp = strings.Compare(a.Title, b.Title)
+	case SortByUpdatedAt:
+		cmp = compareTime(a.UpdatedAt, b.UpdatedAt)
+	default:
+		cmp = compareTime(a.CreatedAt, b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = a.ID - b.ID
+	}
+	if s.Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// EntryCursor is the position of the entry in the sorted entries
+type EntryCursor struct {
+	ID    int
+	Title string
+	// Time is the created or updated time by the sort key
+	Time time.Time
+}
+
+// NewEntryCursor returns the position of the entry in the order
+func NewEntryCursor(s EntrySort, e *domain.Entry) *EntryCursor {
+	c := &EntryCursor{ID: e.ID}
+	switch s.Key {
+	case SortByTitle:
+		c.Title = e.Title
+	case SortByUpdatedAt:
+		c.Time = e.UpdatedAt
+	default:
+		c.Time = e.CreatedAt
+	}
+	return c
+}
+
+// Entry returns the entry at the position to compare with the others by EntrySort.Less
+func (c *EntryCursor) Entry() *domain.Entry {
+	return &domain.Entry{ID: c.ID, Title: c.Title, CreatedAt: c.Time, UpdatedAt: c.Time}
+}
+
+// EntryPageQuery represent the page of the sorted entries
+// The entries are after the After cursor, or before the Before cursor, at most one of them is specified
+// The entries are always in the order of the Sort
+type EntryPageQuery struct {
+	Sort   EntrySort
+	After  *EntryCursor
+	Before *EntryCursor
+	Limit  int
 }
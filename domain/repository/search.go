@@ -0,0 +1,12 @@
+package repository
+
+import "github.com/takashabe/lumber/domain"
+
+// SearchRepository represent the full-text index of the entries
+// Search must return all the hits which contain every word of the query,
+// in order of relevance
+type SearchRepository interface {
+	Index(e *domain.Entry) error
+	Remove(id int) error
+	Search(query string) ([]*domain.SearchHit, error)
+}
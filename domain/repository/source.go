@@ -0,0 +1,14 @@
+package repository
+
+// SourceRepository represent the version controlled repository of the entry files
+type SourceRepository interface {
+	// ReadFile returns the content of the file at the revision
+	ReadFile(revision, path string) ([]byte, error)
+}
+
+// EntryPathRepository represent the mapping of the file paths in the source repository to the entries
+type EntryPathRepository interface {
+	GetEntryID(path string) (int, error)
+	Save(path string, entryID int) error
+	Delete(path string) error
+}
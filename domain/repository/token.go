@@ -1,12 +1,22 @@
 package repository
 
-import "github.com/takashabe/lumber/domain"
+import (
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+)
 
 // TokenRepository represent reopsitory of the token
 type TokenRepository interface {
 	Get(id int) (*domain.Token, error)
-	FindByValue(value string) (*domain.Token, error)
+	// FindByPrefix returns the tokens matched by the public prefix of the value
+	FindByPrefix(prefix string) ([]*domain.Token, error)
+	// FindByPlainValue returns the token saved the plain value before hashing
+	FindByPlainValue(value string) (*domain.Token, error)
+	List() ([]*domain.Token, error)
 	Save(*domain.Token) (int, error)
 	Update(*domain.Token) error
+	// Touch records the time the token is used at
+	Touch(id int, at time.Time) error
 	Delete(id int) (bool, error)
 }
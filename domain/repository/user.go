@@ -0,0 +1,13 @@
+package repository
+
+import "github.com/takashabe/lumber/domain"
+
+// UserRepository represent repository of the user
+type UserRepository interface {
+	Get(id int) (*domain.User, error)
+	FindByName(name string) (*domain.User, error)
+	List() ([]*domain.User, error)
+	Save(*domain.User) (int, error)
+	Update(*domain.User) error
+	Delete(id int) (bool, error)
+}
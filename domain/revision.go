@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Revision represent a snapshot of the entry at a point in time
+type Revision struct {
+	ID        int       `json:"id"`
+	EntryID   int       `json:"entry_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content,omitempty"`
+	Source    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Revision errors
+var (
+	ErrNotFoundRevision = errors.New("failed to not found revision")
+)
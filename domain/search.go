@@ -0,0 +1,17 @@
+package domain
+
+// SearchHit represent the entry matched by the search query
+type SearchHit struct {
+	ID    int     `json:"id"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+	// Snippet is the part of the content around the matched words
+	// HTML escaped, and the matched words are surrounded by <mark> tags
+	Snippet string `json:"snippet"`
+}
+
+// SearchResult represent a page of the search hits
+type SearchResult struct {
+	Total int          `json:"total"`
+	Hits  []*SearchHit `json:"hits"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxSlugLength is max bytes of the generated slug
+// leave a margin for the suffix to avoid duplication
+const maxSlugLength = 200
+
+// Slugify returns the URL friendly string from the title
+// Letters and digits are kept in any language, and others are replaced with a hyphen
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sep := hyphen && b.Len() > 0
+			hyphen = false
+			// the slug never ends with the hyphen
+			n := utf8.RuneLen(r)
+			if sep {
+				n++
+			}
+			if b.Len()+n > maxSlugLength {
+				break
+			}
+			if sep {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  go-pubsub  ", "go-pubsub"},
+		{"Go 1.10 release", "go-1-10-release"},
+		{"日本語 タイトル", "日本語-タイトル"},
+		{"!!!", ""},
+		{strings.Repeat("a", 300), strings.Repeat("a", 200)},
+		{strings.Repeat("a", 199) + " b", strings.Repeat("a", 199)},
+		{strings.Repeat("a", 198) + " b", strings.Repeat("a", 198) + "-b"},
+		{strings.Repeat("a", 199) + " 日", strings.Repeat("a", 199)},
+	}
+	for i, c := range cases {
+		if act := Slugify(c.input); act != c.expect {
+			t.Errorf("#%d: want %s, got %s", i, c.expect, act)
+		}
+	}
+}
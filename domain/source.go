@@ -0,0 +1,12 @@
+package domain
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Source errors
+var (
+	ErrNotFoundSourceRevision = errors.New("failed to not found the revision in the source repository")
+	ErrNotFoundSourceFile     = errors.New("failed to not found the file in the source repository")
+	ErrNotFoundEntryPath      = errors.New("failed to not found the entry of the path")
+)
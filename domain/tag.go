@@ -0,0 +1,24 @@
+package domain
+
+import "strings"
+
+// Tag represent the tag of the entries
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// NormalizeTags returns the tags which are trimmed, lower-cased and deduplicated
+func NormalizeTags(tags []string) []string {
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if len(t) == 0 || seen[t] {
+			continue
+		}
+		seen[t] = true
+		res = append(res, t)
+	}
+	return res
+}
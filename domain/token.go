@@ -1,13 +1,84 @@
 package domain
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
 // Token represent the token entity
+// Tokens without the user are issued before the users, and have the privileges of the admin
 type Token struct {
-	ID    int
-	Value string
+	ID int `json:"id"`
+	// Value is the plain token, only known when the token is created or rotated
+	Value string `json:"value,omitempty"`
+	// Prefix is the public part of the value to find the token
+	Prefix string `json:"prefix"`
+	// Hash is the salted hash of the value, or the plain value saved before hashing
+	Hash   string `json:"-"`
+	UserID int    `json:"user_id"`
+	// Label describes the usage of the token, e.g. the name of the machine
+	Label      string     `json:"label"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	// ExpiresAt is nil when the token never expires
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// tokenHashScheme is the prefix of the hash to distinguish from the plain value
+const tokenHashScheme = "sha256$"
+
+// IsExpired returns whether the token is expired at the time
+func (t *Token) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// IsHashed returns whether the value is saved as the hash
+func (t *Token) IsHashed() bool {
+	return strings.HasPrefix(t.Hash, tokenHashScheme)
+}
+
+// SetValue sets the value with the prefix and the salted hash of it
+func (t *Token) SetValue(value string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return errors.Wrap(err, "failed to generate salt")
+	}
+	t.Value = value
+	t.Prefix = TokenPrefix(value)
+	t.Hash = tokenHash(hex.EncodeToString(salt), value)
+	return nil
+}
+
+// Verify returns whether the value matches the token in constant time
+func (t *Token) Verify(value string) bool {
+	if !t.IsHashed() {
+		return subtle.ConstantTimeCompare([]byte(t.Hash), []byte(value)) == 1
+	}
+	parts := strings.SplitN(strings.TrimPrefix(t.Hash, tokenHashScheme), "$", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(t.Hash), []byte(tokenHash(parts[0], value))) == 1
+}
+
+func tokenHash(salt, value string) string {
+	sum := sha256.Sum256([]byte(salt + value))
+	return tokenHashScheme + salt + "$" + hex.EncodeToString(sum[:])
+}
+
+// TokenPrefix returns the public part of the value formatted "<prefix>.<secret>"
+// The values issued before hashing are UUID, the first group is used
+func TokenPrefix(value string) string {
+	if i := strings.IndexAny(value, ".-"); i > 0 {
+		return value[:i]
+	}
+	return ""
 }
 
 // Token errors
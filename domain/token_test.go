@@ -0,0 +1,50 @@
+package domain
+
+import "testing"
+
+func TestVerifyToken(t *testing.T) {
+	hashed := &Token{}
+	if err := hashed.SetValue("0a1b2c3d4e5f.secret"); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if hashed.Prefix != "0a1b2c3d4e5f" || !hashed.IsHashed() {
+		t.Fatalf("want hashed token with the prefix, got %#v", hashed)
+	}
+	plain := &Token{Hash: "f47ac10b-58cc-4372-a567-0e02b2c3d479"}
+
+	cases := []struct {
+		token  *Token
+		input  string
+		expect bool
+	}{
+		{hashed, "0a1b2c3d4e5f.secret", true},
+		{hashed, "0a1b2c3d4e5f.other", false},
+		{hashed, hashed.Hash, false},
+		{hashed, "", false},
+		{plain, "f47ac10b-58cc-4372-a567-0e02b2c3d479", true},
+		{plain, "f47ac10b", false},
+		{&Token{Hash: "sha256$broken"}, "sha256$broken", false},
+	}
+	for i, c := range cases {
+		if got := c.token.Verify(c.input); got != c.expect {
+			t.Errorf("#%d: want %t, got %t", i, c.expect, got)
+		}
+	}
+}
+
+func TestTokenPrefix(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+	}{
+		{"0a1b2c3d4e5f.secret", "0a1b2c3d4e5f"},
+		{"f47ac10b-58cc-4372-a567-0e02b2c3d479", "f47ac10b"},
+		{"foo", ""},
+		{".foo", ""},
+	}
+	for i, c := range cases {
+		if got := TokenPrefix(c.input); got != c.expect {
+			t.Errorf("#%d: want %q, got %q", i, c.expect, got)
+		}
+	}
+}
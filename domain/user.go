@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"github.com/pkg/errors"
+)
+
+// User represent the user entity, authenticated by the owned tokens
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Role Role   `json:"role"`
+}
+
+// Role represent the permissions of the user
+type Role string
+
+// Roles of the user
+const (
+	// RoleAdmin manages the users in addition to the editor
+	RoleAdmin Role = "admin"
+	// RoleEditor writes any entries
+	RoleEditor Role = "editor"
+	// RoleAuthor writes only own entries
+	RoleAuthor Role = "author"
+	// RoleViewer reads the private entries, but can't write
+	RoleViewer Role = "viewer"
+)
+
+// IsValid returns whether the role is known
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleAuthor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// IsAdmin returns whether the user can manage the users
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// CanWrite returns whether the user can post the entries
+func (u *User) CanWrite() bool {
+	switch u.Role {
+	case RoleAdmin, RoleEditor, RoleAuthor:
+		return true
+	}
+	return false
+}
+
+// CanEdit returns whether the user can edit or delete the entry
+// Authors can edit only own entries
+func (u *User) CanEdit(e *Entry) bool {
+	switch u.Role {
+	case RoleAdmin, RoleEditor:
+		return true
+	case RoleAuthor:
+		return u.ID != 0 && u.ID == e.AuthorID
+	}
+	return false
+}
+
+// User errors
+var (
+	ErrUserAlreadyExistSameName = errors.New("failed to save user. A record with the same name already exists")
+	ErrNotFoundUser             = errors.New("failed to not found user")
+)
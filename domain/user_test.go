@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestCanEditUser(t *testing.T) {
+	entry := &Entry{ID: 1, AuthorID: 2}
+	cases := []struct {
+		input       *User
+		expectWrite bool
+		expectEdit  bool
+	}{
+		{&User{ID: 1, Role: RoleAdmin}, true, true},
+		{&User{ID: 1, Role: RoleEditor}, true, true},
+		{&User{ID: 2, Role: RoleAuthor}, true, true},
+		{&User{ID: 1, Role: RoleAuthor}, true, false},
+		{&User{ID: 2, Role: RoleViewer}, false, false},
+		{&User{ID: 2, Role: Role("unknown")}, false, false},
+	}
+	for i, c := range cases {
+		if got := c.input.CanWrite(); got != c.expectWrite {
+			t.Errorf("#%d: want write %t, got %t", i, c.expectWrite, got)
+		}
+		if got := c.input.CanEdit(entry); got != c.expectEdit {
+			t.Errorf("#%d: want edit %t, got %t", i, c.expectEdit, got)
+		}
+	}
+
+	// entries posted without the user are not owned by any authors
+	if (&User{Role: RoleAuthor}).CanEdit(&Entry{ID: 1}) {
+		t.Errorf("want the author without id can't edit the entry without the author")
+	}
+}
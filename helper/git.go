@@ -0,0 +1,77 @@
+package helper
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// GitFixture is the bare git repository pushed from the working tree for the tests
+type GitFixture struct {
+	// Bare is the path of the bare repository
+	Bare string
+
+	t    *testing.T
+	root string
+	work string
+}
+
+// NewGitFixture returns the empty bare repository and the working tree
+// Must be closed to remove the repositories
+func NewGitFixture(t *testing.T) *GitFixture {
+	root, err := ioutil.TempDir("", "lumber-git")
+	if err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	g := &GitFixture{
+		Bare: filepath.Join(root, "bare.git"),
+		t:    t,
+		root: root,
+		work: filepath.Join(root, "work"),
+	}
+	g.git(root, "init", "--quiet", "--bare", g.Bare)
+	g.git(root, "init", "--quiet", g.work)
+	g.git(g.work, "checkout", "--quiet", "-b", "master")
+	return g
+}
+
+// Commit writes and removes the files, then pushes to the master branch of the bare repository
+// Returns the commit id
+func (g *GitFixture) Commit(files map[string]string, removed ...string) string {
+	for name, data := range files {
+		path := filepath.Join(g.work, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			g.t.Fatalf("want non error, got %v", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			g.t.Fatalf("want non error, got %v", err)
+		}
+	}
+	for _, name := range removed {
+		if err := os.Remove(filepath.Join(g.work, filepath.FromSlash(name))); err != nil {
+			g.t.Fatalf("want non error, got %v", err)
+		}
+	}
+	g.git(g.work, "add", "--all")
+	g.git(g.work, "commit", "--quiet", "--allow-empty", "-m", "update")
+	g.git(g.work, "push", "--quiet", g.Bare, "master")
+	return strings.TrimSpace(g.git(g.work, "rev-parse", "HEAD"))
+}
+
+// Close removes the repositories
+func (g *GitFixture) Close() {
+	os.RemoveAll(g.root)
+}
+
+func (g *GitFixture) git(dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{"-c", "user.name=lumber", "-c", "user.email=lumber@example.com"}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		g.t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
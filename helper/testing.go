@@ -3,11 +3,22 @@ package helper
 import (
 	"database/sql"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 
+	_ "github.com/mattn/go-sqlite3" // driver
+	"github.com/pkg/errors"
 	"github.com/takashabe/go-fixture"
 	_ "github.com/takashabe/go-fixture/mysql" // driver
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/memory"
+	"github.com/takashabe/lumber/infrastructure/utils"
+	"github.com/takashabe/lumber/library/config"
+	yaml "gopkg.in/yaml.v2"
 )
 
 // LoadFixture load fixture files
@@ -18,7 +29,15 @@ func LoadFixture(t *testing.T, file string) {
 	}
 	defer db.Close()
 
-	f, err := fixture.NewFixture(db, "mysql")
+	err = loadFixture(db, file)
+	if err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+}
+
+// LoadMemoryFixture load fixture files into the memory repositories
+func LoadMemoryFixture(t *testing.T, file string, e repository.EntryRepository, tr repository.TokenRepository, ur repository.UserRepository) {
+	f, err := memory.NewFixture(e, tr, ur)
 	if err != nil {
 		t.Fatalf("want non error, got %v", err)
 	}
@@ -30,17 +49,18 @@ func LoadFixture(t *testing.T, file string) {
 
 // SetupTables initialize the database by fixture of the schema
 func SetupTables() {
+	SetupTablesFrom("../_sql")
+}
+
+// SetupTablesFrom initialize the database by the schema in the directory
+func SetupTablesFrom(dir string) {
 	db, err := newDatastore()
 	if err != nil {
 		panic(err)
 	}
 	defer db.Close()
 
-	f, err := fixture.NewFixture(db, "mysql")
-	if err != nil {
-		panic(err)
-	}
-	err = f.Load("../_sql/schema.sql")
+	err = loadFixture(db, DialectFile(filepath.Join(dir, "schema.sql")))
 	if err != nil {
 		panic(err)
 	}
@@ -48,25 +68,104 @@ func SetupTables() {
 
 // InitializeTable delete all data each talbe
 func InitializeTable() {
-	db, err := newDatastore()
-	if err != nil {
-		panic(err)
+	SetupTables()
+}
+
+func isSQLite() bool {
+	return config.Config.DB.Driver == utils.DriverSQLite
+}
+
+// DialectFile returns the SQL file for the driver, the "_sqlite" suffixed file for sqlite
+func DialectFile(file string) string {
+	if isSQLite() {
+		ext := filepath.Ext(file)
+		return strings.TrimSuffix(file, ext) + "_sqlite" + ext
+	}
+	return file
+}
+
+func loadFixture(db *sql.DB, file string) error {
+	// go-fixture supports only mysql
+	if isSQLite() {
+		return loadSQLiteFixture(db, file)
 	}
-	defer db.Close()
 
 	f, err := fixture.NewFixture(db, "mysql")
 	if err != nil {
-		panic(err)
+		return err
 	}
-	err = f.Load("../_sql/schema.sql")
+	return f.Load(file)
+}
+
+var truncateRegexp = regexp.MustCompile("(?i)TRUNCATE\\s+(?:TABLE\\s+)?`?(\\w+)`?")
+
+func loadSQLiteFixture(db *sql.DB, file string) error {
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
-		panic(err)
+		return err
+	}
+
+	switch filepath.Ext(file) {
+	case ".sql":
+		// sqlite doesn't have TRUNCATE, emulate it with DELETE and reset the sequence
+		q := truncateRegexp.ReplaceAllString(string(data),
+			"DELETE FROM $1; DELETE FROM sqlite_sequence WHERE name = '$1'")
+		_, err = db.Exec(q)
+		return err
+	case ".yml", ".yaml":
+		return loadSQLiteYAML(db, data)
+	default:
+		return errors.Errorf("unsupported fixture file: %s", file)
+	}
+}
+
+func loadSQLiteYAML(db *sql.DB, data []byte) error {
+	model := struct {
+		Table  string                   `yaml:"table"`
+		Record []map[string]interface{} `yaml:"record"`
+	}{}
+	err := yaml.Unmarshal(data, &model)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf("DELETE FROM `%s`", model.Table))
+	if err != nil {
+		tx.Rollback()
+		return err
 	}
+	for _, r := range model.Record {
+		var (
+			columns      = make([]string, 0, len(r))
+			placeholders = make([]string, 0, len(r))
+			values       = make([]interface{}, 0, len(r))
+		)
+		for k, v := range r {
+			columns = append(columns, fmt.Sprintf("`%s`", k))
+			placeholders = append(placeholders, "?")
+			values = append(values, v)
+		}
+		q := fmt.Sprintf("INSERT INTO `%s` (%s) VALUES (%s)",
+			model.Table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+		if _, err := tx.Exec(q, values...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 // newDatastore returns sql.DB
 // Porting from datastore package
 func newDatastore() (*sql.DB, error) {
+	if isSQLite() {
+		return utils.ConnectSQLite()
+	}
+
 	getEnvWithDefault := func(name, def string) string {
 		if env := os.Getenv(name); len(env) != 0 {
 			return env
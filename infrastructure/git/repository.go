@@ -0,0 +1,92 @@
+package git
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// RepositoryImpl implements the SourceRepository on the local git repository by the git command
+// Both of the bare repository and the working tree are available
+type RepositoryImpl struct {
+	dir string
+
+	// serialize fetching the missing revisions
+	mu sync.Mutex
+}
+
+// NewRepository returns initialized RepositoryImpl
+func NewRepository(dir string) (repository.SourceRepository, error) {
+	r := &RepositoryImpl{dir: dir}
+	if _, err := r.git("rev-parse", "--git-dir"); err != nil {
+		return nil, errors.Wrapf(err, "invalid git repository %s", dir)
+	}
+	return r, nil
+}
+
+// ReadFile returns the content of the file at the revision
+// Fetches from the remotes once when the revision doesn't exist yet, e.g. the mirror of the hosting service
+func (r *RepositoryImpl) ReadFile(revision, path string) ([]byte, error) {
+	if !validRevision(revision) {
+		return nil, errors.Wrapf(domain.ErrNotFoundSourceRevision, "invalid revision: %q", revision)
+	}
+	if err := r.ensureRevision(revision); err != nil {
+		return nil, err
+	}
+
+	object := revision + ":" + strings.TrimPrefix(path, "/")
+	// the directories are not found as well
+	typ, err := r.git("cat-file", "-t", object)
+	if err != nil || strings.TrimSpace(string(typ)) != "blob" {
+		return nil, domain.ErrNotFoundSourceFile
+	}
+	return r.git("cat-file", "blob", object)
+}
+
+func (r *RepositoryImpl) ensureRevision(revision string) error {
+	commit := revision + "^{commit}"
+	if _, err := r.git("cat-file", "-e", commit); err == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.git("fetch", "--quiet", "--all"); err != nil {
+		return errors.Wrap(err, "failed to fetch the source repository")
+	}
+	if _, err := r.git("cat-file", "-e", commit); err != nil {
+		return errors.Wrapf(domain.ErrNotFoundSourceRevision, "revision: %s", revision)
+	}
+	return nil
+}
+
+// git runs the git command in the repository, returns the stdout
+func (r *RepositoryImpl) git(args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// validRevision reports whether the revision is the hex encoded commit id
+// Rejects the options and the revision expressions
+func validRevision(revision string) bool {
+	if len(revision) < 7 || len(revision) > 64 {
+		return false
+	}
+	for _, c := range revision {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package git
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestReadFile(t *testing.T) {
+	fixture := helper.NewGitFixture(t)
+	defer fixture.Close()
+	first := fixture.Commit(map[string]string{"entries/a.md": "# a", "b.md": "# b"})
+	second := fixture.Commit(map[string]string{"entries/a.md": "# a2"}, "b.md")
+
+	repo, err := NewRepository(fixture.Bare)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		revision  string
+		path      string
+		expect    string
+		expectErr error
+	}{
+		{first, "entries/a.md", "# a", nil},
+		{second, "entries/a.md", "# a2", nil},
+		{first, "b.md", "# b", nil},
+		{second, "b.md", "", domain.ErrNotFoundSourceFile},
+		{second, "entries", "", domain.ErrNotFoundSourceFile},
+		{strings.Repeat("0", 40), "b.md", "", domain.ErrNotFoundSourceRevision},
+		{"--output=/tmp/x", "b.md", "", domain.ErrNotFoundSourceRevision},
+		{"HEAD", "b.md", "", domain.ErrNotFoundSourceRevision},
+	}
+	for i, c := range cases {
+		data, err := repo.ReadFile(c.revision, c.path)
+		if errors.Cause(err) != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if string(data) != c.expect {
+			t.Errorf("#%d: want %q, got %q", i, c.expect, data)
+		}
+	}
+
+	if _, err := NewRepository(t.Name()); err == nil {
+		t.Errorf("want error for the invalid repository, got nil")
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// AssetRepositoryImpl implements the AssetRepository on memory
+type AssetRepositoryImpl struct {
+	mu     sync.RWMutex
+	assets map[string]*domain.Asset
+	lastID int
+}
+
+// NewAssetRepository returns initialized AssetRepositoryImpl
+func NewAssetRepository() repository.AssetRepository {
+	return &AssetRepositoryImpl{
+		assets: make(map[string]*domain.Asset),
+	}
+}
+
+// Get returns an asset matched by the hash
+func (r *AssetRepositoryImpl) Get(hash string) (*domain.Asset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.assets[hash]
+	if !ok {
+		return nil, domain.ErrNotFoundAsset
+	}
+	c := *a
+	return &c, nil
+}
+
+// Save saves the asset metadata
+func (r *AssetRepositoryImpl) Save(m *domain.Asset) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	saved := *m
+	saved.ID = r.lastID
+	saved.CreatedAt = time.Now()
+	r.assets[saved.Hash] = &saved
+	return saved.ID, nil
+}
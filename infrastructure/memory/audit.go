@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// AuditRepositoryImpl implements the AuditRepository on memory
+type AuditRepositoryImpl struct {
+	mu   sync.RWMutex
+	logs []*domain.AuditLog
+}
+
+// NewAuditRepository returns initialized AuditRepositoryImpl
+func NewAuditRepository() repository.AuditRepository {
+	return &AuditRepositoryImpl{}
+}
+
+// Save saves the audit log
+func (r *AuditRepositoryImpl) Save(m *domain.AuditLog) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved := *m
+	saved.ID = len(r.logs) + 1
+	r.logs = append(r.logs, &saved)
+	return saved.ID, nil
+}
+
+// Find returns the audit logs matched by the filter ordered by the newest
+func (r *AuditRepositoryImpl) Find(f repository.AuditFilter) ([]*domain.AuditLog, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	logs := []*domain.AuditLog{}
+	for i := len(r.logs) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(logs) >= f.Limit {
+			break
+		}
+		if f.Match(r.logs[i]) {
+			c := *r.logs[i]
+			logs = append(logs, &c)
+		}
+	}
+	return logs, nil
+}
+
+// Reset removes all audit logs
+func (r *AuditRepositoryImpl) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logs = nil
+}
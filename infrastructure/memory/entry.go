@@ -0,0 +1,521 @@
+package memory
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// EntryRepositoryImpl implements the EntryRepository on memory
+type EntryRepositoryImpl struct {
+	mu             sync.RWMutex
+	entries        map[int]*domain.Entry
+	lastID         int
+	revisions      []*domain.Revision
+	lastRevisionID int
+	// entry id by the previous slug
+	redirects map[string]int
+
+	// tag names by id, only used to load fixtures of the entry_tags
+	// because entries hold the tag names on memory
+	fixtureTagNames map[int]string
+}
+
+// NewEntryRepository returns initialized EntryRepositoryImpl
+func NewEntryRepository() repository.EntryRepository {
+	return &EntryRepositoryImpl{
+		entries:   make(map[int]*domain.Entry),
+		redirects: make(map[string]int),
+	}
+}
+
+// copyEntry returns a copy of the entry, avoid to share pointers with callers
+func copyEntry(e *domain.Entry) *domain.Entry {
+	c := *e
+	c.Tags = append([]string{}, e.Tags...)
+	c.Assets = copyAssets(e.Assets)
+	return &c
+}
+
+// copyAssets returns a copy of the mapping of the links to the assets
+func copyAssets(assets map[string]string) map[string]string {
+	c := make(map[string]string, len(assets))
+	for link, hash := range assets {
+		c[link] = hash
+	}
+	return c
+}
+
+// sortedTags returns normalized tags with the name order
+func sortedTags(tags []string) []string {
+	res := domain.NormalizeTags(tags)
+	sort.Strings(res)
+	return res
+}
+
+// sortedIDs returns all ids with ascending order, must be called with lock
+func (r *EntryRepositoryImpl) sortedIDs() []int {
+	ids := make([]int, 0, len(r.entries))
+	for id := range r.entries {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Get return a entry matched by 'id'
+func (r *EntryRepositoryImpl) Get(id int) (*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[id]
+	if !ok {
+		return &domain.Entry{}, sql.ErrNoRows
+	}
+	return copyEntry(e), nil
+}
+
+// GetByTitle return a entry matched by 'title'
+func (r *EntryRepositoryImpl) GetByTitle(title string) (*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range r.sortedIDs() {
+		if e := r.entries[id]; e.Title == title {
+			return copyEntry(e), nil
+		}
+	}
+	return &domain.Entry{}, sql.ErrNoRows
+}
+
+// GetBySlug return a entry matched by the current 'slug'
+func (r *EntryRepositoryImpl) GetBySlug(slug string) (*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if e := r.findBySlug(slug); e != nil {
+		return copyEntry(e), nil
+	}
+	return &domain.Entry{}, sql.ErrNoRows
+}
+
+// GetRedirectedSlug returns the current slug of the entry which had the 'slug' before
+func (r *EntryRepositoryImpl) GetRedirectedSlug(slug string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.redirects[slug]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	e, ok := r.entries[id]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return e.Slug, nil
+}
+
+// findBySlug must be called with lock
+func (r *EntryRepositoryImpl) findBySlug(slug string) *domain.Entry {
+	if len(slug) == 0 {
+		return nil
+	}
+	for _, e := range r.entries {
+		if e.Slug == slug {
+			return e
+		}
+	}
+	return nil
+}
+
+// GetIDs return the entry id list matched by the filter
+func (r *EntryRepositoryImpl) GetIDs(f repository.EntryFilter) ([]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]int, 0, len(r.entries))
+	for _, id := range r.sortedIDs() {
+		if f.Match(r.entries[id]) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// GetTitles returns entries with contain id and title matched by the filter
+func (r *EntryRepositoryImpl) GetTitles(f repository.EntryFilter, start, n int) ([]*domain.Entry, error) {
+	if start < 0 {
+		return nil, errors.New("invalid start index")
+	}
+	if n < 1 {
+		// default
+		n = 100
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*domain.Entry, 0)
+	for _, id := range r.sortedIDs() {
+		if id < start {
+			continue
+		}
+		if len(entries) >= n {
+			break
+		}
+		e := r.entries[id]
+		if !f.Match(e) {
+			continue
+		}
+		entries = append(entries, &domain.Entry{ID: e.ID, Title: e.Title, CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt})
+	}
+	return entries, nil
+}
+
+// GetTitlePage returns the entries with the id, title and timestamps in the page
+func (r *EntryRepositoryImpl) GetTitlePage(f repository.EntryFilter, q repository.EntryPageQuery) ([]*domain.Entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sorted := make([]*domain.Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		if f.Match(e) {
+			sorted = append(sorted, e)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return q.Sort.Less(sorted[i], sorted[j]) })
+
+	start, end := 0, len(sorted)
+	if q.After != nil {
+		after := q.After.Entry()
+		start = sort.Search(len(sorted), func(i int) bool { return q.Sort.Less(after, sorted[i]) })
+	}
+	if q.Before != nil {
+		before := q.Before.Entry()
+		end = sort.Search(len(sorted), func(i int) bool { return !q.Sort.Less(sorted[i], before) })
+	}
+	if start > end {
+		start = end
+	}
+	if q.Limit > 0 && end-start > q.Limit {
+		// the page before the cursor is the nearest entries to the cursor
+		if q.Before != nil && q.After == nil {
+			start = end - q.Limit
+		} else {
+			end = start + q.Limit
+		}
+	}
+
+	entries := make([]*domain.Entry, 0, end-start)
+	for _, e := range sorted[start:end] {
+		entries = append(entries, &domain.Entry{ID: e.ID, Title: e.Title, CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt})
+	}
+	return entries, nil
+}
+
+// Count returns the number of the entries matched by the filter
+func (r *EntryRepositoryImpl) Count(f repository.EntryFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	n := 0
+	for _, e := range r.entries {
+		if f.Match(e) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// GetTags returns tags with the number of the entries matched by the filter
+func (r *EntryRepositoryImpl) GetTags(f repository.EntryFilter) ([]*domain.Tag, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, e := range r.entries {
+		if !f.Match(e) {
+			continue
+		}
+		for _, t := range e.Tags {
+			counts[t]++
+		}
+	}
+	tags := make([]*domain.Tag, 0, len(counts))
+	for name, cnt := range counts {
+		tags = append(tags, &domain.Tag{Name: name, Count: cnt})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+// GetArchives returns the number of the entries in each month ordered by the newest
+func (r *EntryRepositoryImpl) GetArchives(f repository.EntryFilter) ([]*domain.Archive, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	times := make([]time.Time, 0)
+	for _, e := range r.entries {
+		if f.Match(e) {
+			times = append(times, e.CreatedAt)
+		}
+	}
+	return domain.NewArchives(times), nil
+}
+
+// GetRecent returns the entries matched by the filter in order of newest
+func (r *EntryRepositoryImpl) GetRecent(f repository.EntryFilter, n int) ([]*domain.Entry, error) {
+	if n < 1 {
+		// default
+		n = 100
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*domain.Entry, 0)
+	for _, e := range r.entries {
+		if f.Match(e) {
+			entries = append(entries, copyEntry(e))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries, nil
+}
+
+// Save saves entry data
+func (r *EntryRepositoryImpl) Save(e *domain.Entry) (int, error) {
+	sizeTitle := len(e.Title)
+	sizeContent := len(e.Content)
+	if sizeTitle == 0 || sizeContent == 0 {
+		return 0, config.ErrEmptyEntry
+	}
+	if sizeTitle > config.MaxTitleBytes || sizeContent > config.MaxContentBytes ||
+		len(e.Source) > config.MaxContentBytes ||
+		len(e.Slug) > config.MaxSlugBytes || len(e.Summary) > config.MaxSummaryBytes {
+		return 0, config.ErrEntrySizeLimitExceeded
+	}
+	for _, t := range e.Tags {
+		if len(t) > config.MaxTagBytes {
+			return 0, config.ErrEntrySizeLimitExceeded
+		}
+	}
+	for link := range e.Assets {
+		if len(link) > config.MaxAssetLinkBytes {
+			return 0, config.ErrEntrySizeLimitExceeded
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.findBySlug(e.Slug) != nil {
+		return 0, errors.New("duplicated slug")
+	}
+	delete(r.redirects, e.Slug)
+	r.lastID++
+	saved := copyEntry(e)
+	saved.ID = r.lastID
+	saved.Version = 1
+	saved.CreatedAt = time.Now()
+	saved.UpdatedAt = saved.CreatedAt
+	saved.Tags = sortedTags(e.Tags)
+	r.entries[saved.ID] = saved
+	r.saveRevision(saved)
+	return saved.ID, nil
+}
+
+// saveRevision records the title, content and source of the entry, must be called with lock
+func (r *EntryRepositoryImpl) saveRevision(e *domain.Entry) {
+	r.lastRevisionID++
+	r.revisions = append(r.revisions, &domain.Revision{
+		ID:        r.lastRevisionID,
+		EntryID:   e.ID,
+		Title:     e.Title,
+		Content:   e.Content,
+		Source:    e.Source,
+		CreatedAt: time.Now(),
+	})
+}
+
+// hasRevision must be called with lock
+func (r *EntryRepositoryImpl) hasRevision(entryID int) bool {
+	for _, rev := range r.revisions {
+		if rev.EntryID == entryID {
+			return true
+		}
+	}
+	return false
+}
+
+// Edit update the contents and metadata of the entry
+// Do nothing when not found the entry as well as the sql implementation
+// The previous slug redirects to the entry when the slug is changed
+func (r *EntryRepositoryImpl) Edit(e *domain.Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saved, ok := r.entries[e.ID]
+	if !ok {
+		return nil
+	}
+	if e.Version != 0 && e.Version != saved.Version {
+		return config.ErrEntryVersionConflict
+	}
+	if other := r.findBySlug(e.Slug); other != nil && other.ID != e.ID {
+		return errors.New("duplicated slug")
+	}
+	if !r.hasRevision(saved.ID) {
+		r.saveRevision(saved)
+	}
+	if saved.Slug != e.Slug {
+		delete(r.redirects, e.Slug)
+		if len(saved.Slug) != 0 {
+			r.redirects[saved.Slug] = saved.ID
+		}
+	}
+	saved.Title = e.Title
+	saved.Content = e.Content
+	saved.Source = e.Source
+	saved.Status = e.Status
+	saved.Slug = e.Slug
+	saved.Summary = e.Summary
+	saved.PublishAt = e.PublishAt
+	saved.Tags = sortedTags(e.Tags)
+	saved.Assets = copyAssets(e.Assets)
+	saved.UpdatedAt = time.Now()
+	saved.Version++
+	e.Version = saved.Version
+	r.saveRevision(saved)
+	return nil
+}
+
+// Delete delets entry and its revisions and slug redirects when matched id and the version
+func (r *EntryRepositoryImpl) Delete(id, version int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[id]
+	if ok && version != 0 && version != e.Version {
+		return false, config.ErrEntryVersionConflict
+	}
+	delete(r.entries, id)
+
+	revs := make([]*domain.Revision, 0, len(r.revisions))
+	for _, rev := range r.revisions {
+		if rev.EntryID != id {
+			revs = append(revs, rev)
+		}
+	}
+	r.revisions = revs
+	for slug, entryID := range r.redirects {
+		if entryID == id {
+			delete(r.redirects, slug)
+		}
+	}
+	return ok, nil
+}
+
+// PublishScheduled changes the scheduled entries to public when the publish date has come
+func (r *EntryRepositoryImpl) PublishScheduled(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cnt := 0
+	for _, e := range r.entries {
+		if e.IsScheduled() && e.PublishAt != nil && !e.PublishAt.After(now) {
+			e.Status = domain.EntryStatusPublic
+			e.UpdatedAt = now
+			e.Version++
+			cnt++
+		}
+	}
+	return cnt, nil
+}
+
+// GetRevisions returns revisions of the entry without content, in order of oldest
+func (r *EntryRepositoryImpl) GetRevisions(entryID int) ([]*domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	revs := make([]*domain.Revision, 0)
+	for _, rev := range r.revisions {
+		if rev.EntryID == entryID {
+			c := *rev
+			c.Content = ""
+			c.Source = ""
+			revs = append(revs, &c)
+		}
+	}
+	return revs, nil
+}
+
+// GetRevision returns a revision of the entry
+func (r *EntryRepositoryImpl) GetRevision(entryID, revisionID int) (*domain.Revision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rev := range r.revisions {
+		if rev.ID == revisionID && rev.EntryID == entryID {
+			c := *rev
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFoundRevision
+}
+
+// resetTagNames replaces the tag names by id used by resetTags
+func (r *EntryRepositoryImpl) resetTagNames(names map[int]string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fixtureTagNames = names
+}
+
+// resetTags replaces the tags of all entries by the tag ids without recording revisions
+func (r *EntryRepositoryImpl) resetTags(tagIDs map[int][]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, e := range r.entries {
+		tags := make([]string, 0, len(tagIDs[id]))
+		for _, tagID := range tagIDs[id] {
+			tags = append(tags, r.fixtureTagNames[tagID])
+		}
+		e.Tags = sortedTags(tags)
+	}
+}
+
+// Reset replaces all entries with the given entries
+func (r *EntryRepositoryImpl) Reset(entries ...*domain.Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = make(map[int]*domain.Entry)
+	r.lastID = 0
+	r.revisions = nil
+	r.lastRevisionID = 0
+	r.redirects = make(map[string]int)
+	for _, e := range entries {
+		r.entries[e.ID] = copyEntry(e)
+		if e.ID > r.lastID {
+			r.lastID = e.ID
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// EntryPathRepositoryImpl implements the EntryPathRepository on memory
+type EntryPathRepositoryImpl struct {
+	mu    sync.RWMutex
+	paths map[string]int
+}
+
+// NewEntryPathRepository returns initialized EntryPathRepositoryImpl
+func NewEntryPathRepository() repository.EntryPathRepository {
+	return &EntryPathRepositoryImpl{
+		paths: make(map[string]int),
+	}
+}
+
+// GetEntryID returns the entry id matched by the path
+func (r *EntryPathRepositoryImpl) GetEntryID(path string) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.paths[path]
+	if !ok {
+		return 0, domain.ErrNotFoundEntryPath
+	}
+	return id, nil
+}
+
+// Save maps the path to the entry, replaces the entry of the path
+func (r *EntryPathRepositoryImpl) Save(path string, entryID int) error {
+	if len(path) > config.MaxEntryPathBytes {
+		return config.ErrEntrySizeLimitExceeded
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paths[path] = entryID
+	return nil
+}
+
+// Delete deletes the mapping of the path
+func (r *EntryPathRepositoryImpl) Delete(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.paths, path)
+	return nil
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+func setupRepository(t *testing.T, fixtures ...string) (*EntryRepositoryImpl, *TokenRepositoryImpl) {
+	e := NewEntryRepository()
+	tr := NewTokenRepository()
+	f, err := NewFixture(e, tr, NewUserRepository())
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	for _, file := range fixtures {
+		if err := f.Load(file); err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+	}
+	return e.(*EntryRepositoryImpl), tr.(*TokenRepositoryImpl)
+}
+
+func TestGetEntry(t *testing.T) {
+	repo, _ := setupRepository(t, "testdata/entries.yml")
+
+	cases := []struct {
+		input     int
+		expectID  int
+		expectErr error
+	}{
+		{1, 1, nil},
+		{0, 0, sql.ErrNoRows},
+	}
+	for i, c := range cases {
+		e, err := repo.Get(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if e.ID != c.expectID {
+			t.Errorf("#%d: want id %d, got %d", i, c.expectID, e.ID)
+		}
+	}
+}
+
+func TestGetTitlesEntry(t *testing.T) {
+	cases := []struct {
+		fixtures []string
+		start    int
+		length   int
+		expect   []*domain.Entry
+	}{
+		{
+			[]string{"testdata/entries.yml"},
+			2,
+			2,
+			[]*domain.Entry{
+				&domain.Entry{ID: 2, Title: "foo"},
+			},
+		},
+		{
+			[]string{"testdata/entries.yml"},
+			0,
+			1,
+			[]*domain.Entry{
+				&domain.Entry{ID: 1, Title: "foo"},
+			},
+		},
+		{
+			[]string{"testdata/entries.yml", "testdata/delete_entries.sql"},
+			0,
+			2,
+			[]*domain.Entry{},
+		},
+	}
+	for i, c := range cases {
+		repo, _ := setupRepository(t, c.fixtures...)
+		es, err := repo.GetTitles(repository.EntryFilter{IncludePrivate: true}, c.start, c.length)
+		if err != nil {
+			t.Errorf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(es, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, es)
+		}
+	}
+}
+
+func TestGetTitlePageEntry(t *testing.T) {
+	repo, _ := setupRepository(t, "testdata/page_entries.yml")
+
+	at := func(day int) time.Time {
+		return time.Date(2018, 3, day, 0, 0, 0, 0, time.UTC)
+	}
+	all := repository.EntryFilter{IncludePrivate: true}
+	cases := []struct {
+		filter    repository.EntryFilter
+		query     repository.EntryPageQuery
+		expectIDs []int
+	}{
+		{
+			all,
+			repository.EntryPageQuery{Limit: 2},
+			[]int{1, 2},
+		},
+		{
+			all,
+			repository.EntryPageQuery{After: &repository.EntryCursor{ID: 2, Time: at(2)}, Limit: 2},
+			[]int{3, 4},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Desc: true}, After: &repository.EntryCursor{ID: 3, Time: at(2)}},
+			[]int{2, 1},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Before: &repository.EntryCursor{ID: 4, Time: at(4)}, Limit: 2},
+			[]int{2, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Desc: true}, Before: &repository.EntryCursor{ID: 2, Time: at(2)}, Limit: 2},
+			[]int{4, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByTitle}, After: &repository.EntryCursor{ID: 1, Title: "c"}},
+			[]int{5, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByTitle, Desc: true}, Before: &repository.EntryCursor{ID: 1, Title: "c"}},
+			[]int{3, 5},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByUpdatedAt}, After: &repository.EntryCursor{ID: 4, Time: at(7)}, Limit: 2},
+			[]int{5, 3},
+		},
+		{
+			repository.EntryFilter{},
+			repository.EntryPageQuery{After: &repository.EntryCursor{ID: 3, Time: at(2)}},
+			[]int{4},
+		},
+	}
+	for i, c := range cases {
+		es, err := repo.GetTitlePage(c.filter, c.query)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		ids := make([]int, 0)
+		for _, e := range es {
+			ids = append(ids, e.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+	}
+
+	countCases := []struct {
+		filter repository.EntryFilter
+		expect int
+	}{
+		{all, 5},
+		{repository.EntryFilter{}, 4},
+	}
+	for i, c := range countCases {
+		n, err := repo.Count(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expect {
+			t.Errorf("#%d: want %d, got %d", i, c.expect, n)
+		}
+	}
+}
+
+func TestGetArchivesEntry(t *testing.T) {
+	repo, _ := setupRepository(t, "testdata/page_entries.yml")
+
+	since := time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		filter    repository.EntryFilter
+		expectIDs []int
+		expect    []*domain.Archive
+	}{
+		{
+			repository.EntryFilter{IncludePrivate: true},
+			[]int{1, 2, 3, 4, 5},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 5}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Since: &since, Until: &until},
+			[]int{2, 3, 4},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 3}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Until: &since},
+			[]int{1},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 1}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Since: &until},
+			[]int{5},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 1}},
+		},
+	}
+	for i, c := range cases {
+		ids, err := repo.GetIDs(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+		archives, err := repo.GetArchives(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(archives, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, archives)
+		}
+	}
+}
+
+func TestEditEntryVersion(t *testing.T) {
+	repo, _ := setupRepository(t, "testdata/entries.yml")
+
+	cases := []struct {
+		version       int
+		expectVersion int
+		expectErr     error
+	}{
+		{1, 2, nil},
+		// the version has been changed by the previous edit
+		{1, 1, config.ErrEntryVersionConflict},
+		{0, 3, nil},
+	}
+	for i, c := range cases {
+		e, err := repo.Get(1)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		e.Version = c.version
+		if err := repo.Edit(e); err != c.expectErr {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if e.Version != c.expectVersion {
+			t.Errorf("#%d: want version %d, got %d", i, c.expectVersion, e.Version)
+		}
+	}
+
+	if _, err := repo.Delete(1, 2); err != config.ErrEntryVersionConflict {
+		t.Errorf("want error %#v, got %#v", config.ErrEntryVersionConflict, err)
+	}
+	if ok, err := repo.Delete(1, 3); err != nil || !ok {
+		t.Errorf("want deleted, got %v and %#v", ok, err)
+	}
+}
+
+func TestSaveEntry(t *testing.T) {
+	repo, _ := setupRepository(t, "testdata/entries.yml")
+
+	id, err := repo.Save(&domain.Entry{Title: "title", Content: "content"})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if id != 3 {
+		t.Errorf("want id 3, got %d", id)
+	}
+}
@@ -0,0 +1,226 @@
+package memory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Fixture loads fixture files into the memory repositories
+// Supports the same fixture formats as go-fixture:
+//   - yml: replaces all records of the table
+//   - sql: only TRUNCATE or DELETE statements
+type Fixture struct {
+	entry *EntryRepositoryImpl
+	token *TokenRepositoryImpl
+	user  *UserRepositoryImpl
+}
+
+// NewFixture returns initialized Fixture
+// Repositories must be created by this package
+func NewFixture(e repository.EntryRepository, t repository.TokenRepository, u repository.UserRepository) (*Fixture, error) {
+	entry, ok := e.(*EntryRepositoryImpl)
+	if !ok {
+		return nil, errors.Errorf("unsupported entry repository: %T", e)
+	}
+	token, ok := t.(*TokenRepositoryImpl)
+	if !ok {
+		return nil, errors.Errorf("unsupported token repository: %T", t)
+	}
+	user, ok := u.(*UserRepositoryImpl)
+	if !ok {
+		return nil, errors.Errorf("unsupported user repository: %T", u)
+	}
+	return &Fixture{
+		entry: entry,
+		token: token,
+		user:  user,
+	}, nil
+}
+
+// Load loads the fixture file
+func (f *Fixture) Load(file string) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	switch filepath.Ext(file) {
+	case ".sql":
+		return f.loadSQL(data)
+	case ".yml", ".yaml":
+		return f.loadYAML(data)
+	default:
+		return errors.Errorf("unsupported fixture file: %s", file)
+	}
+}
+
+var clearTableRegexp = regexp.MustCompile("(?i)(?:TRUNCATE\\s+(?:TABLE\\s+)?|DELETE\\s+FROM\\s+)`?(\\w+)`?")
+
+func (f *Fixture) loadSQL(data []byte) error {
+	for _, m := range clearTableRegexp.FindAllStringSubmatch(string(data), -1) {
+		if err := f.reset(m[1], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Fixture) loadYAML(data []byte) error {
+	model := struct {
+		Table  string        `yaml:"table"`
+		Record []fixtureItem `yaml:"record"`
+	}{}
+	err := yaml.Unmarshal(data, &model)
+	if err != nil {
+		return err
+	}
+	return f.reset(model.Table, model.Record)
+}
+
+func (f *Fixture) reset(table string, records []fixtureItem) error {
+	switch table {
+	case "entries":
+		entries := make([]*domain.Entry, 0, len(records))
+		for _, r := range records {
+			publishAt, err := r.time("publish_at")
+			if err != nil {
+				return err
+			}
+			createdAt, err := r.time("created_at")
+			if err != nil {
+				return err
+			}
+			updatedAt, err := r.time("updated_at")
+			if err != nil {
+				return err
+			}
+			entries = append(entries, &domain.Entry{
+				ID:        r.int("id"),
+				Title:     r.string("title"),
+				Content:   r.string("content"),
+				Source:    r.string("source"),
+				Status:    domain.EntryStatus(r.int("status")),
+				Slug:      r.string("slug"),
+				Summary:   r.string("summary"),
+				PublishAt: publishAt,
+				AuthorID:  r.int("author_id"),
+				Version:   r.intOr("version", 1),
+				CreatedAt: timeOrZero(createdAt),
+				UpdatedAt: timeOrZero(updatedAt),
+			})
+		}
+		f.entry.Reset(entries...)
+	case "tags":
+		names := make(map[int]string)
+		for _, r := range records {
+			names[r.int("id")] = r.string("name")
+		}
+		f.entry.resetTagNames(names)
+	case "entry_tags":
+		tags := make(map[int][]int)
+		for _, r := range records {
+			id := r.int("entry_id")
+			tags[id] = append(tags[id], r.int("tag_id"))
+		}
+		f.entry.resetTags(tags)
+	case "tokens":
+		tokens := make([]*domain.Token, 0, len(records))
+		for _, r := range records {
+			createdAt, err := r.time("created_at")
+			if err != nil {
+				return err
+			}
+			lastUsedAt, err := r.time("last_used_at")
+			if err != nil {
+				return err
+			}
+			expiresAt, err := r.time("expires_at")
+			if err != nil {
+				return err
+			}
+			tokens = append(tokens, &domain.Token{
+				ID:         r.int("id"),
+				Hash:       r.string("value"),
+				Prefix:     r.string("prefix"),
+				UserID:     r.int("user_id"),
+				Label:      r.string("label"),
+				CreatedAt:  timeOrZero(createdAt),
+				LastUsedAt: lastUsedAt,
+				ExpiresAt:  expiresAt,
+			})
+		}
+		f.token.Reset(tokens...)
+	case "users":
+		users := make([]*domain.User, 0, len(records))
+		for _, r := range records {
+			users = append(users, &domain.User{
+				ID:   r.int("id"),
+				Name: r.string("name"),
+				Role: domain.Role(r.string("role")),
+			})
+		}
+		f.user.Reset(users...)
+	default:
+		return errors.Errorf("unsupported fixture table: %s", table)
+	}
+	return nil
+}
+
+// fixtureItem represent a record of the fixture
+type fixtureItem map[string]interface{}
+
+func (i fixtureItem) int(key string) int {
+	v, _ := i[key].(int)
+	return v
+}
+
+// intOr returns the default value when the key doesn't exist, as well as the default of the column
+func (i fixtureItem) intOr(key string, def int) int {
+	if _, ok := i[key]; !ok {
+		return def
+	}
+	return i.int(key)
+}
+
+func (i fixtureItem) string(key string) string {
+	v, ok := i[key]
+	if !ok || v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// fixtureTimeLayout is the datetime format of the fixture as well as the mysql
+const fixtureTimeLayout = "2006-01-02 15:04:05"
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func (i fixtureItem) time(key string) (*time.Time, error) {
+	switch v := i[key].(type) {
+	case nil:
+		return nil, nil
+	case time.Time:
+		return &v, nil
+	case string:
+		t, err := time.Parse(fixtureTimeLayout, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", key)
+		}
+		return &t, nil
+	default:
+		return nil, errors.Errorf("invalid %s: %v", key, v)
+	}
+}
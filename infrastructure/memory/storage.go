@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// AssetStorageImpl implements the AssetStorage on memory
+type AssetStorageImpl struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewAssetStorage returns initialized AssetStorageImpl
+func NewAssetStorage() repository.AssetStorage {
+	return &AssetStorageImpl{
+		files: make(map[string][]byte),
+	}
+}
+
+// Put stores the file, overwrites the file of the same hash
+func (s *AssetStorageImpl) Put(hash string, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[hash] = data
+	return nil
+}
+
+// Open returns the file matched by the hash
+func (s *AssetStorageImpl) Open(hash string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.files[hash]
+	if !ok {
+		return nil, domain.ErrNotFoundAsset
+	}
+	return ioutil.NopCloser(bytes.NewReader(data)), nil
+}
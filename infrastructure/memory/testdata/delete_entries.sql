@@ -0,0 +1 @@
+truncate table entries;
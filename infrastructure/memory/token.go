@@ -0,0 +1,156 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// TokenRepositoryImpl implements the TokenRepository on memory
+type TokenRepositoryImpl struct {
+	mu     sync.RWMutex
+	tokens map[int]*domain.Token
+	lastID int
+}
+
+// NewTokenRepository returns initialized TokenRepositoryImpl
+func NewTokenRepository() repository.TokenRepository {
+	return &TokenRepositoryImpl{
+		tokens: make(map[int]*domain.Token),
+	}
+}
+
+// Get return a token matched by 'id'
+func (r *TokenRepositoryImpl) Get(id int) (*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tokens[id]
+	if !ok {
+		return nil, domain.ErrNotFoundToken
+	}
+	c := *t
+	return &c, nil
+}
+
+// FindByPrefix returns the tokens matched by the public prefix of the value
+func (r *TokenRepositoryImpl) FindByPrefix(prefix string) ([]*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := []*domain.Token{}
+	for _, t := range r.tokens {
+		if t.Prefix == prefix {
+			c := *t
+			tokens = append(tokens, &c)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// FindByPlainValue returns the token saved the plain value before hashing
+func (r *TokenRepositoryImpl) FindByPlainValue(value string) (*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.findByHash(value)
+}
+
+// findByHash must be called with lock
+func (r *TokenRepositoryImpl) findByHash(value string) (*domain.Token, error) {
+	for _, t := range r.tokens {
+		if t.Hash == value {
+			c := *t
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFoundToken
+}
+
+// List returns all tokens ordered by the id
+func (r *TokenRepositoryImpl) List() ([]*domain.Token, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]*domain.Token, 0, len(r.tokens))
+	for _, t := range r.tokens {
+		c := *t
+		tokens = append(tokens, &c)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+	return tokens, nil
+}
+
+// Save saves token data
+func (r *TokenRepositoryImpl) Save(m *domain.Token) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.findByHash(m.Hash); err == nil {
+		return 0, domain.ErrTokenAlreadyExistSameValue
+	}
+
+	r.lastID++
+	saved := *m
+	saved.ID = r.lastID
+	saved.Value = ""
+	saved.CreatedAt = time.Now()
+	saved.LastUsedAt = nil
+	r.tokens[saved.ID] = &saved
+	return saved.ID, nil
+}
+
+// Update update the hash of the value, the label and the expiry
+func (r *TokenRepositoryImpl) Update(m *domain.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if saved, ok := r.tokens[m.ID]; ok {
+		saved.Hash = m.Hash
+		saved.Prefix = m.Prefix
+		saved.Label = m.Label
+		saved.ExpiresAt = m.ExpiresAt
+	}
+	return nil
+}
+
+// Touch records the time the token is used at
+func (r *TokenRepositoryImpl) Touch(id int, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if saved, ok := r.tokens[id]; ok {
+		saved.LastUsedAt = &at
+	}
+	return nil
+}
+
+// Delete deletes token when matched id
+func (r *TokenRepositoryImpl) Delete(id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.tokens[id]
+	delete(r.tokens, id)
+	return ok, nil
+}
+
+// Reset replaces all tokens with the given tokens
+func (r *TokenRepositoryImpl) Reset(tokens ...*domain.Token) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens = make(map[int]*domain.Token)
+	r.lastID = 0
+	for _, t := range tokens {
+		c := *t
+		r.tokens[t.ID] = &c
+		if t.ID > r.lastID {
+			r.lastID = t.ID
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestSaveToken(t *testing.T) {
+	_, repo := setupRepository(t, "testdata/tokens.yml")
+
+	cases := []struct {
+		input     *domain.Token
+		expectErr error
+	}{
+		{&domain.Token{Value: "test"}, nil},
+		{&domain.Token{Value: "foo"}, domain.ErrTokenAlreadyExistSameValue},
+	}
+	for i, c := range cases {
+		_, err := repo.Save(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// UserRepositoryImpl implements the UserRepository on memory
+type UserRepositoryImpl struct {
+	mu     sync.RWMutex
+	users  map[int]*domain.User
+	lastID int
+}
+
+// NewUserRepository returns initialized UserRepositoryImpl
+func NewUserRepository() repository.UserRepository {
+	return &UserRepositoryImpl{
+		users: make(map[int]*domain.User),
+	}
+}
+
+// Get returns a user matched by the id
+func (r *UserRepositoryImpl) Get(id int) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, domain.ErrNotFoundUser
+	}
+	c := *u
+	return &c, nil
+}
+
+// FindByName returns a user matched by the name
+func (r *UserRepositoryImpl) FindByName(name string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.findByName(name)
+}
+
+// findByName must be called with lock
+func (r *UserRepositoryImpl) findByName(name string) (*domain.User, error) {
+	for _, u := range r.users {
+		if u.Name == name {
+			c := *u
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFoundUser
+}
+
+// List returns all users ordered by the id
+func (r *UserRepositoryImpl) List() ([]*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	users := make([]*domain.User, 0, len(r.users))
+	for _, u := range r.users {
+		c := *u
+		users = append(users, &c)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// Save saves the user
+func (r *UserRepositoryImpl) Save(m *domain.User) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.findByName(m.Name); err == nil {
+		return 0, domain.ErrUserAlreadyExistSameName
+	}
+
+	r.lastID++
+	saved := *m
+	saved.ID = r.lastID
+	r.users[saved.ID] = &saved
+	return saved.ID, nil
+}
+
+// Update updates the name and the role
+func (r *UserRepositoryImpl) Update(m *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if other, err := r.findByName(m.Name); err == nil && other.ID != m.ID {
+		return domain.ErrUserAlreadyExistSameName
+	}
+	if saved, ok := r.users[m.ID]; ok {
+		saved.Name = m.Name
+		saved.Role = m.Role
+	}
+	return nil
+}
+
+// Delete deletes the user when matched id
+// The tokens of the deleted user are no longer authenticated
+func (r *UserRepositoryImpl) Delete(id int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.users[id]
+	delete(r.users, id)
+	return ok, nil
+}
+
+// Reset replaces all users with the given users
+func (r *UserRepositoryImpl) Reset(users ...*domain.User) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users = make(map[int]*domain.User)
+	r.lastID = 0
+	for _, u := range users {
+		c := *u
+		r.users[u.ID] = &c
+		if u.ID > r.lastID {
+			r.lastID = u.ID
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package persistence
+
+import (
+	"database/sql"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/utils"
+)
+
+// AssetRepositoryImpl implements the AssetRepository
+type AssetRepositoryImpl struct {
+	*SQLRepositoryAdapter
+}
+
+// NewAssetRepository returns initialized AssetRepositoryImpl
+func NewAssetRepository() (repository.AssetRepository, error) {
+	db, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AssetRepositoryImpl{
+		&SQLRepositoryAdapter{Conn: db},
+	}, nil
+}
+
+// Get returns an asset matched by the hash
+func (r *AssetRepositoryImpl) Get(hash string) (*domain.Asset, error) {
+	row, err := r.queryRow("select id, hash, name, content_type, size, created_at from assets where hash=?", hash)
+	if err != nil {
+		return nil, err
+	}
+	m := &domain.Asset{}
+	err = row.Scan(&m.ID, &m.Hash, &m.Name, &m.ContentType, &m.Size, &m.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFoundAsset
+	}
+	return m, err
+}
+
+// Save saves the asset metadata
+func (r *AssetRepositoryImpl) Save(m *domain.Asset) (int, error) {
+	stmt, err := r.Conn.Prepare("insert into assets (hash, name, content_type, size) values(?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(m.Hash, m.Name, m.ContentType, m.Size)
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return int(id), nil
+}
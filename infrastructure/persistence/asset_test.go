@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestGetAsset(t *testing.T) {
+	repo, err := NewAssetRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/assets.yml")
+
+	cases := []struct {
+		input     string
+		expectID  int
+		expectErr error
+	}{
+		{"2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", 1, nil},
+		{"unknown", 0, domain.ErrNotFoundAsset},
+	}
+	for i, c := range cases {
+		asset, err := repo.Get(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if asset.ID != c.expectID || asset.Name != "foo.png" || asset.ContentType != "image/png" || asset.Size != 3 {
+			t.Errorf("#%d: want id %d, got %#v", i, c.expectID, asset)
+		}
+	}
+}
+
+func TestSaveAsset(t *testing.T) {
+	repo, err := NewAssetRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/assets.yml")
+
+	input := &domain.Asset{
+		Hash:        "fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9",
+		Name:        "bar.gif",
+		ContentType: "image/gif",
+		Size:        3,
+	}
+	id, err := repo.Save(input)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	asset, err := repo.Get(input.Hash)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if asset.ID != id || asset.Name != input.Name || asset.CreatedAt.IsZero() {
+		t.Errorf("want id %d and name %s, got %#v", id, input.Name, asset)
+	}
+
+	if _, err := repo.Save(input); err == nil {
+		t.Errorf("want error for the duplicated hash, got nil")
+	}
+}
@@ -0,0 +1,102 @@
+package persistence
+
+import (
+	"strings"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/utils"
+)
+
+// AuditRepositoryImpl implements the AuditRepository
+type AuditRepositoryImpl struct {
+	*SQLRepositoryAdapter
+}
+
+// NewAuditRepository returns initialized AuditRepositoryImpl
+func NewAuditRepository() (repository.AuditRepository, error) {
+	db, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditRepositoryImpl{
+		&SQLRepositoryAdapter{Conn: db},
+	}, nil
+}
+
+// auditColumns is the column list corresponding to mapToEntity
+const auditColumns = "id, action, entry_id, user_id, token_id, remote_addr, before_hash, after_hash, created_at"
+
+func (r *AuditRepositoryImpl) mapToEntity(row scanner) (*domain.AuditLog, error) {
+	m := &domain.AuditLog{}
+	err := row.Scan(&m.ID, &m.Action, &m.EntryID, &m.UserID, &m.TokenID, &m.RemoteAddr, &m.BeforeHash, &m.AfterHash, &m.CreatedAt)
+	return m, err
+}
+
+// Save saves the audit log
+func (r *AuditRepositoryImpl) Save(m *domain.AuditLog) (int, error) {
+	stmt, err := r.Conn.Prepare("insert into audit_logs (action, entry_id, user_id, token_id, remote_addr, before_hash, after_hash, created_at) values(?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(string(m.Action), m.EntryID, m.UserID, m.TokenID, m.RemoteAddr, m.BeforeHash, m.AfterHash, m.CreatedAt.UTC())
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return int(id), nil
+}
+
+// Find returns the audit logs matched by the filter ordered by the newest
+func (r *AuditRepositoryImpl) Find(f repository.AuditFilter) ([]*domain.AuditLog, error) {
+	conds := []string{"1=1"}
+	args := []interface{}{}
+	if len(f.Action) != 0 {
+		conds = append(conds, "action=?")
+		args = append(args, string(f.Action))
+	}
+	if f.EntryID != 0 {
+		conds = append(conds, "entry_id=?")
+		args = append(args, f.EntryID)
+	}
+	if f.UserID != 0 {
+		conds = append(conds, "user_id=?")
+		args = append(args, f.UserID)
+	}
+	if f.TokenID != 0 {
+		conds = append(conds, "token_id=?")
+		args = append(args, f.TokenID)
+	}
+	if f.Since != nil {
+		conds = append(conds, "created_at>=?")
+		args = append(args, utcTime(f.Since))
+	}
+	if f.Until != nil {
+		conds = append(conds, "created_at<?")
+		args = append(args, utcTime(f.Until))
+	}
+	q := "select " + auditColumns + " from audit_logs where " + strings.Join(conds, " and ") + " order by id desc"
+	if f.Limit > 0 {
+		q += " limit ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := r.query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logs := []*domain.AuditLog{}
+	for rows.Next() {
+		l, err := r.mapToEntity(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,90 @@
+package persistence
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestFindAudit(t *testing.T) {
+	repo, err := NewAuditRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/audit_logs.yml")
+
+	since := time.Date(2018, 3, 1, 12, 0, 0, 0, time.UTC)
+	until := time.Date(2018, 3, 2, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		input     repository.AuditFilter
+		expectIDs []int
+	}{
+		{repository.AuditFilter{}, []int{3, 2, 1}},
+		{repository.AuditFilter{Limit: 2}, []int{3, 2}},
+		{repository.AuditFilter{EntryID: 1}, []int{2, 1}},
+		{repository.AuditFilter{UserID: 1}, []int{3, 1}},
+		{repository.AuditFilter{TokenID: 4}, []int{2}},
+		{repository.AuditFilter{Action: domain.AuditActionDelete}, []int{3}},
+		{repository.AuditFilter{Since: &since}, []int{3, 2}},
+		{repository.AuditFilter{Since: &since, Until: &until}, []int{2}},
+		{repository.AuditFilter{EntryID: 2, Action: domain.AuditActionEdit}, []int{}},
+	}
+	for i, c := range cases {
+		logs, err := repo.Find(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		ids := []int{}
+		for _, l := range logs {
+			ids = append(ids, l.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want %v, got %v", i, c.expectIDs, ids)
+		}
+	}
+}
+
+func TestSaveAudit(t *testing.T) {
+	repo, err := NewAuditRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/audit_logs.yml")
+
+	input := &domain.AuditLog{
+		Action:     domain.AuditActionRevert,
+		EntryID:    1,
+		UserID:     2,
+		TokenID:    4,
+		RemoteAddr: "192.0.2.1",
+		BeforeHash: "bbb",
+		AfterHash:  "aaa",
+		CreatedAt:  time.Date(2018, 3, 4, 10, 0, 0, 0, time.UTC),
+	}
+	id, err := repo.Save(input)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	logs, err := repo.Find(repository.AuditFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("want 1 log, got %d", len(logs))
+	}
+	saved := logs[0]
+	if !saved.CreatedAt.Equal(input.CreatedAt) {
+		t.Errorf("want created_at %v, got %v", input.CreatedAt, saved.CreatedAt)
+	}
+	expect := *input
+	expect.ID = id
+	expect.CreatedAt = saved.CreatedAt
+	if !reflect.DeepEqual(saved, &expect) {
+		t.Errorf("want %#v, got %#v", &expect, saved)
+	}
+}
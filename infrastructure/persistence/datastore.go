@@ -2,11 +2,15 @@ package persistence
 
 import (
 	"database/sql"
+	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql" // mysql driver
+	_ "github.com/mattn/go-sqlite3"    // sqlite3 driver
 )
 
 // SQLRepositoryAdapter provides accessors to the RDB
+// Queries are written to be compatible with both mysql and sqlite3
 type SQLRepositoryAdapter struct {
 	Conn *sql.DB
 }
@@ -30,3 +34,41 @@ func (a *SQLRepositoryAdapter) queryRow(q string, args ...interface{}) (*sql.Row
 
 	return stmt.QueryRow(args...), nil
 }
+
+// transaction runs fn in the transaction
+// Rollbacks when fn returns an error, otherwise commits
+func (a *SQLRepositoryAdapter) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := a.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// nullString returns NULL for the empty string, used by nullable unique columns
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: len(s) != 0}
+}
+
+// utcTime returns the time in UTC to compare the stored times in any driver
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// datetime returns the literal of the time in UTC compared with the timestamps set by the database in any driver
+func datetime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// placeholders returns n placeholders joined by comma for the "in" clause
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
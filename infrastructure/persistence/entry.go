@@ -3,6 +3,8 @@ package persistence
 import (
 	"database/sql"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
@@ -17,7 +19,7 @@ type EntryRepositoryImpl struct {
 
 // NewEntryRepository returns initialized Datastore
 func NewEntryRepository() (repository.EntryRepository, error) {
-	db, err := utils.ConnectMySQL()
+	db, err := utils.ConnectDB()
 	if err != nil {
 		return nil, err
 	}
@@ -27,36 +29,114 @@ func NewEntryRepository() (repository.EntryRepository, error) {
 	}, nil
 }
 
-func (r *EntryRepositoryImpl) mapToEntity(row *sql.Row) (*domain.Entry, error) {
+// entryColumns is the column list corresponding to mapToEntity
+const entryColumns = "id, title, content, source, status, slug, summary, publish_at, author_id, version, created_at, updated_at"
+
+// scanner is implemented by sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *EntryRepositoryImpl) mapToEntity(row scanner) (*domain.Entry, error) {
 	m := &domain.Entry{}
-	err := row.Scan(&m.ID, &m.Title, &m.Content, &m.Status)
+	var source, slug sql.NullString
+	err := row.Scan(&m.ID, &m.Title, &m.Content, &source, &m.Status, &slug, &m.Summary, &m.PublishAt, &m.AuthorID, &m.Version, &m.CreatedAt, &m.UpdatedAt)
+	m.Source = source.String
+	m.Slug = slug.String
 	return m, err
 }
 
 // Get return a entry record matched by 'id'
 func (r *EntryRepositoryImpl) Get(id int) (*domain.Entry, error) {
-	row, err := r.queryRow("select id, title, content, status from entries where id=?", id)
+	return r.getBy("id=?", id)
+}
+
+// GetByTitle return a entry record matched by 'title'
+func (r *EntryRepositoryImpl) GetByTitle(title string) (*domain.Entry, error) {
+	return r.getBy("title=?", title)
+}
+
+// GetBySlug return a entry record matched by the current 'slug'
+func (r *EntryRepositoryImpl) GetBySlug(slug string) (*domain.Entry, error) {
+	return r.getBy("slug=?", slug)
+}
+
+// GetRedirectedSlug returns the current slug of the entry which had the 'slug' before
+func (r *EntryRepositoryImpl) GetRedirectedSlug(slug string) (string, error) {
+	row, err := r.queryRow(`select e.slug from entry_slug_redirects r
+		inner join entries e on e.id = r.entry_id where r.slug=?`, slug)
+	if err != nil {
+		return "", err
+	}
+	var current sql.NullString
+	err = row.Scan(&current)
+	return current.String, err
+}
+
+// getBy return a entry record with tags and assets matched by the condition
+func (r *EntryRepositoryImpl) getBy(cond string, args ...interface{}) (*domain.Entry, error) {
+	row, err := r.queryRow("select "+entryColumns+" from entries where "+cond, args...)
 	if err != nil {
 		return nil, err
 	}
-	return r.mapToEntity(row)
+	e, err := r.mapToEntity(row)
+	if err != nil {
+		return e, err
+	}
+	if e.Tags, err = r.getEntryTags(e.ID); err != nil {
+		return nil, err
+	}
+	e.Assets, err = r.getEntryAssets(e.ID)
+	return e, err
 }
 
-// GetByTitle return a entry record matched by 'title'
-func (r *EntryRepositoryImpl) GetByTitle(title string) (*domain.Entry, error) {
-	row, err := r.queryRow("select id, title, content, status from entries where title=?", title)
+func (r *EntryRepositoryImpl) getEntryTags(id int) ([]string, error) {
+	rows, err := r.query(`select t.name from tags t
+		inner join entry_tags et on t.id = et.tag_id
+		where et.entry_id=? order by t.name`, id)
 	if err != nil {
 		return nil, err
 	}
-	return r.mapToEntity(row)
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
 }
 
-// GetIDs return all entry id list
-func (r *EntryRepositoryImpl) GetIDs() ([]int, error) {
-	rows, err := r.query("select id from entries")
+func (r *EntryRepositoryImpl) getEntryAssets(id int) (map[string]string, error) {
+	rows, err := r.query("select link, hash from entry_assets where entry_id=?", id)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
+
+	assets := make(map[string]string)
+	for rows.Next() {
+		var link, hash string
+		if err := rows.Scan(&link, &hash); err != nil {
+			return nil, err
+		}
+		assets[link] = hash
+	}
+	return assets, nil
+}
+
+// GetIDs return the entry id list matched by the filter
+func (r *EntryRepositoryImpl) GetIDs(f repository.EntryFilter) ([]int, error) {
+	cond, args := filterConditions(f)
+	rows, err := r.query("select id from entries where "+cond+" order by id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	ids := make([]int, 0)
 	for rows.Next() {
 		var i int
@@ -69,8 +149,8 @@ func (r *EntryRepositoryImpl) GetIDs() ([]int, error) {
 	return ids, nil
 }
 
-// GetTitles returns entries with contain id and title
-func (r *EntryRepositoryImpl) GetTitles(start, n int) ([]*domain.Entry, error) {
+// GetTitles returns entries with contain id and title matched by the filter
+func (r *EntryRepositoryImpl) GetTitles(f repository.EntryFilter, start, n int) ([]*domain.Entry, error) {
 	if start < 0 {
 		return nil, errors.New("invalid start index")
 	}
@@ -80,22 +160,228 @@ func (r *EntryRepositoryImpl) GetTitles(start, n int) ([]*domain.Entry, error) {
 	}
 
 	// NOTE: depends on id order
-	rows, err := r.query("select id, title from entries where id >= ? limit ?", start, n)
+	cond, args := filterConditions(f)
+	args = append([]interface{}{start}, args...)
+	args = append(args, n)
+	rows, err := r.query("select id, title, created_at, updated_at from entries where id >= ? and "+cond+" order by id limit ?", args...)
 	if err != nil {
 		return nil, err
 	}
+	return r.scanTitles(rows)
+}
+
+// GetTitlePage returns the entries with the id, title and timestamps in the page
+func (r *EntryRepositoryImpl) GetTitlePage(f repository.EntryFilter, q repository.EntryPageQuery) ([]*domain.Entry, error) {
+	column := sortColumn(q.Sort.Key)
+	cond, args := filterConditions(f)
+	if q.After != nil {
+		c, a := cursorCondition(column, q.Sort.Key, q.After, q.Sort.Desc)
+		cond += " and " + c
+		args = append(args, a...)
+	}
+	if q.Before != nil {
+		c, a := cursorCondition(column, q.Sort.Key, q.Before, !q.Sort.Desc)
+		cond += " and " + c
+		args = append(args, a...)
+	}
+
+	// the page before the cursor is the nearest entries to the cursor, read in the reverse order
+	reverse := q.Before != nil && q.After == nil
+	direction := "asc"
+	if q.Sort.Desc != reverse {
+		direction = "desc"
+	}
+	query := fmt.Sprintf("select id, title, created_at, updated_at from entries where %s order by %s %s, id %s", cond, column, direction, direction)
+	if q.Limit > 0 {
+		query += " limit ?"
+		args = append(args, q.Limit)
+	}
+	rows, err := r.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := r.scanTitles(rows)
+	if err != nil {
+		return nil, err
+	}
+	if reverse {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	return entries, nil
+}
+
+// sortColumn returns the column of the sort key
+func sortColumn(key repository.EntrySortKey) string {
+	switch key {
+	case repository.SortByTitle:
+		return "title"
+	case repository.SortByUpdatedAt:
+		return "updated_at"
+	default:
+		return "created_at"
+	}
+}
+
+// cursorCondition returns the where clause of the entries after the cursor in the direction
+func cursorCondition(column string, key repository.EntrySortKey, c *repository.EntryCursor, desc bool) (string, []interface{}) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	var v interface{} = datetime(c.Time)
+	if key == repository.SortByTitle {
+		v = c.Title
+	}
+	return fmt.Sprintf("(%s %s ? or (%s = ? and id %s ?))", column, op, column, op), []interface{}{v, v, c.ID}
+}
+
+// Count returns the number of the entries matched by the filter
+func (r *EntryRepositoryImpl) Count(f repository.EntryFilter) (int, error) {
+	cond, args := filterConditions(f)
+	row, err := r.queryRow("select count(*) from entries where "+cond, args...)
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	err = row.Scan(&n)
+	return n, err
+}
+
+// GetRecent returns the entries matched by the filter in order of newest
+func (r *EntryRepositoryImpl) GetRecent(f repository.EntryFilter, n int) ([]*domain.Entry, error) {
+	if n < 1 {
+		// default
+		n = 100
+	}
+
+	cond, args := filterConditions(f)
+	args = append(args, n)
+	rows, err := r.query("select "+entryColumns+" from entries where "+cond+" order by created_at desc, id desc limit ?", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	entries := make([]*domain.Entry, 0)
 	for rows.Next() {
-		e := &domain.Entry{}
-		err := rows.Scan(&e.ID, &e.Title)
+		e, err := r.mapToEntity(rows)
 		if err != nil {
 			return nil, err
 		}
 		entries = append(entries, e)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	// load tags after closing rows, sqlite allows only one connection
+	for _, e := range entries {
+		if e.Tags, err = r.getEntryTags(e.ID); err != nil {
+			return nil, err
+		}
+	}
 	return entries, nil
 }
 
+// filterConditions returns the where clause of the entries table and its arguments
+func filterConditions(f repository.EntryFilter) (string, []interface{}) {
+	statuses := f.Statuses()
+	args := make([]interface{}, 0, len(statuses))
+	for _, s := range statuses {
+		args = append(args, int(s))
+	}
+	cond := "status in (" + placeholders(len(statuses)) + ")"
+	if f.Since != nil {
+		cond += " and created_at >= ?"
+		args = append(args, datetime(*f.Since))
+	}
+	if f.Until != nil {
+		cond += " and created_at < ?"
+		args = append(args, datetime(*f.Until))
+	}
+
+	tags := domain.NormalizeTags(f.Tags)
+	if len(tags) == 0 {
+		return cond, args
+	}
+	for _, t := range tags {
+		args = append(args, t)
+	}
+	args = append(args, len(tags))
+	cond += ` and id in (
+		select et.entry_id from entry_tags et
+		inner join tags t on t.id = et.tag_id
+		where t.name in (` + placeholders(len(tags)) + `)
+		group by et.entry_id having count(*) = ?
+	)`
+	return cond, args
+}
+
+func (r *EntryRepositoryImpl) scanTitles(rows *sql.Rows) ([]*domain.Entry, error) {
+	defer rows.Close()
+
+	entries := make([]*domain.Entry, 0)
+	for rows.Next() {
+		e := &domain.Entry{}
+		err := rows.Scan(&e.ID, &e.Title, &e.CreatedAt, &e.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetTags returns tags with the number of the entries matched by the filter
+func (r *EntryRepositoryImpl) GetTags(f repository.EntryFilter) ([]*domain.Tag, error) {
+	cond, args := filterConditions(f)
+	rows, err := r.query(`select t.name, count(*) from tags t
+		inner join entry_tags et on t.id = et.tag_id
+		where et.entry_id in (select id from entries where `+cond+`)
+		group by t.id, t.name order by t.name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]*domain.Tag, 0)
+	for rows.Next() {
+		t := &domain.Tag{}
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, nil
+}
+
+// GetArchives returns the number of the entries in each month ordered by the newest
+func (r *EntryRepositoryImpl) GetArchives(f repository.EntryFilter) ([]*domain.Archive, error) {
+	cond, args := filterConditions(f)
+	rows, err := r.query("select created_at from entries where "+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// the months are counted out of the database to avoid the dialects of the date functions
+	times := make([]time.Time, 0)
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, err
+		}
+		times = append(times, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return domain.NewArchives(times), nil
+}
+
 // Save saves entry data to datastore
 func (r *EntryRepositoryImpl) Save(e *domain.Entry) (int, error) {
 	sizeTitle := len(e.Title)
@@ -103,46 +389,258 @@ func (r *EntryRepositoryImpl) Save(e *domain.Entry) (int, error) {
 	if sizeTitle == 0 || sizeContent == 0 {
 		return 0, config.ErrEmptyEntry
 	}
-	if sizeTitle > config.MaxTitleBytes || sizeContent > config.MaxContentBytes {
+	if sizeTitle > config.MaxTitleBytes || sizeContent > config.MaxContentBytes ||
+		len(e.Source) > config.MaxContentBytes ||
+		len(e.Slug) > config.MaxSlugBytes || len(e.Summary) > config.MaxSummaryBytes {
 		return 0, config.ErrEntrySizeLimitExceeded
 	}
+	for _, t := range e.Tags {
+		if len(t) > config.MaxTagBytes {
+			return 0, config.ErrEntrySizeLimitExceeded
+		}
+	}
+	for link := range e.Assets {
+		if len(link) > config.MaxAssetLinkBytes {
+			return 0, config.ErrEntrySizeLimitExceeded
+		}
+	}
 
-	stmt, err := r.Conn.Prepare("insert into entries (title, content, status) values(?, ?, ?)")
+	var id int
+	err := r.transaction(func(tx *sql.Tx) error {
+		if err := claimSlug(tx, e.Slug); err != nil {
+			return err
+		}
+		res, err := tx.Exec("insert into entries (title, content, source, status, slug, summary, publish_at, author_id) values(?, ?, ?, ?, ?, ?, ?, ?)",
+			e.Title, e.Content, nullString(e.Source), int(e.Status), nullString(e.Slug), e.Summary, utcTime(e.PublishAt), e.AuthorID)
+		if err != nil {
+			return err
+		}
+		lastID, _ := res.LastInsertId()
+		id = int(lastID)
+		if err := saveRevision(tx, id); err != nil {
+			return err
+		}
+		if err := saveTags(tx, id, e.Tags); err != nil {
+			return err
+		}
+		return saveAssets(tx, id, e.Assets)
+	})
 	if err != nil {
 		return 0, err
 	}
-	defer stmt.Close()
+	return id, nil
+}
 
-	res, err := stmt.Exec(e.Title, e.Content, int(e.Status))
+// saveTags replaces the tags of the entry, creates the tag when it doesn't exist yet
+func saveTags(tx *sql.Tx, id int, tags []string) error {
+	_, err := tx.Exec("delete from entry_tags where entry_id=?", id)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	id, _ := res.LastInsertId()
-	return int(id), nil
+	for _, name := range domain.NormalizeTags(tags) {
+		var tagID int64
+		err := tx.QueryRow("select id from tags where name=?", name).Scan(&tagID)
+		if err == sql.ErrNoRows {
+			res, err := tx.Exec("insert into tags (name) values(?)", name)
+			if err != nil {
+				return err
+			}
+			tagID, _ = res.LastInsertId()
+		} else if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec("insert into entry_tags (entry_id, tag_id) values(?, ?)", id, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveAssets replaces the mapping of the links to the assets of the entry
+func saveAssets(tx *sql.Tx, id int, assets map[string]string) error {
+	_, err := tx.Exec("delete from entry_assets where entry_id=?", id)
+	if err != nil {
+		return err
+	}
+	for link, hash := range assets {
+		_, err := tx.Exec("insert into entry_assets (entry_id, link, hash) values(?, ?, ?)", id, link, hash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveRevision records the current title, content and source of the entry as a revision
+func saveRevision(tx *sql.Tx, id int) error {
+	_, err := tx.Exec("insert into entry_revisions (entry_id, title, content, source) select id, title, content, source from entries where id=?", id)
+	return err
 }
 
-// Edit update the title and content of the entry
+// Edit update the contents and metadata of the entry
+// Entries saved before introduced revisions record the previous state as well
+// The previous slug redirects to the entry when the slug is changed
 func (r *EntryRepositoryImpl) Edit(e *domain.Entry) error {
-	stmt, err := r.Conn.Prepare("update entries set title=?, content=? where id=?")
+	return r.transaction(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`insert into entry_revisions (entry_id, title, content, source)
+			select id, title, content, source from entries
+			where id=? and not exists (select 1 from entry_revisions where entry_id=?)`, e.ID, e.ID)
+		if err != nil {
+			return err
+		}
+		if err := saveSlugRedirect(tx, e.ID, e.Slug); err != nil {
+			return err
+		}
+		query := "update entries set title=?, content=?, source=?, status=?, slug=?, summary=?, publish_at=?, version=version+1 where id=?"
+		args := []interface{}{e.Title, e.Content, nullString(e.Source), int(e.Status), nullString(e.Slug), e.Summary, utcTime(e.PublishAt), e.ID}
+		if e.Version != 0 {
+			query += " and version=?"
+			args = append(args, e.Version)
+		}
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		if cnt, _ := res.RowsAffected(); cnt == 0 {
+			if e.Version != 0 {
+				return config.ErrEntryVersionConflict
+			}
+			return nil
+		}
+		if err := tx.QueryRow("select version from entries where id=?", e.ID).Scan(&e.Version); err != nil {
+			return err
+		}
+		if err := saveRevision(tx, e.ID); err != nil {
+			return err
+		}
+		if err := saveTags(tx, e.ID, e.Tags); err != nil {
+			return err
+		}
+		return saveAssets(tx, e.ID, e.Assets)
+	})
+}
+
+// claimSlug removes the redirect of the slug to be used as a current slug
+func claimSlug(tx *sql.Tx, slug string) error {
+	if len(slug) == 0 {
+		return nil
+	}
+	_, err := tx.Exec("delete from entry_slug_redirects where slug=?", slug)
+	return err
+}
+
+// saveSlugRedirect records the current slug of the entry as a redirect when the slug is changed
+func saveSlugRedirect(tx *sql.Tx, id int, slug string) error {
+	var current sql.NullString
+	err := tx.QueryRow("select slug from entries where id=?", id).Scan(&current)
+	if err == sql.ErrNoRows {
+		return nil
+	}
 	if err != nil {
 		return err
 	}
-	defer stmt.Close()
+	if !current.Valid || current.String == slug {
+		return nil
+	}
 
-	_, err = stmt.Exec(e.Title, e.Content, e.ID)
+	if err := claimSlug(tx, slug); err != nil {
+		return err
+	}
+	if err := claimSlug(tx, current.String); err != nil {
+		return err
+	}
+	_, err = tx.Exec("insert into entry_slug_redirects (slug, entry_id) values(?, ?)", current.String, id)
 	return err
 }
 
-// Delete delets record when matched id
+// Delete delets record and its revisions, tags and slug redirects when matched id and the version
 // Returns number of deleted record and an error
-func (r *EntryRepositoryImpl) Delete(id int) (bool, error) {
-	stmt, err := r.Conn.Prepare("delete from entries where id=?")
+func (r *EntryRepositoryImpl) Delete(id, version int) (bool, error) {
+	var deleted bool
+	err := r.transaction(func(tx *sql.Tx) error {
+		query := "delete from entries where id=?"
+		args := []interface{}{id}
+		if version != 0 {
+			query += " and version=?"
+			args = append(args, version)
+		}
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		cnt, _ := res.RowsAffected()
+		deleted = cnt > 0
+		if !deleted && version != 0 {
+			var n int
+			if err := tx.QueryRow("select count(*) from entries where id=?", id).Scan(&n); err != nil {
+				return err
+			}
+			if n != 0 {
+				return config.ErrEntryVersionConflict
+			}
+		}
+
+		for _, q := range []string{
+			"delete from entry_revisions where entry_id=?",
+			"delete from entry_tags where entry_id=?",
+			"delete from entry_slug_redirects where entry_id=?",
+			"delete from entry_assets where entry_id=?",
+		} {
+			if _, err := tx.Exec(q, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// PublishScheduled changes the scheduled entries to public when the publish date has come
+// Returns number of published entries
+func (r *EntryRepositoryImpl) PublishScheduled(now time.Time) (int, error) {
+	res, err := r.Conn.Exec("update entries set status=?, version=version+1 where status=? and publish_at <= ?",
+		int(domain.EntryStatusPublic), int(domain.EntryStatusScheduled), now.UTC())
 	if err != nil {
-		return false, err
+		return 0, err
 	}
-	defer stmt.Close()
-
-	res, err := stmt.Exec(id)
 	cnt, _ := res.RowsAffected()
-	return cnt > 0, err
+	return int(cnt), nil
+}
+
+// GetRevisions returns revisions of the entry without content, in order of oldest
+func (r *EntryRepositoryImpl) GetRevisions(entryID int) ([]*domain.Revision, error) {
+	rows, err := r.query("select id, entry_id, title, created_at from entry_revisions where entry_id=? order by id", entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revs := make([]*domain.Revision, 0)
+	for rows.Next() {
+		rev := &domain.Revision{}
+		err := rows.Scan(&rev.ID, &rev.EntryID, &rev.Title, &rev.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, rev)
+	}
+	return revs, nil
+}
+
+// GetRevision returns a revision of the entry
+func (r *EntryRepositoryImpl) GetRevision(entryID, revisionID int) (*domain.Revision, error) {
+	row, err := r.queryRow("select id, entry_id, title, content, source, created_at from entry_revisions where id=? and entry_id=?", revisionID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	rev := &domain.Revision{}
+	var source sql.NullString
+	err = row.Scan(&rev.ID, &rev.EntryID, &rev.Title, &rev.Content, &source, &rev.CreatedAt)
+	rev.Source = source.String
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFoundRevision
+	}
+	return rev, err
 }
@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"database/sql"
+
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/utils"
+)
+
+// EntryPathRepositoryImpl implements the EntryPathRepository
+type EntryPathRepositoryImpl struct {
+	*SQLRepositoryAdapter
+}
+
+// NewEntryPathRepository returns initialized EntryPathRepositoryImpl
+func NewEntryPathRepository() (repository.EntryPathRepository, error) {
+	db, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EntryPathRepositoryImpl{
+		&SQLRepositoryAdapter{Conn: db},
+	}, nil
+}
+
+// GetEntryID returns the entry id matched by the path
+func (r *EntryPathRepositoryImpl) GetEntryID(path string) (int, error) {
+	row, err := r.queryRow("select entry_id from entry_paths where path=?", path)
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = row.Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrNotFoundEntryPath
+	}
+	return id, err
+}
+
+// Save maps the path to the entry, replaces the entry of the path
+func (r *EntryPathRepositoryImpl) Save(path string, entryID int) error {
+	if len(path) > config.MaxEntryPathBytes {
+		return config.ErrEntrySizeLimitExceeded
+	}
+	return r.transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("delete from entry_paths where path=?", path); err != nil {
+			return err
+		}
+		_, err := tx.Exec("insert into entry_paths (path, entry_id) values(?, ?)", path, entryID)
+		return err
+	})
+}
+
+// Delete deletes the mapping of the path
+func (r *EntryPathRepositoryImpl) Delete(path string) error {
+	stmt, err := r.Conn.Prepare("delete from entry_paths where path=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(path)
+	return err
+}
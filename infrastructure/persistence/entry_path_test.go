@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestEntryPath(t *testing.T) {
+	repo, err := NewEntryPathRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/delete_entry_paths.sql")
+
+	cases := []struct {
+		path      string
+		entryID   int
+		expectErr error
+	}{
+		{"a.md", 1, nil},
+		// replace the entry of the path
+		{"a.md", 2, nil},
+		{"posts/b.md", 3, nil},
+		{strings.Repeat("a", config.MaxEntryPathBytes+1), 4, config.ErrEntrySizeLimitExceeded},
+	}
+	for i, c := range cases {
+		err := repo.Save(c.path, c.entryID)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		id, err := repo.GetEntryID(c.path)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if id != c.entryID {
+			t.Errorf("#%d: want entry id %d, got %d", i, c.entryID, id)
+		}
+	}
+
+	if err := repo.Delete("a.md"); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := repo.GetEntryID("a.md"); err != domain.ErrNotFoundEntryPath {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundEntryPath, err)
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"database/sql"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/config"
 	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
 	"github.com/takashabe/lumber/helper"
 )
 
@@ -46,20 +50,33 @@ func TestGetIDsEntry(t *testing.T) {
 
 	cases := []struct {
 		fixture   string
+		filter    repository.EntryFilter
 		expectIDs []int
 	}{
 		{
 			"testdata/entries.yml",
+			repository.EntryFilter{IncludePrivate: true},
 			[]int{1, 2},
 		},
+		{
+			"testdata/entries.yml",
+			repository.EntryFilter{},
+			[]int{},
+		},
+		{
+			"testdata/scheduled_entries.yml",
+			repository.EntryFilter{IncludePrivate: true},
+			[]int{1},
+		},
 		{
 			"testdata/delete_entries.sql",
+			repository.EntryFilter{IncludePrivate: true},
 			[]int{},
 		},
 	}
 	for i, c := range cases {
 		helper.LoadFixture(t, c.fixture)
-		ids, err := db.GetIDs()
+		ids, err := db.GetIDs(c.filter)
 		if err != nil {
 			t.Errorf("#%d: want non error, got %#v", i, err)
 		}
@@ -75,6 +92,19 @@ func TestGetTitlesEntry(t *testing.T) {
 		t.Fatalf("want non error, got %#v", err)
 	}
 
+	first := &domain.Entry{
+		ID:        1,
+		Title:     "foo",
+		CreatedAt: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	second := &domain.Entry{
+		ID:        2,
+		Title:     "foo",
+		CreatedAt: time.Date(2018, 3, 3, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2018, 3, 3, 0, 0, 0, 0, time.UTC),
+	}
+
 	cases := []struct {
 		fixture string
 		start   int
@@ -85,27 +115,19 @@ func TestGetTitlesEntry(t *testing.T) {
 			"testdata/entries.yml",
 			0,
 			2,
-			[]*domain.Entry{
-				&domain.Entry{ID: 1, Title: "foo"},
-				&domain.Entry{ID: 2, Title: "foo"},
-			},
+			[]*domain.Entry{first, second},
 		},
 		{
 			"testdata/entries.yml",
 			2,
 			2,
-			[]*domain.Entry{
-				&domain.Entry{ID: 2, Title: "foo"},
-			},
+			[]*domain.Entry{second},
 		},
 		{
 			"testdata/entries.yml",
 			0,
 			0,
-			[]*domain.Entry{
-				&domain.Entry{ID: 1, Title: "foo"},
-				&domain.Entry{ID: 2, Title: "foo"},
-			},
+			[]*domain.Entry{first, second},
 		},
 		{
 			"testdata/delete_entries.sql",
@@ -116,12 +138,159 @@ func TestGetTitlesEntry(t *testing.T) {
 	}
 	for i, c := range cases {
 		helper.LoadFixture(t, c.fixture)
-		es, err := db.GetTitles(c.start, c.length)
+		es, err := db.GetTitles(repository.EntryFilter{IncludePrivate: true}, c.start, c.length)
 		if err != nil {
 			t.Errorf("#%d: want non error, got %#v", i, err)
 		}
 		if !reflect.DeepEqual(es, c.expect) {
-			t.Errorf("#%d: want %q, got %q", i, c.expect, es)
+			t.Errorf("#%d: want %v, got %v", i, c.expect, es)
+		}
+	}
+}
+
+func TestGetTitlePageEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/page_entries.yml")
+
+	at := func(day int) time.Time {
+		return time.Date(2018, 3, day, 0, 0, 0, 0, time.UTC)
+	}
+	all := repository.EntryFilter{IncludePrivate: true}
+	cases := []struct {
+		filter    repository.EntryFilter
+		query     repository.EntryPageQuery
+		expectIDs []int
+	}{
+		{
+			all,
+			repository.EntryPageQuery{Limit: 2},
+			[]int{1, 2},
+		},
+		{
+			all,
+			repository.EntryPageQuery{After: &repository.EntryCursor{ID: 2, Time: at(2)}, Limit: 2},
+			[]int{3, 4},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Desc: true}, After: &repository.EntryCursor{ID: 3, Time: at(2)}},
+			[]int{2, 1},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Before: &repository.EntryCursor{ID: 4, Time: at(4)}, Limit: 2},
+			[]int{2, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Desc: true}, Before: &repository.EntryCursor{ID: 2, Time: at(2)}, Limit: 2},
+			[]int{4, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByTitle}, After: &repository.EntryCursor{ID: 1, Title: "c"}},
+			[]int{5, 3},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByTitle, Desc: true}, Before: &repository.EntryCursor{ID: 1, Title: "c"}},
+			[]int{3, 5},
+		},
+		{
+			all,
+			repository.EntryPageQuery{Sort: repository.EntrySort{Key: repository.SortByUpdatedAt}, After: &repository.EntryCursor{ID: 4, Time: at(7)}, Limit: 2},
+			[]int{5, 3},
+		},
+		{
+			repository.EntryFilter{},
+			repository.EntryPageQuery{After: &repository.EntryCursor{ID: 3, Time: at(2)}},
+			[]int{4},
+		},
+	}
+	for i, c := range cases {
+		es, err := db.GetTitlePage(c.filter, c.query)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		ids := make([]int, 0)
+		for _, e := range es {
+			ids = append(ids, e.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+	}
+
+	countCases := []struct {
+		filter repository.EntryFilter
+		expect int
+	}{
+		{all, 5},
+		{repository.EntryFilter{}, 4},
+	}
+	for i, c := range countCases {
+		n, err := db.Count(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expect {
+			t.Errorf("#%d: want %d, got %d", i, c.expect, n)
+		}
+	}
+}
+
+func TestGetArchivesEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/page_entries.yml")
+
+	since := time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		filter    repository.EntryFilter
+		expectIDs []int
+		expect    []*domain.Archive
+	}{
+		{
+			repository.EntryFilter{IncludePrivate: true},
+			[]int{1, 2, 3, 4, 5},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 5}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Since: &since, Until: &until},
+			[]int{2, 3, 4},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 3}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Until: &since},
+			[]int{1},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 1}},
+		},
+		{
+			repository.EntryFilter{IncludePrivate: true, Since: &until},
+			[]int{5},
+			[]*domain.Archive{{Year: 2018, Month: time.March, Count: 1}},
+		},
+	}
+	for i, c := range cases {
+		ids, err := db.GetIDs(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+		archives, err := db.GetArchives(c.filter)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(archives, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, archives)
 		}
 	}
 }
@@ -190,11 +359,13 @@ func TestEditEntry(t *testing.T) {
 		inputID      int
 		inputTitle   string
 		inputContent string
+		inputSource  string
 	}{
 		{
 			1,
 			"edit_title",
 			"edit_content",
+			"# edit_title\n\nedit_content",
 		},
 	}
 	for i, c := range cases {
@@ -204,6 +375,7 @@ func TestEditEntry(t *testing.T) {
 			ID:      c.inputID,
 			Title:   c.inputTitle,
 			Content: c.inputContent,
+			Source:  c.inputSource,
 		}
 		err = db.Edit(entity)
 		if err != nil {
@@ -217,6 +389,51 @@ func TestEditEntry(t *testing.T) {
 			t.Errorf("#%d: want title %s and content %s, but title %s and content %s",
 				i, e.Title, e.Content, c.inputTitle, c.inputContent)
 		}
+		if e.Source != c.inputSource {
+			t.Errorf("#%d: want source %q, got %q", i, c.inputSource, e.Source)
+		}
+	}
+}
+
+func TestEditEntryVersion(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/entries.yml")
+
+	cases := []struct {
+		version       int
+		expectVersion int
+		expectErr     error
+	}{
+		{1, 2, nil},
+		// the version has been changed by the previous edit
+		{1, 1, config.ErrEntryVersionConflict},
+		{2, 3, nil},
+		{0, 4, nil},
+	}
+	for i, c := range cases {
+		e, err := db.Get(1)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		e.Version = c.version
+		err = db.Edit(e)
+		if errors.Cause(err) != c.expectErr {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if e.Version != c.expectVersion {
+			t.Errorf("#%d: want version %d, got %d", i, c.expectVersion, e.Version)
+		}
+	}
+
+	e, err := db.Get(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if e.Version != 4 {
+		t.Errorf("want version 4, got %d", e.Version)
 	}
 }
 
@@ -227,21 +444,324 @@ func TestDeleteEntry(t *testing.T) {
 	}
 
 	cases := []struct {
-		input  int
-		expect bool
+		input     int
+		version   int
+		expect    bool
+		expectErr error
 	}{
-		{1, true},
-		{0, false},
+		{1, 0, true, nil},
+		{0, 0, false, nil},
+		{1, 1, true, nil},
+		{1, 2, false, config.ErrEntryVersionConflict},
+		{0, 1, false, nil},
 	}
 	for i, c := range cases {
 		helper.LoadFixture(t, "testdata/entries.yml")
 
-		flag, err := db.Delete(c.input)
-		if err != nil {
-			t.Fatalf("want non error, got %#v", err)
+		flag, err := db.Delete(c.input, c.version)
+		if errors.Cause(err) != c.expectErr {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.expectErr, err)
 		}
 		if flag != c.expect {
 			t.Errorf("#%d: want error %#v, got %#v", i, c.expect, err)
 		}
 	}
 }
+
+func TestGetRevisionsEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/entries.yml")
+	helper.LoadFixture(t, "testdata/delete_revisions.sql")
+
+	err = db.Edit(&domain.Entry{ID: 1, Title: "edit_title", Content: "edit_content"})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	revs, err := db.GetRevisions(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectTitles := []string{"foo", "edit_title"}
+	if len(revs) != len(expectTitles) {
+		t.Fatalf("want revisions %d, got %d", len(expectTitles), len(revs))
+	}
+	for i, rev := range revs {
+		if rev.EntryID != 1 || rev.Title != expectTitles[i] {
+			t.Errorf("#%d: want entry id 1 and title %s, got %d and %s", i, expectTitles[i], rev.EntryID, rev.Title)
+		}
+	}
+
+	rev, err := db.GetRevision(1, revs[0].ID)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if rev.Content != "bar" {
+		t.Errorf("want content bar, got %s", rev.Content)
+	}
+	_, err = db.GetRevision(2, revs[0].ID)
+	if err != domain.ErrNotFoundRevision {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundRevision, err)
+	}
+}
+
+func TestSaveEntryWithMetadata(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/delete_entries.sql")
+
+	publishAt := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	input := &domain.Entry{
+		Title:     "title",
+		Content:   "content",
+		Status:    domain.EntryStatusPrivate,
+		Slug:      "slug",
+		Summary:   "summary",
+		PublishAt: &publishAt,
+		AuthorID:  2,
+	}
+	id, err := db.Save(input)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	e, err := db.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if e.Slug != input.Slug || e.Summary != input.Summary || e.Status != input.Status || e.AuthorID != input.AuthorID {
+		t.Errorf("want %#v, got %#v", input, e)
+	}
+	if e.PublishAt == nil || !e.PublishAt.Equal(publishAt) {
+		t.Errorf("want publish_at %v, got %v", publishAt, e.PublishAt)
+	}
+}
+
+func TestTagsEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/entries.yml")
+	helper.LoadFixture(t, "testdata/tags.yml")
+	helper.LoadFixture(t, "testdata/entry_tags.yml")
+
+	tags, err := db.GetTags(repository.EntryFilter{IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectTags := []*domain.Tag{
+		&domain.Tag{Name: "blog", Count: 1},
+		&domain.Tag{Name: "go", Count: 2},
+	}
+	if !reflect.DeepEqual(tags, expectTags) {
+		t.Errorf("want %v, got %v", expectTags, tags)
+	}
+
+	f := repository.EntryFilter{Tags: []string{"go", "blog"}, IncludePrivate: true}
+	es, err := db.GetTitles(f, 0, 0)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectEntries := []*domain.Entry{
+		&domain.Entry{
+			ID:        1,
+			Title:     "foo",
+			CreatedAt: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2018, 3, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	if !reflect.DeepEqual(es, expectEntries) {
+		t.Errorf("want %v, got %v", expectEntries, es)
+	}
+
+	// replace tags by editing, and create a new tag
+	err = db.Edit(&domain.Entry{ID: 1, Title: "foo", Content: "bar", Tags: []string{"new"}})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	e, err := db.Get(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := []string{"new"}; !reflect.DeepEqual(e.Tags, expect) {
+		t.Errorf("want %v, got %v", expect, e.Tags)
+	}
+}
+
+func TestAssetsEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/delete_entries.sql")
+	helper.LoadFixture(t, "testdata/delete_entry_assets.sql")
+
+	hashA := strings.Repeat("a", 64)
+	hashB := strings.Repeat("b", 64)
+	id, err := db.Save(&domain.Entry{Title: "foo", Content: "bar", Assets: map[string]string{"images/a.png": hashA}})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	e, err := db.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := map[string]string{"images/a.png": hashA}; !reflect.DeepEqual(e.Assets, expect) {
+		t.Errorf("want %v, got %v", expect, e.Assets)
+	}
+
+	// replace assets by editing
+	e.Assets = map[string]string{"images/a.png": hashB, "images/b.png": hashB}
+	if err := db.Edit(e); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	e, err = db.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := map[string]string{"images/a.png": hashB, "images/b.png": hashB}; !reflect.DeepEqual(e.Assets, expect) {
+		t.Errorf("want %v, got %v", expect, e.Assets)
+	}
+}
+
+func TestSlugEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/delete_entries.sql")
+	helper.LoadFixture(t, "testdata/delete_slug_redirects.sql")
+
+	id, err := db.Save(&domain.Entry{Title: "foo", Content: "bar", Slug: "foo"})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// entries without the slug are not conflicted
+	for i := 0; i < 2; i++ {
+		if _, err := db.Save(&domain.Entry{Title: "baz", Content: "bar"}); err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+	}
+	if _, err := db.Save(&domain.Entry{Title: "dup", Content: "bar", Slug: "foo"}); err == nil {
+		t.Errorf("want error for the duplicated slug, got nil")
+	}
+
+	for _, slug := range []string{"renamed", "renamed-again"} {
+		err = db.Edit(&domain.Entry{ID: id, Title: "foo", Content: "bar", Slug: slug})
+		if err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+	}
+
+	e, err := db.GetBySlug("renamed-again")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if e.ID != id {
+		t.Errorf("want id %d, got %d", id, e.ID)
+	}
+	cases := []struct {
+		input  string
+		expect string
+		err    error
+	}{
+		{"foo", "renamed-again", nil},
+		{"renamed", "renamed-again", nil},
+		{"unknown", "", sql.ErrNoRows},
+	}
+	for i, c := range cases {
+		current, err := db.GetRedirectedSlug(c.input)
+		if errors.Cause(err) != c.err {
+			t.Fatalf("#%d: want error %#v, got %#v", i, c.err, err)
+		}
+		if current != c.expect {
+			t.Errorf("#%d: want %s, got %s", i, c.expect, current)
+		}
+	}
+
+	// redirects are removed with the entry
+	if _, err := db.Delete(id, 0); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := db.GetRedirectedSlug("foo"); errors.Cause(err) != sql.ErrNoRows {
+		t.Errorf("want error %#v, got %#v", sql.ErrNoRows, err)
+	}
+}
+
+func TestPublishScheduledEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/scheduled_entries.yml")
+
+	cases := []struct {
+		now         time.Time
+		expectCount int
+		expectIDs   []int
+	}{
+		{time.Date(2018, 2, 1, 0, 0, 0, 0, time.UTC), 0, []int{1}},
+		{time.Date(2018, 3, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60)), 1, []int{1, 2}},
+		{time.Date(2018, 5, 1, 0, 0, 0, 0, time.UTC), 1, []int{1, 2, 3}},
+	}
+	for i, c := range cases {
+		n, err := db.PublishScheduled(c.now)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if n != c.expectCount {
+			t.Errorf("#%d: want %d published, got %d", i, c.expectCount, n)
+		}
+		ids, err := db.GetIDs(repository.EntryFilter{})
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+	}
+}
+
+func TestGetRecentEntry(t *testing.T) {
+	db, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/feed_entries.yml")
+
+	cases := []struct {
+		filter    repository.EntryFilter
+		n         int
+		expectIDs []int
+	}{
+		{repository.EntryFilter{}, 0, []int{2, 1}},
+		{repository.EntryFilter{IncludePrivate: true}, 0, []int{3, 2, 1}},
+		{repository.EntryFilter{IncludePrivate: true}, 1, []int{3}},
+	}
+	for i, c := range cases {
+		es, err := db.GetRecent(c.filter, c.n)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		ids := make([]int, 0)
+		for _, e := range es {
+			ids = append(ids, e.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids)
+		}
+	}
+
+	e, err := db.Get(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expectUpdatedAt := time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC)
+	if !e.UpdatedAt.Equal(expectUpdatedAt) {
+		t.Errorf("want %v, got %v", expectUpdatedAt, e.UpdatedAt)
+	}
+}
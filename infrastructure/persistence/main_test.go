@@ -4,28 +4,10 @@ import (
 	"os"
 	"testing"
 
-	fixture "github.com/takashabe/go-fixture"
-	_ "github.com/takashabe/go-fixture/mysql"
+	"github.com/takashabe/lumber/helper"
 )
 
 func TestMain(m *testing.M) {
-	setup()
+	helper.SetupTablesFrom("../../_sql")
 	os.Exit(m.Run())
 }
-
-func setup() {
-	db, err := NewEntryRepository()
-	if err != nil {
-		panic(err)
-	}
-
-	impl := db.(*EntryRepositoryImpl)
-	f, err := fixture.NewFixture(impl.Conn, "mysql")
-	if err != nil {
-		panic(err)
-	}
-	err = f.Load("testdata/schema.sql")
-	if err != nil {
-		panic(err)
-	}
-}
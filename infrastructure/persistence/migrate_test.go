@@ -0,0 +1,85 @@
+package persistence
+
+import (
+	"testing"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestUpgradeSchema(t *testing.T) {
+	helper.LoadFixture(t, helper.DialectFile("testdata/legacy_schema.sql"))
+	defer func() {
+		helper.LoadFixture(t, "testdata/drop_tables.sql")
+		helper.SetupTablesFrom("../../_sql")
+	}()
+	helper.LoadFixture(t, helper.DialectFile("../../_sql/upgrade.sql"))
+
+	// the existing entries are read and edited with the new columns and tables
+	entryRepo, err := NewEntryRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	entry, err := entryRepo.Get(1)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if entry.Version != 1 {
+		t.Errorf("want version 1, got %d", entry.Version)
+	}
+	entry.Content = "<p>edited</p>"
+	entry.Tags = []string{"go"}
+	if err := entryRepo.Edit(entry); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	revisions, err := entryRepo.GetRevisions(1)
+	if err != nil || len(revisions) == 0 {
+		t.Errorf("want the revisions, got %#v, %#v", revisions, err)
+	}
+	if _, err := entryRepo.GetTitles(repository.EntryFilter{IncludePrivate: true}, 1, 2); err != nil {
+		t.Errorf("want non error, got %#v", err)
+	}
+	auditRepo, err := NewAuditRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := auditRepo.Save(&domain.AuditLog{Action: domain.AuditActionEdit, EntryID: 1}); err != nil {
+		t.Errorf("want non error, got %#v", err)
+	}
+
+	tokenRepo, err := NewTokenRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	userRepo, err := NewUserRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	// the plain values are authenticated before and after "lumber token migrate"
+	auth := application.NewAuthInteractor(tokenRepo, userRepo)
+	for i, value := range []string{"foo", "bar"} {
+		if _, err := auth.Authenticate(value); err != nil {
+			t.Errorf("#%d: want non error before the migration, got %#v", i, err)
+		}
+	}
+	n, err := application.NewTokenInteractor(tokenRepo, userRepo).Migrate()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if n != 2 {
+		t.Errorf("want 2 migrated tokens, got %d", n)
+	}
+	for i, value := range []string{"foo", "bar"} {
+		user, err := auth.Authenticate(value)
+		if err != nil {
+			t.Errorf("#%d: want non error after the migration, got %#v", i, err)
+			continue
+		}
+		if !user.IsAdmin() {
+			t.Errorf("#%d: want the admin, got %#v", i, user)
+		}
+	}
+}
@@ -0,0 +1 @@
+truncate table entry_slug_redirects;
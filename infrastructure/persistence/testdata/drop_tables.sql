@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS entries;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS entry_revisions;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS entry_tags;
+DROP TABLE IF EXISTS entry_slug_redirects;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS entry_assets;
+DROP TABLE IF EXISTS entry_paths;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,32 @@
+DROP TABLE IF EXISTS entries;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS entry_revisions;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS entry_tags;
+DROP TABLE IF EXISTS entry_slug_redirects;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS entry_assets;
+DROP TABLE IF EXISTS entry_paths;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS audit_logs;
+
+CREATE TABLE entries (
+  `id`         int          NOT NULL AUTO_INCREMENT,
+  `title`      varchar(256) NOT NULL,
+  `content`    text         NOT NULL,
+  `status`     int          NOT NULL,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE tokens (
+  `id`         int          NOT NULL AUTO_INCREMENT,
+  `value`      varchar(256) NOT NULL UNIQUE,
+  `created_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO entries (id, title, content, status) VALUES (1, 'foo', '<p>foo</p>', 0), (2, 'bar', '<p>bar</p>', 1);
+INSERT INTO tokens (id, value) VALUES (1, 'foo'), (2, 'bar');
@@ -2,6 +2,7 @@ package persistence
 
 import (
 	"database/sql"
+	"time"
 
 	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/domain/repository"
@@ -15,7 +16,7 @@ type TokenRepositoryImpl struct {
 
 // NewTokenRepository returns initialized Datastore
 func NewTokenRepository() (repository.TokenRepository, error) {
-	db, err := utils.ConnectMySQL()
+	db, err := utils.ConnectDB()
 	if err != nil {
 		return nil, err
 	}
@@ -25,17 +26,34 @@ func NewTokenRepository() (repository.TokenRepository, error) {
 	}, nil
 }
 
-func (r *TokenRepositoryImpl) mapToEntity(row *sql.Row) (*domain.Token, error) {
+// tokenColumns is the column list corresponding to mapToEntity
+// the value column has the hash of the value
+const tokenColumns = "id, value, prefix, user_id, label, created_at, last_used_at, expires_at"
+
+func (r *TokenRepositoryImpl) mapToEntity(row scanner) (*domain.Token, error) {
 	m := &domain.Token{}
-	err := row.Scan(&m.ID, &m.Value)
+	err := row.Scan(&m.ID, &m.Hash, &m.Prefix, &m.UserID, &m.Label, &m.CreatedAt, &m.LastUsedAt, &m.ExpiresAt)
 	return m, err
 }
 
 // Get return a token record matched by 'id'
 func (r *TokenRepositoryImpl) Get(id int) (*domain.Token, error) {
-	row, err := r.queryRow("select id, value from tokens where id=?", id)
-	if err != nil {
+	return r.getBy("id=?", id)
+}
+
+// FindByPrefix returns the tokens matched by the public prefix of the value
+func (r *TokenRepositoryImpl) FindByPrefix(prefix string) ([]*domain.Token, error) {
+	return r.list("where prefix=? order by id", prefix)
+}
 
+// FindByPlainValue returns the token saved the plain value before hashing
+func (r *TokenRepositoryImpl) FindByPlainValue(value string) (*domain.Token, error) {
+	return r.getBy("value=?", value)
+}
+
+func (r *TokenRepositoryImpl) getBy(cond string, args ...interface{}) (*domain.Token, error) {
+	row, err := r.queryRow("select "+tokenColumns+" from tokens where "+cond, args...)
+	if err != nil {
 		return nil, err
 	}
 	d, err := r.mapToEntity(row)
@@ -45,33 +63,43 @@ func (r *TokenRepositoryImpl) Get(id int) (*domain.Token, error) {
 	return d, err
 }
 
-// FindByValue return a token record matched by 'value'
-func (r *TokenRepositoryImpl) FindByValue(value string) (*domain.Token, error) {
-	row, err := r.queryRow("select id, value from tokens where value=?", value)
+// List returns all tokens ordered by the id
+func (r *TokenRepositoryImpl) List() ([]*domain.Token, error) {
+	return r.list("order by id")
+}
+
+func (r *TokenRepositoryImpl) list(cond string, args ...interface{}) ([]*domain.Token, error) {
+	rows, err := r.query("select "+tokenColumns+" from tokens "+cond, args...)
 	if err != nil {
 		return nil, err
 	}
-	d, err := r.mapToEntity(row)
-	if err != nil && err == sql.ErrNoRows {
-		return nil, domain.ErrNotFoundToken
+	defer rows.Close()
+
+	tokens := []*domain.Token{}
+	for rows.Next() {
+		t, err := r.mapToEntity(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
 	}
-	return d, err
+	return tokens, rows.Err()
 }
 
 // Save saves token data to datastore
 func (r *TokenRepositoryImpl) Save(m *domain.Token) (int, error) {
-	_, err := r.FindByValue(m.Value)
+	_, err := r.FindByPlainValue(m.Hash)
 	if err == nil {
 		return 0, domain.ErrTokenAlreadyExistSameValue
 	}
 
-	stmt, err := r.Conn.Prepare("insert into tokens (value) values(?)")
+	stmt, err := r.Conn.Prepare("insert into tokens (value, prefix, user_id, label, expires_at) values(?, ?, ?, ?, ?)")
 	if err != nil {
 		return 0, err
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(m.Value)
+	res, err := stmt.Exec(m.Hash, m.Prefix, m.UserID, m.Label, utcTime(m.ExpiresAt))
 	if err != nil {
 		return 0, err
 	}
@@ -79,15 +107,21 @@ func (r *TokenRepositoryImpl) Save(m *domain.Token) (int, error) {
 	return int(id), nil
 }
 
-// Update update the value
+// Update update the hash of the value, the label and the expiry
 func (r *TokenRepositoryImpl) Update(m *domain.Token) error {
-	stmt, err := r.Conn.Prepare("update tokens set value=? where id=?")
+	stmt, err := r.Conn.Prepare("update tokens set value=?, prefix=?, label=?, expires_at=? where id=?")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(m.Value, m.ID)
+	_, err = stmt.Exec(m.Hash, m.Prefix, m.Label, utcTime(m.ExpiresAt), m.ID)
+	return err
+}
+
+// Touch records the time the token is used at
+func (r *TokenRepositoryImpl) Touch(id int, at time.Time) error {
+	_, err := r.Conn.Exec("update tokens set last_used_at=? where id=?", at.UTC(), id)
 	return err
 }
 
@@ -101,6 +135,9 @@ func (r *TokenRepositoryImpl) Delete(id int) (bool, error) {
 	defer stmt.Close()
 
 	res, err := stmt.Exec(id)
+	if err != nil {
+		return false, err
+	}
 	cnt, _ := res.RowsAffected()
-	return cnt > 0, err
+	return cnt > 0, nil
 }
@@ -1,7 +1,9 @@
 package persistence
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/helper"
@@ -37,7 +39,7 @@ func TestGetToken(t *testing.T) {
 	}
 }
 
-func TestFindByValueToken(t *testing.T) {
+func TestFindByPlainValueToken(t *testing.T) {
 	repo, err := NewTokenRepository()
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
@@ -54,7 +56,7 @@ func TestFindByValueToken(t *testing.T) {
 		{"", 0, domain.ErrNotFoundToken},
 	}
 	for i, c := range cases {
-		token, err := repo.FindByValue(c.input)
+		token, err := repo.FindByPlainValue(c.input)
 		if err != c.expectErr {
 			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
 		}
@@ -79,11 +81,15 @@ func TestSaveToken(t *testing.T) {
 		expectErr error
 	}{
 		{
-			&domain.Token{Value: "test"},
+			&domain.Token{Hash: "test"},
 			nil,
 		},
 		{
-			&domain.Token{Value: "foo"},
+			&domain.Token{Hash: "owned", Prefix: "own", UserID: 1},
+			nil,
+		},
+		{
+			&domain.Token{Hash: "foo"},
 			domain.ErrTokenAlreadyExistSameValue,
 		},
 	}
@@ -101,8 +107,72 @@ func TestSaveToken(t *testing.T) {
 		if err != nil {
 			t.Errorf("#%d: want non error, got %#v", i, err)
 		}
-		if token.Value != c.input.Value {
-			t.Errorf("#%d: want value %#v, got %#v", i, c.input.Value, token.Value)
+		if token.Hash != c.input.Hash || token.Prefix != c.input.Prefix || token.UserID != c.input.UserID {
+			t.Errorf("#%d: want %#v, got %#v", i, c.input, token)
+		}
+	}
+}
+
+func TestListAndTouchToken(t *testing.T) {
+	repo, err := NewTokenRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/tokens.yml")
+
+	usedAt := time.Date(2018, 3, 1, 10, 0, 0, 0, time.UTC)
+	if err := repo.Touch(2, usedAt); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	expiresAt := time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+	err = repo.Update(&domain.Token{ID: 3, Hash: "rotated", Prefix: "rot", Label: "laptop", ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	tokens, err := repo.List()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(tokens) != 3 {
+		t.Fatalf("want 3 tokens, got %d", len(tokens))
+	}
+	if tokens[1].LastUsedAt == nil || !tokens[1].LastUsedAt.Equal(usedAt) || tokens[0].LastUsedAt != nil {
+		t.Errorf("want last_used_at %v, got %v", usedAt, tokens[1].LastUsedAt)
+	}
+	rotated := tokens[2]
+	if rotated.Hash != "rotated" || rotated.Prefix != "rot" || rotated.Label != "laptop" || rotated.UserID != 2 ||
+		rotated.ExpiresAt == nil || !rotated.ExpiresAt.Equal(expiresAt) || rotated.CreatedAt.IsZero() {
+		t.Errorf("want updated token, got %#v", rotated)
+	}
+}
+
+func TestFindByPrefixToken(t *testing.T) {
+	repo, err := NewTokenRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/hashed_tokens.yml")
+
+	cases := []struct {
+		input     string
+		expectIDs []int
+	}{
+		{"0a1b2c", []int{1, 3}},
+		{"3d4e5f", []int{2}},
+		{"unknown", nil},
+	}
+	for i, c := range cases {
+		tokens, err := repo.FindByPrefix(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		var ids []int
+		for _, token := range tokens {
+			ids = append(ids, token.ID)
+		}
+		if !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want %v, got %v", i, c.expectIDs, ids)
 		}
 	}
 }
@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"database/sql"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/utils"
+)
+
+// UserRepositoryImpl implements the UserRepository
+type UserRepositoryImpl struct {
+	*SQLRepositoryAdapter
+}
+
+// NewUserRepository returns initialized UserRepositoryImpl
+func NewUserRepository() (repository.UserRepository, error) {
+	db, err := utils.ConnectDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserRepositoryImpl{
+		&SQLRepositoryAdapter{Conn: db},
+	}, nil
+}
+
+func (r *UserRepositoryImpl) mapToEntity(row scanner) (*domain.User, error) {
+	m := &domain.User{}
+	err := row.Scan(&m.ID, &m.Name, &m.Role)
+	return m, err
+}
+
+// Get returns a user matched by the id
+func (r *UserRepositoryImpl) Get(id int) (*domain.User, error) {
+	return r.getBy("id=?", id)
+}
+
+// FindByName returns a user matched by the name
+func (r *UserRepositoryImpl) FindByName(name string) (*domain.User, error) {
+	return r.getBy("name=?", name)
+}
+
+func (r *UserRepositoryImpl) getBy(cond string, args ...interface{}) (*domain.User, error) {
+	row, err := r.queryRow("select id, name, role from users where "+cond, args...)
+	if err != nil {
+		return nil, err
+	}
+	u, err := r.mapToEntity(row)
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrNotFoundUser
+	}
+	return u, err
+}
+
+// List returns all users ordered by the id
+func (r *UserRepositoryImpl) List() ([]*domain.User, error) {
+	rows, err := r.query("select id, name, role from users order by id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		u, err := r.mapToEntity(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// Save saves the user
+func (r *UserRepositoryImpl) Save(m *domain.User) (int, error) {
+	_, err := r.FindByName(m.Name)
+	if err == nil {
+		return 0, domain.ErrUserAlreadyExistSameName
+	}
+
+	stmt, err := r.Conn.Prepare("insert into users (name, role) values(?, ?)")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.Exec(m.Name, string(m.Role))
+	if err != nil {
+		return 0, err
+	}
+	id, _ := res.LastInsertId()
+	return int(id), nil
+}
+
+// Update updates the name and the role
+func (r *UserRepositoryImpl) Update(m *domain.User) error {
+	other, err := r.FindByName(m.Name)
+	if err == nil && other.ID != m.ID {
+		return domain.ErrUserAlreadyExistSameName
+	}
+
+	stmt, err := r.Conn.Prepare("update users set name=?, role=? where id=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.Exec(m.Name, string(m.Role), m.ID)
+	return err
+}
+
+// Delete deletes the user and the owned tokens when matched id
+func (r *UserRepositoryImpl) Delete(id int) (bool, error) {
+	var deleted bool
+	err := r.transaction(func(tx *sql.Tx) error {
+		res, err := tx.Exec("delete from users where id=?", id)
+		if err != nil {
+			return err
+		}
+		cnt, _ := res.RowsAffected()
+		deleted = cnt > 0
+
+		_, err = tx.Exec("delete from tokens where user_id=?", id)
+		return err
+	})
+	return deleted, err
+}
@@ -0,0 +1,131 @@
+package persistence
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/helper"
+)
+
+func TestGetUser(t *testing.T) {
+	repo, err := NewUserRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/users.yml")
+
+	cases := []struct {
+		input     int
+		expect    *domain.User
+		expectErr error
+	}{
+		{2, &domain.User{ID: 2, Name: "bob", Role: domain.RoleAuthor}, nil},
+		{0, nil, domain.ErrNotFoundUser},
+	}
+	for i, c := range cases {
+		user, err := repo.Get(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if !reflect.DeepEqual(user, c.expect) {
+			t.Errorf("#%d: want %#v, got %#v", i, c.expect, user)
+		}
+	}
+}
+
+func TestListUser(t *testing.T) {
+	repo, err := NewUserRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/users.yml")
+
+	users, err := repo.List()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Name)
+	}
+	if expect := []string{"alice", "bob"}; !reflect.DeepEqual(names, expect) {
+		t.Errorf("want %v, got %v", expect, names)
+	}
+}
+
+func TestSaveAndUpdateUser(t *testing.T) {
+	repo, err := NewUserRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/users.yml")
+
+	if _, err := repo.Save(&domain.User{Name: "alice", Role: domain.RoleViewer}); err != domain.ErrUserAlreadyExistSameName {
+		t.Errorf("want error %#v, got %#v", domain.ErrUserAlreadyExistSameName, err)
+	}
+	id, err := repo.Save(&domain.User{Name: "carol", Role: domain.RoleViewer})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		input     *domain.User
+		expectErr error
+	}{
+		{&domain.User{ID: id, Name: "carol", Role: domain.RoleEditor}, nil},
+		{&domain.User{ID: id, Name: "dave", Role: domain.RoleEditor}, nil},
+		{&domain.User{ID: id, Name: "bob", Role: domain.RoleEditor}, domain.ErrUserAlreadyExistSameName},
+	}
+	for i, c := range cases {
+		err := repo.Update(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+	}
+	user, err := repo.Get(id)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if expect := (&domain.User{ID: id, Name: "dave", Role: domain.RoleEditor}); !reflect.DeepEqual(user, expect) {
+		t.Errorf("want %#v, got %#v", expect, user)
+	}
+}
+
+func TestDeleteUser(t *testing.T) {
+	repo, err := NewUserRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	tokenRepo, err := NewTokenRepository()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	helper.LoadFixture(t, "testdata/users.yml")
+	helper.LoadFixture(t, "testdata/tokens.yml")
+
+	cases := []struct {
+		input  int
+		expect bool
+	}{
+		{2, true},
+		{2, false},
+	}
+	for i, c := range cases {
+		ok, err := repo.Delete(c.input)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if ok != c.expect {
+			t.Errorf("#%d: want %t, got %t", i, c.expect, ok)
+		}
+	}
+
+	// the tokens of the user are deleted together
+	if _, err := tokenRepo.FindByPlainValue("baz"); err != domain.ErrNotFoundToken {
+		t.Errorf("want error %#v, got %#v", domain.ErrNotFoundToken, err)
+	}
+	if _, err := tokenRepo.FindByPlainValue("foo"); err != nil {
+		t.Errorf("want non error, got %#v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package search
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// Weight of the term frequency in the title against the content
+const titleWeight = 3
+
+// SearchRepositoryImpl implements the SearchRepository by the in-process inverted index
+type SearchRepositoryImpl struct {
+	mu   sync.RWMutex
+	docs map[int]*document
+	// postings has the weighted term frequency by the entry id
+	postings map[string]map[int]int
+}
+
+// document represent the indexed entry
+type document struct {
+	id    int
+	title string
+	// text is the plain text of the content, lower is lower-cased text for matching
+	text  []rune
+	lower []rune
+	terms map[string]int
+}
+
+// NewSearchRepository returns initialized SearchRepositoryImpl
+func NewSearchRepository() repository.SearchRepository {
+	return &SearchRepositoryImpl{
+		docs:     make(map[int]*document),
+		postings: make(map[string]map[int]int),
+	}
+}
+
+var htmlTagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// plainText returns the text removed html tags and redundant spaces
+func plainText(s string) string {
+	s = htmlTagRegexp.ReplaceAllString(s, " ")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// Index adds the entry to the index, replaces when already indexed
+func (r *SearchRepositoryImpl) Index(e *domain.Entry) error {
+	text := plainText(e.Content)
+	doc := &document{
+		id:    e.ID,
+		title: e.Title,
+		text:  []rune(text),
+		lower: []rune(strings.Map(toLower, text)),
+		terms: make(map[string]int),
+	}
+	title, _ := e.TrimPrivateTitle()
+	for _, t := range tokenize(title, true) {
+		doc.terms[t] += titleWeight
+	}
+	for _, t := range tokenize(text, true) {
+		doc.terms[t]++
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(e.ID)
+	r.docs[e.ID] = doc
+	for t, freq := range doc.terms {
+		if r.postings[t] == nil {
+			r.postings[t] = make(map[int]int)
+		}
+		r.postings[t][e.ID] = freq
+	}
+	return nil
+}
+
+// Remove removes the entry from the index
+func (r *SearchRepositoryImpl) Remove(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(id)
+	return nil
+}
+
+// remove must be called with lock
+func (r *SearchRepositoryImpl) remove(id int) {
+	doc, ok := r.docs[id]
+	if !ok {
+		return
+	}
+	for t := range doc.terms {
+		delete(r.postings[t], id)
+		if len(r.postings[t]) == 0 {
+			delete(r.postings, t)
+		}
+	}
+	delete(r.docs, id)
+}
+
+// Search returns the entries which contain all the words of the query
+// Scored by TF-IDF, and the higher id comes first with the same score
+func (r *SearchRepositoryImpl) Search(query string) ([]*domain.SearchHit, error) {
+	terms := uniqueTerms(tokenize(query, false))
+	hits := make([]*domain.SearchHit, 0)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	scores := make(map[int]float64)
+	for i, t := range terms {
+		posting := r.postings[t]
+		idf := math.Log(1 + float64(len(r.docs))/float64(1+len(posting)))
+		next := make(map[int]float64)
+		for id, freq := range posting {
+			if _, ok := scores[id]; i == 0 || ok {
+				next[id] = scores[id] + float64(freq)*idf
+			}
+		}
+		scores = next
+	}
+
+	for id, score := range scores {
+		doc := r.docs[id]
+		hits = append(hits, &domain.SearchHit{
+			ID:      id,
+			Title:   doc.title,
+			Score:   score,
+			Snippet: snippet(doc, terms),
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+	return hits, nil
+}
+
+func uniqueTerms(terms []string) []string {
+	res := make([]string, 0, len(terms))
+	seen := make(map[string]bool)
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			res = append(res, t)
+		}
+	}
+	return res
+}
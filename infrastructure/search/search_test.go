@@ -0,0 +1,109 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		input    string
+		indexing bool
+		expect   []string
+	}{
+		{"Hello, World!", false, []string{"hello", "world"}},
+		{"go1.10 release", false, []string{"go1", "10", "release"}},
+		{"日本語のブログ", false, []string{"日本", "本語", "語の", "のブ", "ブロ", "ログ"}},
+		{"犬", false, []string{"犬"}},
+		{"Go言語", true, []string{"go", "言", "語", "言語"}},
+	}
+	for i, c := range cases {
+		act := tokenize(c.input, c.indexing)
+		if !reflect.DeepEqual(act, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, act)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	repo := NewSearchRepository()
+	entries := []*domain.Entry{
+		{ID: 1, Title: "Go", Content: "<p>Writing a blog engine in go.</p>"},
+		{ID: 2, Title: "Blog", Content: "<p>Go is good for the blog, go go.</p>"},
+		{ID: 3, Title: "日記", Content: "<p>今日はブログを書いた</p>"},
+		{ID: 4, Title: "Removed", Content: "<p>go blog</p>"},
+	}
+	for _, e := range entries {
+		if err := repo.Index(e); err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+	}
+	if err := repo.Remove(4); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// replace the indexed entry
+	entries[0].Content = "<p>Writing a blog <em>engine</em> in Go &amp; SQL.</p>"
+	if err := repo.Index(entries[0]); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		query  string
+		expect []*domain.SearchHit
+	}{
+		{
+			"engine",
+			[]*domain.SearchHit{
+				{ID: 1, Title: "Go", Snippet: "Writing a blog <mark>engine</mark> in Go &amp; SQL."},
+			},
+		},
+		{
+			"ブログ",
+			[]*domain.SearchHit{
+				{ID: 3, Title: "日記", Snippet: "今日は<mark>ブログ</mark>を書いた"},
+			},
+		},
+		{
+			"GO blog",
+			[]*domain.SearchHit{
+				{ID: 2, Title: "Blog", Snippet: "<mark>Go</mark> is good for the <mark>blog</mark>, <mark>go</mark> <mark>go</mark>."},
+				{ID: 1, Title: "Go", Snippet: "Writing a <mark>blog</mark> engine in <mark>Go</mark> &amp; SQL."},
+			},
+		},
+		{"unknown", []*domain.SearchHit{}},
+		{"go unknown", []*domain.SearchHit{}},
+		{"", []*domain.SearchHit{}},
+	}
+	for i, c := range cases {
+		hits, err := repo.Search(c.query)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		// the score depends on the index, only checks the order
+		for _, h := range hits {
+			if h.Score <= 0 {
+				t.Errorf("#%d: want positive score, got %v", i, h.Score)
+			}
+			h.Score = 0
+		}
+		if !reflect.DeepEqual(hits, c.expect) {
+			t.Errorf("#%d: want %v, got %v", i, c.expect, hits)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := "aaaa bbbb cccc dddd eeee ffff gggg hhhh iiii jjjj kkkk llll mmmm nnnn oooo pppp " +
+		"qqqq rrrr ssss tttt uuuu vvvv wwww xxxx yyyy zzzz keyword aaaa bbbb cccc dddd eeee " +
+		"ffff gggg hhhh iiii jjjj kkkk llll mmmm nnnn oooo pppp qqqq rrrr ssss tttt uuuu vvvv wwww xxxx end"
+	doc := &document{text: []rune(text), lower: []rune(text)}
+
+	act := snippet(doc, []string{"keyword"})
+	expect := "…ssss tttt uuuu vvvv wwww xxxx yyyy zzzz <mark>keyword</mark> aaaa bbbb cccc dddd eeee " +
+		"ffff gggg hhhh iiii jjjj kkkk llll mmmm nnnn oooo pppp qqqq rrrr ssss tttt uuuu vvvv ww…"
+	if act != expect {
+		t.Errorf("want %q, got %q", expect, act)
+	}
+}
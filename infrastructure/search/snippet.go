@@ -0,0 +1,109 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+)
+
+// Length of the snippet, and the leading context before the first matched word
+const (
+	snippetLength  = 160
+	snippetContext = 40
+)
+
+// span represent a range of the runes [start, end)
+type span struct {
+	start, end int
+}
+
+// snippet returns the part of the text around the first matched term
+// The text is HTML escaped, and the matched terms are surrounded by <mark> tags
+func snippet(doc *document, terms []string) string {
+	spans := matchSpans(doc.lower, terms)
+
+	start := 0
+	if len(spans) != 0 && spans[0].start > snippetContext {
+		start = spans[0].start - snippetContext
+	}
+	end := start + snippetLength
+	if end > len(doc.text) {
+		end = len(doc.text)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, s := range spans {
+		if s.end <= pos {
+			continue
+		}
+		if s.start >= end {
+			break
+		}
+		if s.start > pos {
+			b.WriteString(html.EscapeString(string(doc.text[pos:s.start])))
+		} else {
+			s.start = pos
+		}
+		if s.end > end {
+			s.end = end
+		}
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(doc.text[s.start:s.end])))
+		b.WriteString("</mark>")
+		pos = s.end
+	}
+	b.WriteString(html.EscapeString(string(doc.text[pos:end])))
+	if end < len(doc.text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// matchSpans returns the merged spans of the terms in order of the position
+func matchSpans(text []rune, terms []string) []span {
+	spans := make([]span, 0)
+	for _, t := range terms {
+		term := []rune(t)
+		wordOnly := !isCJK(term[0])
+		for i := 0; i+len(term) <= len(text); i++ {
+			if !hasPrefix(text[i:], term) {
+				continue
+			}
+			end := i + len(term)
+			// avoid to match a part of the word
+			if wordOnly && ((i > 0 && isWord(text[i-1]) && !isCJK(text[i-1])) ||
+				(end < len(text) && isWord(text[end]) && !isCJK(text[end]))) {
+				continue
+			}
+			spans = append(spans, span{i, end})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	merged := make([]span, 0, len(spans))
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+func hasPrefix(s, prefix []rune) bool {
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
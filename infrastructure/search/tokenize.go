@@ -0,0 +1,63 @@
+package search
+
+import (
+	"unicode"
+)
+
+// isCJK returns whether the rune is written without spaces between words
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isWord(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// toLower keeps the number of runes to share the offsets with the original text
+func toLower(r rune) rune {
+	return unicode.ToLower(r)
+}
+
+// tokenize splits the text into the lower-cased terms
+// Words are separated by spaces and symbols, and CJK texts are split into bigrams
+// The indexing texts have CJK unigrams as well to match the query of a single character
+func tokenize(s string, indexing bool) []string {
+	terms := make([]string, 0)
+	var word, cjk []rune
+
+	flushWord := func() {
+		if len(word) != 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 || (indexing && len(cjk) > 0) {
+			for _, r := range cjk {
+				terms = append(terms, string(r))
+			}
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range s {
+		r = toLower(r)
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case isWord(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// LocalStorage implements the AssetStorage on the local filesystem
+// The files are stored in the subdirectories named by the first 2 characters of the hash
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns initialized LocalStorage
+func NewLocalStorage(dir string) (repository.AssetStorage, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrapf(err, "failed to create the storage directory %s", dir)
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Put writes the file atomically, overwrites the file of the same hash
+func (s *LocalStorage) Put(hash string, r io.Reader) error {
+	// the hash is used as the file name, reject the path traversal
+	if !domain.IsAssetHash(hash) {
+		return errors.Errorf("invalid asset hash: %q", hash)
+	}
+	dir := filepath.Join(s.dir, hash[:2])
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(dir, ".upload-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, hash))
+}
+
+// Open returns the file matched by the hash
+func (s *LocalStorage) Open(hash string) (io.ReadCloser, error) {
+	if !domain.IsAssetHash(hash) {
+		return nil, domain.ErrNotFoundAsset
+	}
+	f, err := os.Open(filepath.Join(s.dir, hash[:2], hash))
+	if os.IsNotExist(err) {
+		return nil, domain.ErrNotFoundAsset
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
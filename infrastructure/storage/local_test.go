@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lumber-assets")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalStorage(filepath.Join(dir, "assets"))
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	hash := strings.Repeat("ab", 32)
+	if err := s.Put(hash, strings.NewReader("foo")); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if err := s.Put(hash, strings.NewReader("bar")); err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "assets", "ab", hash)); err != nil {
+		t.Errorf("want stored in the subdirectory, got %#v", err)
+	}
+
+	cases := []struct {
+		input      string
+		expectData string
+		expectErr  error
+	}{
+		{hash, "bar", nil},
+		{strings.Repeat("cd", 32), "", domain.ErrNotFoundAsset},
+		{"../../etc/passwd", "", domain.ErrNotFoundAsset},
+		{strings.Repeat("AB", 32), "", domain.ErrNotFoundAsset},
+	}
+	for i, c := range cases {
+		f, err := s.Open(c.input)
+		if err != c.expectErr {
+			t.Errorf("#%d: want error %#v, got %#v", i, c.expectErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		data, _ := ioutil.ReadAll(f)
+		f.Close()
+		if string(data) != c.expectData {
+			t.Errorf("#%d: want %q, got %q", i, c.expectData, data)
+		}
+	}
+
+	if err := s.Put("../x", strings.NewReader("foo")); err == nil {
+		t.Errorf("want error for the invalid hash, got nil")
+	}
+}
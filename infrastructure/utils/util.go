@@ -4,9 +4,28 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/library/config"
 )
 
+// Supported database drivers
+const (
+	DriverMySQL  = "mysql"
+	DriverSQLite = "sqlite3"
+)
+
+// ConnectDB returns sql.DB for the driver specified in the config
+func ConnectDB() (*sql.DB, error) {
+	switch driver := config.Config.DB.Driver; driver {
+	case DriverMySQL, "":
+		return ConnectMySQL()
+	case DriverSQLite:
+		return ConnectSQLite()
+	default:
+		return nil, errors.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
 // ConnectMySQL returns sql.DB for mysql
 func ConnectMySQL() (*sql.DB, error) {
 	conf := config.Config.DB
@@ -19,7 +38,7 @@ func ConnectMySQL() (*sql.DB, error) {
 		port     = conf.Port
 	)
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", user, password, host, port, name)
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open(DriverMySQL, dsn)
 	if err != nil {
 		return nil, err
 	}
@@ -31,3 +50,24 @@ func ConnectMySQL() (*sql.DB, error) {
 
 	return db, nil
 }
+
+// ConnectSQLite returns sql.DB for sqlite
+func ConnectSQLite() (*sql.DB, error) {
+	path := config.Config.DB.Path
+	if len(path) == 0 {
+		return nil, errors.New("require database file path for sqlite")
+	}
+
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=1&_busy_timeout=5000", path)
+	db, err := sql.Open(DriverSQLite, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	// sqlite allows only one writer at a time, therefore serialize connections
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
@@ -0,0 +1,37 @@
+package interfaces
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+// GetArchives returns the number of the entries in each month ordered by the newest
+func (h *EntryHandler) GetArchives(w http.ResponseWriter, r *http.Request) {
+	archives, err := h.entry.GetArchives(h.entryFilter(r))
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to get archives")
+		return
+	}
+
+	type response struct {
+		Data []*domain.Archive `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: archives})
+}
+
+// GetArchive returns the page of the entries created in the month in UTC
+// The order and the page are specified by the query parameters as well as GetTitlePage
+func (h *EntryHandler) GetArchive(w http.ResponseWriter, r *http.Request, year, month int) {
+	if year < 1 || month < 1 || month > 12 {
+		Error(w, http.StatusNotFound, nil, "invalid archive month")
+		return
+	}
+	since, until := domain.MonthRange(year, time.Month(month))
+	f := h.entryFilter(r)
+	f.Tags = r.URL.Query()["tag"]
+	f.Since = &since
+	f.Until = &until
+	h.respondTitlePage(w, r, f)
+}
@@ -0,0 +1,92 @@
+package interfaces
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestArchiveEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		path       string
+		token      string
+		expectCode int
+		expectBody []byte
+	}{
+		{
+			"/api/archive",
+			"foo",
+			http.StatusOK,
+			[]byte(`{"data":[{"year":2018,"month":3,"count":5}]}`),
+		},
+		{
+			"/api/archive",
+			"",
+			http.StatusOK,
+			[]byte(`{"data":[{"year":2018,"month":3,"count":4}]}`),
+		},
+		{
+			"/api/archive/2018/3?order=desc&limit=2",
+			"",
+			http.StatusOK,
+			[]byte(`{"data":[{"id":4,"title":"b","created_at":"2018-03-04T00:00:00Z","updated_at":"2018-03-07T00:00:00Z"},{"id":3,"title":"e","created_at":"2018-03-02T00:00:00Z","updated_at":"2018-03-09T00:00:00Z"}],"total":4,"next":"eyJrIjoiY3JlYXRlZF9hdCIsImQiOnRydWUsImlkIjozLCJhdCI6IjIwMTgtMDMtMDJUMDA6MDA6MDBaIn0"}`),
+		},
+		{
+			"/api/archive/2018/4",
+			"foo",
+			http.StatusOK,
+			[]byte(`{"data":[],"total":0}`),
+		},
+		{
+			"/api/archive/2018/13",
+			"foo",
+			http.StatusNotFound,
+			[]byte(`{"reason":"invalid archive month"}`),
+		},
+		{
+			"/api/entries?since=2018-03-02T00:00:00Z&until=2018-03-05T00:00:00Z",
+			"foo",
+			http.StatusOK,
+			[]byte(`{"ids":[2,3,4]}`),
+		},
+		{
+			"/api/entries?since=2018-03-04T09:00:00%2B09:00",
+			"foo",
+			http.StatusOK,
+			[]byte(`{"ids":[4,5]}`),
+		},
+		{
+			"/api/entries?since=yesterday",
+			"foo",
+			http.StatusBadRequest,
+			[]byte(`{"reason":"invalid query parameter"}`),
+		},
+		{
+			"/api/titles?since=2018-03-05T00:00:00Z&until=2018-03-02T00:00:00Z",
+			"foo",
+			http.StatusBadRequest,
+			[]byte(`{"reason":"invalid query parameter"}`),
+		},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/page_entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", ts.URL+c.path, c.token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		act, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(act, c.expectBody) {
+			t.Errorf("#%d: want %s, got %s", i, c.expectBody, act)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package interfaces
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// multipartOverheadBytes is the allowance of the multipart headers in addition to the file
+const multipartOverheadBytes = 1 << 20
+
+// AssetHandler provides handler for the uploaded files
+type AssetHandler struct {
+	asset    *application.AssetInteractor
+	maxBytes int64
+}
+
+// NewAssetHandler returns initialized AssetHandler
+func NewAssetHandler(a repository.AssetRepository, s repository.AssetStorage, maxBytes int64) *AssetHandler {
+	return &AssetHandler{
+		asset:    application.NewAssetInteractor(a, s, maxBytes),
+		maxBytes: maxBytes,
+	}
+}
+
+// Post uploads the file of the multipart form field "file"
+// Requires the user by requireUser
+func (h *AssetHandler) Post(w http.ResponseWriter, r *http.Request) {
+	if !requestUser(r).CanWrite() {
+		Error(w, http.StatusForbidden, nil, "insufficient privileges")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+multipartOverheadBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "require the file")
+		return
+	}
+	defer file.Close()
+
+	asset, err := h.asset.Upload(header.Filename, file)
+	if err != nil {
+		switch errors.Cause(err) {
+		case config.ErrEmptyAsset:
+			Error(w, http.StatusBadRequest, err, "the file is empty")
+		case config.ErrAssetSizeLimitExceeded:
+			Error(w, http.StatusRequestEntityTooLarge, err, "the file is too large")
+		case config.ErrUnsupportedAssetType:
+			Error(w, http.StatusUnsupportedMediaType, err, "unsupported file type")
+		default:
+			Error(w, http.StatusInternalServerError, err, "failed to upload the file")
+		}
+		return
+	}
+
+	w.Header().Set("Location", asset.Path())
+	JSON(w, http.StatusCreated, newAssetResponse(asset))
+}
+
+// assetResponse represent the asset with the URL to get the file
+type assetResponse struct {
+	*domain.Asset
+	URL string `json:"url"`
+}
+
+func newAssetResponse(asset *domain.Asset) *assetResponse {
+	return &assetResponse{
+		Asset: asset,
+		URL:   asset.Path(),
+	}
+}
+
+// GetInfo returns the metadata of the asset matched by the hash
+// Used by the client to skip uploading the same content
+func (h *AssetHandler) GetInfo(w http.ResponseWriter, r *http.Request, hash string) {
+	asset, err := h.asset.Find(hash)
+	if err != nil {
+		if err == domain.ErrNotFoundAsset {
+			Error(w, http.StatusNotFound, err, "not found the asset")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to get the asset")
+		return
+	}
+	JSON(w, http.StatusOK, newAssetResponse(asset))
+}
+
+// Get serves the file matched by the hash
+// The content of the hash never changes, therefore allows caching forever
+func (h *AssetHandler) Get(w http.ResponseWriter, r *http.Request, hash string) {
+	asset, body, err := h.asset.Get(hash)
+	if err != nil {
+		if err == domain.ErrNotFoundAsset {
+			Error(w, http.StatusNotFound, err, "not found the asset")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to get the asset")
+		return
+	}
+	defer body.Close()
+
+	etag := fmt.Sprintf(`"%s"`, asset.Hash)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if notModified(r, etag, time.Time{}) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", asset.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(asset.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, body)
+}
@@ -0,0 +1,110 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"testing"
+)
+
+// multipartBody returns the multipart form body of the file and the content type
+func multipartBody(t *testing.T, name string, data []byte) (io.Reader, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	part.Write(data)
+	w.Close()
+	return &buf, w.FormDataContentType()
+}
+
+func TestPostAndGetAsset(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/tokens.yml")
+
+	png, err := ioutil.ReadFile("testdata/image.png")
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		token      string
+		name       string
+		data       []byte
+		expectCode int
+	}{
+		{"foo", "image.png", png, http.StatusCreated},
+		{"", "image.png", png, http.StatusUnauthorized},
+		{"foo", "index.html", []byte("<html></html>"), http.StatusUnsupportedMediaType},
+		{"foo", "large.png", append(png, make([]byte, 1024)...), http.StatusRequestEntityTooLarge},
+		{"foo", "empty.png", []byte{}, http.StatusBadRequest},
+	}
+	for i, c := range cases {
+		body, contentType := multipartBody(t, c.name, c.data)
+		req, err := http.NewRequest("POST", ts.URL+"/api/assets", body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		if len(c.token) != 0 {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if res.StatusCode != http.StatusCreated {
+			continue
+		}
+
+		asset := struct {
+			Hash        string `json:"hash"`
+			ContentType string `json:"content_type"`
+			URL         string `json:"url"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&asset); err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if asset.URL != "/assets/"+asset.Hash || asset.ContentType != "image/png" {
+			t.Errorf("#%d: want url of the hash and image/png, got %#v", i, asset)
+		}
+
+		get := sendRequest(t, "GET", ts.URL+asset.URL, nil)
+		defer get.Body.Close()
+		data, _ := ioutil.ReadAll(get.Body)
+		if get.StatusCode != http.StatusOK || !bytes.Equal(data, c.data) {
+			t.Errorf("#%d: want %d and the uploaded data, got %d and %q", i, http.StatusOK, get.StatusCode, data)
+		}
+		if ct := get.Header.Get("Content-Type"); ct != "image/png" {
+			t.Errorf("#%d: want content type image/png, got %s", i, ct)
+		}
+
+		req, _ = http.NewRequest("GET", ts.URL+asset.URL, nil)
+		req.Header.Set("If-None-Match", get.Header.Get("ETag"))
+		cached, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		cached.Body.Close()
+		if cached.StatusCode != http.StatusNotModified {
+			t.Errorf("#%d: want %d, got %d", i, http.StatusNotModified, cached.StatusCode)
+		}
+	}
+
+	res := sendRequest(t, "GET", ts.URL+"/assets/unknown", nil)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("want %d, got %d", http.StatusNotFound, res.StatusCode)
+	}
+}
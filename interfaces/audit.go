@@ -0,0 +1,95 @@
+package interfaces
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// AuditHandler provides handler for the audit logs, only for the admin
+type AuditHandler struct {
+	audit *application.AuditInteractor
+}
+
+// NewAuditHandler returns initialized AuditHandler
+func NewAuditHandler(a repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{
+		audit: application.NewAuditInteractor(a),
+	}
+}
+
+// List returns the audit logs ordered by the newest
+// Filtered by the "action", "entry_id", "user_id", "token_id", "since", "until" and "limit" query parameters
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	f, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	logs, err := h.audit.Find(f)
+	if err != nil {
+		if errors.Cause(err) == config.ErrInvalidAuditFilter {
+			Error(w, http.StatusBadRequest, err, "invalid query parameter")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to get audit logs")
+		return
+	}
+
+	type response struct {
+		Data []*domain.AuditLog `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: logs})
+}
+
+// parseAuditFilter returns the filter of the query parameters
+// The times are formatted in RFC3339
+func parseAuditFilter(q url.Values) (repository.AuditFilter, error) {
+	f := repository.AuditFilter{
+		Action: domain.AuditAction(q.Get("action")),
+	}
+	ints := map[string]*int{
+		"entry_id": &f.EntryID,
+		"user_id":  &f.UserID,
+		"token_id": &f.TokenID,
+		"limit":    &f.Limit,
+	}
+	for key, dst := range ints {
+		v := q.Get(key)
+		if len(v) == 0 {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return f, errors.Wrapf(err, "%s: %s", key, v)
+		}
+		*dst = n
+	}
+	times := map[string]**time.Time{
+		"since": &f.Since,
+		"until": &f.Until,
+	}
+	for key, dst := range times {
+		v := q.Get(key)
+		if len(v) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.Wrapf(err, "%s: %s", key, v)
+		}
+		*dst = &t
+	}
+	return f, nil
+}
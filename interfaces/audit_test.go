@@ -0,0 +1,105 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/infrastructure/memory"
+)
+
+func TestAudit(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	auditRepository.(*memory.AuditRepositoryImpl).Reset()
+
+	// the editor edits and deletes the entry 2, the admin deletes the entry 1
+	requests := []struct {
+		method string
+		path   string
+		token  string
+		body   string
+	}{
+		{"PUT", "/api/entry/2", "editor", `{"data":"IyB0aXRsZQoKY29udGVudA=="}`},
+		{"DELETE", "/api/entry/2", "editor", ""},
+		{"DELETE", "/api/entry/1", "admin", ""},
+		// failed operations are not recorded
+		{"DELETE", "/api/entry/1", "viewer", ""},
+	}
+	for i, r := range requests {
+		res := sendAuthRequest(t, r.method, ts.URL+r.path, r.token, bytes.NewBufferString(r.body))
+		res.Body.Close()
+		if i < 3 && res.StatusCode != http.StatusOK {
+			t.Fatalf("#%d: want %d, got %d", i, http.StatusOK, res.StatusCode)
+		}
+	}
+
+	cases := []struct {
+		query         string
+		token         string
+		expectCode    int
+		expectActions []domain.AuditAction
+	}{
+		{"", "admin", http.StatusOK, []domain.AuditAction{domain.AuditActionDelete, domain.AuditActionDelete, domain.AuditActionEdit}},
+		{"?entry_id=2", "admin", http.StatusOK, []domain.AuditAction{domain.AuditActionDelete, domain.AuditActionEdit}},
+		{"?user_id=2&action=edit", "admin", http.StatusOK, []domain.AuditAction{domain.AuditActionEdit}},
+		{"?token_id=3", "admin", http.StatusOK, []domain.AuditAction{domain.AuditActionDelete}},
+		{"?limit=1", "admin", http.StatusOK, []domain.AuditAction{domain.AuditActionDelete}},
+		{"?since=2000-01-01T00:00:00Z&until=2001-01-01T00:00:00Z", "admin", http.StatusOK, []domain.AuditAction{}},
+		{"?entry_id=foo", "admin", http.StatusBadRequest, nil},
+		{"?since=yesterday", "admin", http.StatusBadRequest, nil},
+		{"?action=publish", "admin", http.StatusBadRequest, nil},
+		{"", "editor", http.StatusForbidden, nil},
+		{"", "", http.StatusUnauthorized, nil},
+	}
+	for i, c := range cases {
+		res := sendAuthRequest(t, "GET", ts.URL+"/api/audit"+c.query, c.token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if c.expectCode != http.StatusOK {
+			continue
+		}
+		body := struct {
+			Data []*domain.AuditLog `json:"data"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+			t.Fatalf("#%d: want non error, got %v", i, err)
+		}
+		actions := []domain.AuditAction{}
+		for _, l := range body.Data {
+			actions = append(actions, l.Action)
+		}
+		if !reflect.DeepEqual(actions, c.expectActions) {
+			t.Errorf("#%d: want actions %v, got %v", i, c.expectActions, actions)
+		}
+	}
+
+	// the actor is the user of the token and the remote address of the request
+	res := sendAuthRequest(t, "GET", ts.URL+"/api/audit?action=edit", "admin", nil)
+	defer res.Body.Close()
+	body := struct {
+		Data []*domain.AuditLog `json:"data"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	if len(body.Data) != 1 {
+		t.Fatalf("want 1 log, got %d", len(body.Data))
+	}
+	edited := body.Data[0]
+	if edited.UserID != 2 || edited.TokenID != 4 || edited.RemoteAddr != "127.0.0.1" {
+		t.Errorf("want the actor of the editor, got %#v", edited)
+	}
+	if len(edited.BeforeHash) == 0 || len(edited.AfterHash) == 0 || edited.BeforeHash == edited.AfterHash {
+		t.Errorf("want changed content hashes, got %s and %s", edited.BeforeHash, edited.AfterHash)
+	}
+}
@@ -0,0 +1,130 @@
+package interfaces
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// contextKey is the type of the keys of the request context
+type contextKey int
+
+const credentialContextKey contextKey = iota
+
+// credential is the result of the authentication stored in the request context
+type credential struct {
+	user    *domain.User
+	tokenID int
+}
+
+// AuthMiddleware authenticates the requests by the token of the "Authorization: Bearer" header
+type AuthMiddleware struct {
+	auth *application.AuthInteractor
+	// allowQueryToken accepts the deprecated "token" query parameter as well
+	allowQueryToken bool
+}
+
+// NewAuthMiddleware returns initialized AuthMiddleware
+func NewAuthMiddleware(t repository.TokenRepository, u repository.UserRepository, allowQueryToken bool) *AuthMiddleware {
+	return &AuthMiddleware{
+		auth:            application.NewAuthInteractor(t, u),
+		allowQueryToken: allowQueryToken,
+	}
+}
+
+// Handler stores the user and the token in the request context
+// The requests without the valid token are passed through as the anonymous requests
+func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := m.requestToken(r)
+		if len(token) != 0 {
+			user, t, err := m.auth.AuthenticateToken(token)
+			if err == nil {
+				cred := &credential{user: user, tokenID: t.ID}
+				r = r.WithContext(context.WithValue(r.Context(), credentialContextKey, cred))
+			} else {
+				printDebugf("failed to authenticate: %v", err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestToken returns the token of the "Authorization: Bearer" header
+// Falls back to the "token" query parameter only when allowed
+func (m *AuthMiddleware) requestToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); len(header) != 0 {
+		fields := strings.Fields(header)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], "Bearer") {
+			return ""
+		}
+		return fields[1]
+	}
+	if !m.allowQueryToken {
+		return ""
+	}
+	token := r.URL.Query().Get("token")
+	if len(token) != 0 {
+		log.Printf("[WARN] the token query parameter is deprecated, use the Authorization header instead. path: %s", r.URL.Path)
+	}
+	return token
+}
+
+// requestUser returns the user authenticated by AuthMiddleware, nil for the anonymous requests
+func requestUser(r *http.Request) *domain.User {
+	cred, ok := r.Context().Value(credentialContextKey).(*credential)
+	if !ok {
+		return nil
+	}
+	return cred.user
+}
+
+// requestActor returns the actor of the request recorded by the audit logs
+func requestActor(r *http.Request) domain.Actor {
+	actor := domain.Actor{RemoteAddr: r.RemoteAddr}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		actor.RemoteAddr = host
+	}
+	if cred, ok := r.Context().Value(credentialContextKey).(*credential); ok {
+		actor.UserID = cred.user.ID
+		actor.TokenID = cred.tokenID
+	}
+	return actor
+}
+
+// requireUser wraps the handler of the router to respond 401 to the anonymous requests
+// The handler is any function accepted by the router, and called with the same arguments
+func requireUser(handler interface{}) interface{} {
+	fn := reflect.ValueOf(handler)
+	return reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		w := args[0].Interface().(http.ResponseWriter)
+		r := args[1].Interface().(*http.Request)
+		if requestUser(r) == nil {
+			Error(w, http.StatusUnauthorized, nil, "failed to authorized")
+			return nil
+		}
+		return fn.Call(args)
+	}).Interface()
+}
+
+// authorizeAdmin returns whether the user of the request is the admin
+// Responds the error when it isn't
+func authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	user := requestUser(r)
+	if user == nil {
+		Error(w, http.StatusUnauthorized, nil, "failed to authorized")
+		return false
+	}
+	if !user.IsAdmin() {
+		Error(w, http.StatusForbidden, nil, "insufficient privileges")
+		return false
+	}
+	return true
+}
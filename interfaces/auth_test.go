@@ -0,0 +1,86 @@
+package interfaces
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	cases := []struct {
+		allowQueryToken bool
+		path            string
+		header          string
+		expectCode      int
+	}{
+		{false, "/api/entry/1/preview", "Bearer foo", http.StatusOK},
+		{false, "/api/entry/1/preview", "bearer foo", http.StatusOK},
+		{false, "/api/entry/1/preview", "Bearer unknown", http.StatusUnauthorized},
+		{false, "/api/entry/1/preview", "Basic Zm9vOmJhcg==", http.StatusUnauthorized},
+		{false, "/api/entry/1/preview", "", http.StatusUnauthorized},
+		// the query parameter is deprecated
+		{false, "/api/entry/1/preview?token=foo", "", http.StatusUnauthorized},
+		{true, "/api/entry/1/preview?token=foo", "", http.StatusOK},
+		{true, "/api/entry/1/preview?token=foo", "Bearer unknown", http.StatusUnauthorized},
+	}
+	for i, c := range cases {
+		server := &Server{
+			Auth:  NewAuthMiddleware(tokenRepository, userRepository, c.allowQueryToken),
+			Entry: NewEntryHandler(entryRepository, searchRepository, auditRepository, renderer),
+		}
+		ts := httptest.NewServer(server.Routes())
+		defer ts.Close()
+
+		req, err := http.NewRequest("GET", ts.URL+c.path, nil)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if len(c.header) != 0 {
+			req.Header.Set("Authorization", c.header)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/api/entry/"},
+		{"PUT", "/api/entry/1"},
+		{"DELETE", "/api/entry/1"},
+		{"POST", "/api/entry/1/revert/1"},
+		{"POST", "/api/assets"},
+		{"POST", "/api/users"},
+		{"PUT", "/api/users/1"},
+		{"DELETE", "/api/users/1"},
+		{"POST", "/api/tokens"},
+		{"DELETE", "/api/tokens/1"},
+		{"POST", "/api/tokens/1/rotate"},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, "unknown", nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusUnauthorized {
+			t.Errorf("#%d: want %d, got %d", i, http.StatusUnauthorized, res.StatusCode)
+		}
+	}
+}
@@ -1,10 +1,20 @@
 package interfaces
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/infrastructure/git"
 	"github.com/takashabe/lumber/infrastructure/persistence"
+	"github.com/takashabe/lumber/infrastructure/search"
+	"github.com/takashabe/lumber/infrastructure/storage"
 	"github.com/takashabe/lumber/library/config"
 )
 
@@ -15,6 +25,7 @@ const (
 	// Specific error codes. begin 10-
 	ExitCodeError = 10 + iota
 	ExitCodeSetupServerError
+	ExitCodeNotFoundCommandError
 )
 
 // CLI is the command line interface object
@@ -24,23 +35,227 @@ type CLI struct {
 }
 
 // Run invokes the CLI with the given arguments
+// Runs the server unless the subcommand is specified
 func (c *CLI) Run(args []string) int {
-	conf := config.Config.Server
-
 	entryRepository, err := persistence.NewEntryRepository()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized persistence repository: %v", err)
+		return ExitCodeSetupServerError
+	}
 	tokenRepository, err := persistence.NewTokenRepository()
 	if err != nil {
 		fmt.Fprintf(c.ErrStream, "failed to initialized persistence repository: %v", err)
 		return ExitCodeSetupServerError
 	}
+	userRepository, err := persistence.NewUserRepository()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized persistence repository: %v", err)
+		return ExitCodeSetupServerError
+	}
+	auditRepository, err := persistence.NewAuditRepository()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized persistence repository: %v", err)
+		return ExitCodeSetupServerError
+	}
+	renderer, err := application.NewRenderer(strings.Split(config.Config.Renderer.Extensions, ",")...)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized markdown renderer: %v", err)
+		return ExitCodeSetupServerError
+	}
+	sanitizer := newSanitizer()
+	renderer = application.NewSanitizedRenderer(renderer, sanitizer)
+
+	if len(args) > 1 {
+		switch args[1] {
+		case "rerender":
+			return c.rerender(entryRepository, auditRepository, renderer)
+		case "sanitize":
+			return c.sanitize(entryRepository, auditRepository, sanitizer)
+		case "token":
+			return c.token(tokenRepository, userRepository, args[2:])
+		default:
+			fmt.Fprintf(c.ErrStream, "unknown command: %s\n", args[1])
+			return ExitCodeNotFoundCommandError
+		}
+	}
+	return c.serve(entryRepository, tokenRepository, userRepository, auditRepository, renderer)
+}
+
+// newSourceInteractor returns the SourceInteractor of the configured repository for the webhook
+// Returns nil when the webhook is not configured
+func newSourceInteractor(
+	entryRepository repository.EntryRepository,
+	searchRepository repository.SearchRepository,
+	auditRepository repository.AuditRepository,
+	assetRepository repository.AssetRepository,
+	assetStorage repository.AssetStorage,
+	renderer application.Renderer,
+) (*application.SourceInteractor, error) {
+	conf := config.Config.Webhook
+	if len(conf.Secret) == 0 || len(conf.Repository) == 0 {
+		return nil, nil
+	}
+	sourceRepository, err := git.NewRepository(conf.Repository)
+	if err != nil {
+		return nil, err
+	}
+	pathRepository, err := persistence.NewEntryPathRepository()
+	if err != nil {
+		return nil, err
+	}
+	return application.NewSourceInteractor(
+		application.NewEntryInteractor(entryRepository, searchRepository, auditRepository),
+		application.NewAssetInteractor(assetRepository, assetStorage, config.Config.Asset.MaxBytes),
+		pathRepository,
+		sourceRepository,
+		renderer,
+		conf.Branch,
+	), nil
+}
+
+// newAssetStorage returns the AssetStorage of the configured backend
+func newAssetStorage() (repository.AssetStorage, error) {
+	conf := config.Config.Asset
+	switch conf.Storage {
+	case "local":
+		return storage.NewLocalStorage(conf.Dir)
+	default:
+		return nil, errors.Errorf("unknown asset storage: %s", conf.Storage)
+	}
+}
+
+// rerender renders all entries again from the source markdown
+// The search index of the running server is refreshed by restarting it
+func (c *CLI) rerender(entryRepository repository.EntryRepository, auditRepository repository.AuditRepository, renderer application.Renderer) int {
+	n, err := application.NewEntryInteractor(entryRepository, search.NewSearchRepository(), auditRepository).Rerender(renderer)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to rerender entries: %v\n", err)
+		return ExitCodeError
+	}
+	fmt.Fprintf(c.OutStream, "succeed rerender entries. updated=%d\n", n)
+	return ExitCodeOK
+}
+
+// sanitize removes the disallowed HTML from all entries
+// The search index of the running server is refreshed by restarting it
+func (c *CLI) sanitize(entryRepository repository.EntryRepository, auditRepository repository.AuditRepository, sanitizer *application.Sanitizer) int {
+	n, err := application.NewEntryInteractor(entryRepository, search.NewSearchRepository(), auditRepository).Sanitize(sanitizer)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to sanitize entries: %v\n", err)
+		return ExitCodeError
+	}
+	fmt.Fprintf(c.OutStream, "succeed sanitize entries. updated=%d\n", n)
+	return ExitCodeOK
+}
+
+// newSanitizer returns the Sanitizer of the default policy extended by the config
+func newSanitizer() *application.Sanitizer {
+	conf := config.Config.Sanitizer
+	policy := application.DefaultSanitizePolicy()
+	for _, e := range splitList(conf.Elements) {
+		names := strings.Split(e, ":")
+		policy.Elements[names[0]] = append(policy.Elements[names[0]], names[1:]...)
+	}
+	policy.IframeHosts = splitList(conf.IframeHosts)
+	policy.EmbedHosts = splitList(conf.EmbedHosts)
+	return application.NewSanitizer(policy)
+}
+
+// splitList returns the non-empty values of the comma separated list
+func splitList(s string) []string {
+	list := make([]string, 0)
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); len(v) != 0 {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func (c *CLI) serve(
+	entryRepository repository.EntryRepository,
+	tokenRepository repository.TokenRepository,
+	userRepository repository.UserRepository,
+	auditRepository repository.AuditRepository,
+	renderer application.Renderer,
+) int {
+	conf := config.Config.Server
+	site := config.Config.Site
+
+	// the search index is on memory, rebuild from the all entries
+	searchRepository := search.NewSearchRepository()
+	err := application.NewEntryInteractor(entryRepository, searchRepository, auditRepository).RebuildIndex()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to build search index: %v", err)
+		return ExitCodeSetupServerError
+	}
+
+	assetRepository, err := persistence.NewAssetRepository()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized persistence repository: %v", err)
+		return ExitCodeSetupServerError
+	}
+	assetStorage, err := newAssetStorage()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized asset storage: %v", err)
+		return ExitCodeSetupServerError
+	}
+
+	sourceInteractor, err := newSourceInteractor(entryRepository, searchRepository, auditRepository, assetRepository, assetStorage, renderer)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to initialized source repository: %v", err)
+		return ExitCodeSetupServerError
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	publisher := application.NewPublisher(entryRepository, time.Duration(conf.PublishInterval)*time.Second)
+	go publisher.Run(ctx)
+
+	if conf.AllowQueryToken {
+		log.Printf("[WARN] the token query parameter is deprecated and will be removed, use the Authorization header instead")
+	}
 
 	server := Server{
+		Auth: NewAuthMiddleware(
+			tokenRepository,
+			userRepository,
+			conf.AllowQueryToken,
+		),
 		Entry: NewEntryHandler(
 			entryRepository,
-			tokenRepository,
+			searchRepository,
+			auditRepository,
+			renderer,
 		),
 		Token: NewTokenHandler(
 			tokenRepository,
+			userRepository,
+		),
+		Feed: NewFeedHandler(
+			entryRepository,
+			application.Site{
+				Title:       site.Title,
+				Description: site.Description,
+				URL:         site.URL,
+				Author:      site.Author,
+			},
+			site.FeedLength,
+		),
+		Asset: NewAssetHandler(
+			assetRepository,
+			assetStorage,
+			config.Config.Asset.MaxBytes,
+		),
+		Webhook: NewWebhookHandler(
+			sourceInteractor,
+			config.Config.Webhook.Secret,
+		),
+		User: NewUserHandler(
+			userRepository,
+		),
+		Audit: NewAuditHandler(
+			auditRepository,
 		),
 	}
 
@@ -0,0 +1,173 @@
+package interfaces
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// tokenUsage is the usage of the token subcommands
+const tokenUsage = `usage: lumber token <command>
+  create [-user NAME] [-label LABEL] [-expires DURATION]
+      create a token, owned by the user when specified, otherwise has the privileges of the admin
+  list
+      list the tokens
+  revoke ID
+      delete the token
+  rotate ID
+      replace the value of the token
+  migrate
+      hash the plain values of the tokens created before hashing
+`
+
+// token manages the tokens offline, e.g. to create the first token
+func (c *CLI) token(tokenRepository repository.TokenRepository, userRepository repository.UserRepository, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.ErrStream, tokenUsage)
+		return ExitCodeNotFoundCommandError
+	}
+
+	interactor := application.NewTokenInteractor(tokenRepository, userRepository)
+	switch args[0] {
+	case "create":
+		return c.createToken(interactor, userRepository, args[1:])
+	case "list":
+		return c.listToken(interactor, userRepository)
+	case "revoke":
+		id, ok := c.parseTokenID(args[1:])
+		if !ok {
+			return ExitCodeError
+		}
+		if err := interactor.Revoke(id); err != nil {
+			fmt.Fprintf(c.ErrStream, "failed to revoke token: %v\n", err)
+			return ExitCodeError
+		}
+		fmt.Fprintf(c.OutStream, "succeed revoke token. id=%d\n", id)
+		return ExitCodeOK
+	case "rotate":
+		id, ok := c.parseTokenID(args[1:])
+		if !ok {
+			return ExitCodeError
+		}
+		token, err := interactor.Rotate(id)
+		if err != nil {
+			fmt.Fprintf(c.ErrStream, "failed to rotate token: %v\n", err)
+			return ExitCodeError
+		}
+		fmt.Fprintf(c.OutStream, "succeed rotate token. id=%d\n%s\n", token.ID, token.Value)
+		return ExitCodeOK
+	case "migrate":
+		n, err := interactor.Migrate()
+		if err != nil {
+			fmt.Fprintf(c.ErrStream, "failed to migrate tokens: %v\n", err)
+			return ExitCodeError
+		}
+		fmt.Fprintf(c.OutStream, "succeed migrate tokens. updated=%d\n", n)
+		return ExitCodeOK
+	default:
+		fmt.Fprintf(c.ErrStream, "unknown token command: %s\n%s", args[0], tokenUsage)
+		return ExitCodeNotFoundCommandError
+	}
+}
+
+func (c *CLI) createToken(interactor *application.TokenInteractor, userRepository repository.UserRepository, args []string) int {
+	var (
+		name    string
+		label   string
+		expires time.Duration
+	)
+	flags := flag.NewFlagSet("token create", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.StringVar(&name, "user", "", "name of the user owned the token")
+	flags.StringVar(&label, "label", "", "label of the token")
+	flags.DurationVar(&expires, "expires", 0, "duration until the token expires, never expires by default")
+	if err := flags.Parse(args); err != nil {
+		fmt.Fprintf(c.ErrStream, "invalid arguments: %v\n%s", err, tokenUsage)
+		return ExitCodeError
+	}
+
+	var userID int
+	if len(name) != 0 {
+		user, err := userRepository.FindByName(name)
+		if err != nil {
+			fmt.Fprintf(c.ErrStream, "failed to get user: %v\n", err)
+			return ExitCodeError
+		}
+		userID = user.ID
+	}
+	var expiresAt *time.Time
+	if expires != 0 {
+		t := time.Now().Add(expires)
+		expiresAt = &t
+	}
+
+	token, err := interactor.Create(userID, label, expiresAt)
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to create token: %v\n", err)
+		return ExitCodeError
+	}
+	fmt.Fprintf(c.OutStream, "succeed create token. id=%d\n%s\n", token.ID, token.Value)
+	return ExitCodeOK
+}
+
+func (c *CLI) listToken(interactor *application.TokenInteractor, userRepository repository.UserRepository) int {
+	tokens, err := interactor.List()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to get tokens: %v\n", err)
+		return ExitCodeError
+	}
+	users, err := userRepository.List()
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "failed to get users: %v\n", err)
+		return ExitCodeError
+	}
+	names := make(map[int]string)
+	for _, u := range users {
+		names[u.ID] = u.Name
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.Local().Format(time.RFC3339)
+	}
+	w := tabwriter.NewWriter(c.OutStream, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tPREFIX\tUSER\tLABEL\tCREATED\tLAST USED\tEXPIRES")
+	for _, t := range tokens {
+		user := "-"
+		if t.UserID != 0 {
+			user = names[t.UserID]
+		}
+		prefix, label := t.Prefix, t.Label
+		if len(prefix) == 0 {
+			prefix = "-"
+		}
+		if len(label) == 0 {
+			label = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, prefix, user, label, formatTime(&t.CreatedAt), formatTime(t.LastUsedAt), formatTime(t.ExpiresAt))
+	}
+	w.Flush()
+	return ExitCodeOK
+}
+
+// parseTokenID returns the id of the token of the first argument
+func (c *CLI) parseTokenID(args []string) (int, bool) {
+	if len(args) == 0 {
+		fmt.Fprintf(c.ErrStream, "require the token id\n%s", tokenUsage)
+		return 0, false
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Fprintf(c.ErrStream, "invalid token id: %s\n", args[0])
+		return 0, false
+	}
+	return id, true
+}
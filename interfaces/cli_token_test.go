@@ -0,0 +1,40 @@
+package interfaces
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTokenCLI(t *testing.T) {
+	cases := []struct {
+		args         []string
+		expectCode   int
+		expectOutput string
+	}{
+		{[]string{"create", "-user", "carol", "-label", "laptop", "-expires", "24h"}, ExitCodeOK, "succeed create token. id=7\n"},
+		{[]string{"create", "-user", "unknown"}, ExitCodeError, ""},
+		{[]string{"create", "-expires", "-1h"}, ExitCodeError, ""},
+		{[]string{"list"}, ExitCodeOK, "\n3   -       alice  -      "},
+		{[]string{"rotate", "3"}, ExitCodeOK, "succeed rotate token. id=3\n"},
+		{[]string{"rotate", "99"}, ExitCodeError, ""},
+		{[]string{"revoke", "3"}, ExitCodeOK, "succeed revoke token. id=3\n"},
+		{[]string{"revoke", "foo"}, ExitCodeError, ""},
+		{[]string{"migrate"}, ExitCodeOK, "succeed migrate tokens. updated=6\n"},
+		{[]string{"unknown"}, ExitCodeNotFoundCommandError, ""},
+		{[]string{}, ExitCodeNotFoundCommandError, ""},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		out := &bytes.Buffer{}
+		cli := &CLI{OutStream: out, ErrStream: &bytes.Buffer{}}
+		code := cli.token(tokenRepository, userRepository, c.args)
+		if code != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, code)
+		}
+		if !strings.Contains(out.String(), c.expectOutput) {
+			t.Errorf("#%d: want output %q, got %q", i, c.expectOutput, out.String())
+		}
+	}
+}
@@ -3,7 +3,13 @@ package interfaces
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -15,31 +21,101 @@ import (
 
 // EntryHandler provides handler for the entry
 type EntryHandler struct {
-	entry *application.EntryInteractor
-	auth  *application.AuthInteractor
+	entry    *application.EntryInteractor
+	renderer application.Renderer
 }
 
 // NewEntryHandler returns initialized EntryHandler
-func NewEntryHandler(e repository.EntryRepository, t repository.TokenRepository) *EntryHandler {
+func NewEntryHandler(e repository.EntryRepository, s repository.SearchRepository, a repository.AuditRepository, r application.Renderer) *EntryHandler {
 	return &EntryHandler{
-		entry: application.NewEntryInteractor(e),
-		auth:  application.NewAuthInteractor(t),
+		entry:    application.NewEntryInteractor(e, s, a),
+		renderer: r,
 	}
 }
 
 // Get returns entry when matched id
 func (h *EntryHandler) Get(w http.ResponseWriter, r *http.Request, id int) {
-	entry, err := h.entry.Get(id)
+	entry, err := h.entry.Get(id, h.entryFilter(r))
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to get entry")
 		return
 	}
-	JSON(w, http.StatusOK, entry)
+	respondEntry(w, r, entry)
+}
+
+// respondEntry writes the source markdown when requested by "Accept: text/markdown", otherwise JSON
+func respondEntry(w http.ResponseWriter, r *http.Request, entry *domain.Entry) {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("ETag", entry.ETag())
+	if !acceptMarkdown(r) {
+		JSON(w, http.StatusOK, entry)
+		return
+	}
+	if len(entry.Source) == 0 {
+		Error(w, http.StatusNotAcceptable, nil, "not found the source of the entry")
+		return
+	}
+	w.Header().Set("Content-Type", "text/markdown; charset=UTF-8")
+	// the source is not sanitized, must not be sniffed as HTML
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, entry.Source)
+}
+
+// acceptMarkdown returns whether the Accept header contains the markdown media type
+func acceptMarkdown(r *http.Request) bool {
+	for _, v := range strings.Split(r.Header.Get("Accept"), ",") {
+		t, _, err := mime.ParseMediaType(v)
+		if err == nil && t == "text/markdown" {
+			return true
+		}
+	}
+	return false
+}
+
+// Preview returns entry regardless of the status, for checking the drafts
+func (h *EntryHandler) Preview(w http.ResponseWriter, r *http.Request, id int) {
+	if requestUser(r) == nil {
+		Error(w, http.StatusUnauthorized, nil, "failed to authorized")
+		return
+	}
+
+	entry, err := h.entry.Lookup(id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get entry")
+		return
+	}
+	respondEntry(w, r, entry)
+}
+
+// GetBySlug returns entry matched the slug
+// Redirects to the current slug when the slug has been changed
+func (h *EntryHandler) GetBySlug(w http.ResponseWriter, r *http.Request, slug string) {
+	entry, current, err := h.entry.GetBySlug(slug, h.entryFilter(r))
+	if err != nil || len(current) == 0 {
+		Error(w, http.StatusNotFound, err, "failed to get entry")
+		return
+	}
+	if entry == nil {
+		location := "/api/entry/slug/" + url.PathEscape(current)
+		if len(r.URL.RawQuery) != 0 {
+			location += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return
+	}
+	respondEntry(w, r, entry)
 }
 
 // GetIDs returns entry id list
+// Filtered by the "tag", "since" and "until" query parameters
 func (h *EntryHandler) GetIDs(w http.ResponseWriter, r *http.Request) {
-	ids, err := h.entry.GetIDs()
+	f, err := h.listFilter(r)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	ids, err := h.entry.GetIDs(f)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to get entry")
 		return
@@ -52,37 +128,121 @@ func (h *EntryHandler) GetIDs(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetTitles returns entries
+// Filtered by the "tag", "since" and "until" query parameters
+// Deprecated: depends on the order of the ids, use GetTitlePage instead
 func (h *EntryHandler) GetTitles(w http.ResponseWriter, r *http.Request, start, length int) {
-	es, err := h.entry.GetTitles(start, length)
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Link", `</api/titles>; rel="successor-version"`)
+	f, err := h.listFilter(r)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	es, err := h.entry.GetTitles(f, start, length)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to get entry")
 		return
 	}
+	respondTitles(w, es)
+}
 
-	type entry struct {
-		ID    int    `json:"id"`
-		Title string `json:"title"`
+// GetTitlePage returns the page of the entries
+// Ordered by the "sort" (created_at, updated_at or title) and "order" (asc or desc) query parameters,
+// and the page is specified by the "cursor" and "limit" query parameters
+// Filtered by the "tag", "since" and "until" query parameters
+func (h *EntryHandler) GetTitlePage(w http.ResponseWriter, r *http.Request) {
+	f, err := h.listFilter(r)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	h.respondTitlePage(w, r, f)
+}
+
+// respondTitlePage responds the page of the entries matched by the filter
+// The order and the page are specified by the query parameters as well as GetTitlePage
+func (h *EntryHandler) respondTitlePage(w http.ResponseWriter, r *http.Request, f repository.EntryFilter) {
+	q := r.URL.Query()
+	s := repository.EntrySort{Key: repository.EntrySortKey(q.Get("sort"))}
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		s.Desc = true
+	default:
+		Error(w, http.StatusBadRequest, nil, "invalid query parameter")
+		return
+	}
+	limit := 0
+	if v := q.Get("limit"); len(v) != 0 {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, http.StatusBadRequest, err, "invalid query parameter")
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.entry.GetTitlePage(f, s, q.Get("cursor"), limit)
+	if err != nil {
+		if errors.Cause(err) == config.ErrInvalidPageQuery {
+			Error(w, http.StatusBadRequest, err, "invalid query parameter")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to get entry")
+		return
 	}
+
 	type response struct {
-		Data []entry `json:"data"`
+		Data  []titleEntry `json:"data"`
+		Total int          `json:"total"`
+		Next  string       `json:"next,omitempty"`
+		Prev  string       `json:"prev,omitempty"`
 	}
-	res := []entry{}
+	JSON(w, http.StatusOK, response{
+		Data:  newTitleEntries(page.Entries),
+		Total: page.Total,
+		Next:  page.Next,
+		Prev:  page.Prev,
+	})
+}
+
+// titleEntry is the entry of the title listings
+type titleEntry struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newTitleEntries(es []*domain.Entry) []titleEntry {
+	res := []titleEntry{}
 	for _, e := range es {
-		res = append(res, entry{ID: e.ID, Title: e.Title})
+		res = append(res, titleEntry{ID: e.ID, Title: e.Title, CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt})
 	}
-	JSON(w, http.StatusOK, response{Data: res})
+	return res
+}
+
+func respondTitles(w http.ResponseWriter, es []*domain.Entry) {
+	type response struct {
+		Data []titleEntry `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: newTitleEntries(es)})
 }
 
 // Post create new entry
+// The user of the token is recorded as the author, requires the user by requireUser
 func (h *EntryHandler) Post(w http.ResponseWriter, r *http.Request) {
-	if err := h.authenticate(r); err != nil {
-		Error(w, http.StatusUnauthorized, nil, "failed to authorized")
+	user := requestUser(r)
+	if !user.CanWrite() {
+		Error(w, http.StatusForbidden, nil, "insufficient privileges")
 		return
 	}
 
 	raw := struct {
-		Data   []byte `json:"data"`
-		Status int    `json:"status"`
+		Data   []byte            `json:"data"`
+		Status int               `json:"status"`
+		Tags   []string          `json:"tags"`
+		Assets map[string]string `json:"assets"`
 	}{}
 	err := json.NewDecoder(r.Body).Decode(&raw)
 	if err != nil {
@@ -90,17 +250,25 @@ func (h *EntryHandler) Post(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	element, err := application.NewEntryElement(raw.Data)
+	element, err := application.NewEntryElementWithAssets(raw.Data, domain.MergeAssetLinks(nil, raw.Assets), h.renderer)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to create new entry")
 		return
 	}
-	element.Status = domain.EntryStatus(raw.Status)
-	id, err := h.entry.Post(element)
+	element.SetDefaultStatus(domain.EntryStatus(raw.Status))
+	element.AuthorID = user.ID
+	if len(raw.Tags) != 0 {
+		element.Tags = raw.Tags
+	}
+	id, err := h.entry.WithActor(requestActor(r)).Post(element)
 	if err != nil {
-		if errors.Cause(err) == config.ErrDuplicatedTitle {
+		switch errors.Cause(err) {
+		case config.ErrDuplicatedTitle:
 			JSON(w, http.StatusNoContent, nil)
 			return
+		case config.ErrDuplicatedSlug:
+			Error(w, http.StatusConflict, err, "duplicated the entry slug")
+			return
 		}
 		Error(w, http.StatusNotFound, err, "failed to create new entry")
 		return
@@ -115,66 +283,148 @@ func (h *EntryHandler) Post(w http.ResponseWriter, r *http.Request) {
 }
 
 // Edit change entry the title and content
+// Edits only the version of the If-Match header when specified
 func (h *EntryHandler) Edit(w http.ResponseWriter, r *http.Request, id int) {
-	if err := h.authenticate(r); err != nil {
-		Error(w, http.StatusUnauthorized, nil, "failed to authorized")
-		return
-	}
-
-	entry, err := h.entry.Get(id)
-	if err != nil {
-		Error(w, http.StatusNotFound, err, fmt.Sprintf("not found entry. id:%d", id))
+	entry, ok := h.lookupEditable(w, r, id)
+	if !ok {
 		return
 	}
 
 	raw := struct {
-		Data []byte `json:"data"`
+		Data   []byte            `json:"data"`
+		Tags   []string          `json:"tags"`
+		Assets map[string]string `json:"assets"`
 	}{}
-	err = json.NewDecoder(r.Body).Decode(&raw)
+	err := json.NewDecoder(r.Body).Decode(&raw)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to parse request")
 		return
 	}
 
-	element, err := application.NewEntryElement(raw.Data)
+	// keep the assets of the links unless specified, the links may be omitted by the client
+	element, err := application.NewEntryElementWithAssets(raw.Data, domain.MergeAssetLinks(entry.Assets, raw.Assets), h.renderer)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to parse entry data")
 		return
 	}
-	element.Status = entry.Status
-	err = h.entry.Edit(id, element)
+	element.SetDefaultStatus(entry.Status)
+	element.Version, ok = ifMatchVersion(w, r, entry)
+	if !ok {
+		return
+	}
+	switch {
+	case len(raw.Tags) != 0:
+		element.Tags = raw.Tags
+	case len(element.Tags) == 0:
+		// keep the tags unless specified
+		element.Tags = entry.Tags
+	}
+	err = h.entry.WithActor(requestActor(r)).Edit(id, element)
 	if err != nil {
+		switch errors.Cause(err) {
+		case config.ErrDuplicatedSlug:
+			Error(w, http.StatusConflict, err, "duplicated the entry slug")
+			return
+		case config.ErrEntryVersionConflict:
+			Error(w, http.StatusPreconditionFailed, err, "the entry has been changed")
+			return
+		}
 		Error(w, http.StatusNotFound, err, "failed to edit entry")
 		return
 	}
+	entry.Version = element.Version
+	w.Header().Set("ETag", entry.ETag())
 	JSON(w, http.StatusOK, nil)
 }
 
 // Delete deletes entry
+// Deletes only the version of the If-Match header when specified
 func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
-	if err := h.authenticate(r); err != nil {
-		Error(w, http.StatusUnauthorized, nil, "failed to authorized")
+	entry, ok := h.lookupEditable(w, r, id)
+	if !ok {
 		return
 	}
-
-	_, err := h.entry.Get(id)
-	if err != nil {
-		Error(w, http.StatusNotFound, err, fmt.Sprintf("not found entry. id:%d", id))
+	version, ok := ifMatchVersion(w, r, entry)
+	if !ok {
 		return
 	}
 
-	err = h.entry.Delete(id)
+	err := h.entry.WithActor(requestActor(r)).Delete(id, version)
 	if err != nil {
+		if errors.Cause(err) == config.ErrEntryVersionConflict {
+			Error(w, http.StatusPreconditionFailed, err, "the entry has been changed")
+			return
+		}
 		Error(w, http.StatusNotFound, err, "failed to delete entry")
 		return
 	}
 	JSON(w, http.StatusOK, nil)
 }
 
-func (h *EntryHandler) authenticate(r *http.Request) error {
-	token := r.URL.Query().Get("token")
-	if len(token) == 0 {
-		return errors.New("invalid parmaeter")
+// ifMatchVersion returns the version of the entry required by the If-Match header, zero when not specified
+// Responds 412 with the current ETag and returns false when the entry doesn't match
+func ifMatchVersion(w http.ResponseWriter, r *http.Request, entry *domain.Entry) (int, bool) {
+	match := r.Header.Get("If-Match")
+	if len(match) == 0 {
+		return 0, true
+	}
+	for _, tag := range strings.Split(match, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == entry.ETag() {
+			return entry.Version, true
+		}
+	}
+	w.Header().Set("ETag", entry.ETag())
+	Error(w, http.StatusPreconditionFailed, nil, "the entry has been changed")
+	return 0, false
+}
+
+// entryFilter returns the filter to read the entries
+// Private entries are included only for the authorized requests
+func (h *EntryHandler) entryFilter(r *http.Request) repository.EntryFilter {
+	return repository.EntryFilter{
+		IncludePrivate: requestUser(r) != nil,
+	}
+}
+
+// listFilter returns the filter of the listings by the "tag" query parameters,
+// and the range of the created time by the "since" and "until" query parameters in RFC3339
+func (h *EntryHandler) listFilter(r *http.Request) (repository.EntryFilter, error) {
+	q := r.URL.Query()
+	f := h.entryFilter(r)
+	f.Tags = q["tag"]
+	times := map[string]**time.Time{
+		"since": &f.Since,
+		"until": &f.Until,
+	}
+	for key, dst := range times {
+		v := q.Get(key)
+		if len(v) == 0 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.Wrapf(err, "%s: %s", key, v)
+		}
+		*dst = &t
+	}
+	if f.Since != nil && f.Until != nil && !f.Since.Before(*f.Until) {
+		return f, errors.Errorf("since: %s, until: %s", f.Since, f.Until)
+	}
+	return f, nil
+}
+
+// lookupEditable returns the entry when the user of the request can edit it
+// Responds the error and returns false otherwise, requires the user by requireUser
+func (h *EntryHandler) lookupEditable(w http.ResponseWriter, r *http.Request, id int) (*domain.Entry, bool) {
+	entry, err := h.entry.Lookup(id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, fmt.Sprintf("not found entry. id:%d", id))
+		return nil, false
+	}
+	if !requestUser(r).CanEdit(entry) {
+		Error(w, http.StatusForbidden, nil, "insufficient privileges")
+		return nil, false
 	}
-	return h.auth.AuthenticateByToken(token)
+	return entry, true
 }
@@ -4,12 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"reflect"
 	"testing"
-
-	"github.com/takashabe/lumber/helper"
 )
 
 func TestGetEntry(t *testing.T) {
@@ -18,23 +17,39 @@ func TestGetEntry(t *testing.T) {
 
 	cases := []struct {
 		input      int
+		token      string
 		expectBody []byte
 		expectCode int
 	}{
 		{
 			1,
-			[]byte(`{"id":1,"title":"foo","content":"bar","status":1}`),
+			"foo",
+			[]byte(`{"id":1,"title":"foo","content":"bar","status":1,"slug":"","tags":[],"version":1,"created_at":"2018-03-01T00:00:00Z","updated_at":"2018-03-02T00:00:00Z"}`),
 			http.StatusOK,
 		},
+		{
+			1,
+			"",
+			[]byte(`{"reason":"failed to get entry"}`),
+			http.StatusNotFound,
+		},
+		{
+			1,
+			"invalid",
+			[]byte(`{"reason":"failed to get entry"}`),
+			http.StatusNotFound,
+		},
 		{
 			0,
+			"foo",
 			[]byte(`{"reason":"failed to get entry"}`),
 			http.StatusNotFound,
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
-		res := sendRequest(t, "GET", fmt.Sprintf("%s/api/entry/%d", ts.URL, c.input), nil)
+		loadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", fmt.Sprintf("%s/api/entry/%d", ts.URL, c.input), c.token, nil)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expectCode {
@@ -56,15 +71,18 @@ func TestGetIDsEntry(t *testing.T) {
 
 	cases := []struct {
 		fixture    string
+		token      string
 		expectBody []byte
 		expectCode int
 	}{
-		{"testdata/entries.yml", []byte(`{"ids":[1,2]}`), http.StatusOK},
-		{"testdata/truncate_entries.sql", []byte(`{"ids":[]}`), http.StatusOK},
+		{"testdata/entries.yml", "foo", []byte(`{"ids":[1,2]}`), http.StatusOK},
+		{"testdata/entries.yml", "", []byte(`{"ids":[]}`), http.StatusOK},
+		{"testdata/truncate_entries.sql", "foo", []byte(`{"ids":[]}`), http.StatusOK},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, c.fixture)
-		res := sendRequest(t, "GET", fmt.Sprintf("%s/api/entries", ts.URL), nil)
+		loadFixture(t, c.fixture)
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", ts.URL+"/api/entries", c.token, nil)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expectCode {
@@ -88,6 +106,7 @@ func TestGetTitlesEntry(t *testing.T) {
 		fixture    string
 		start      int
 		length     int
+		token      string
 		expectBody []byte
 		expectCode int
 	}{
@@ -95,18 +114,31 @@ func TestGetTitlesEntry(t *testing.T) {
 			"testdata/entries.yml",
 			0,
 			2,
-			[]byte(`{"data":[{"id":1,"title":"foo"},{"id":2,"title":"foo"}]}`),
+			"foo",
+			[]byte(`{"data":[{"id":1,"title":"foo","created_at":"2018-03-01T00:00:00Z","updated_at":"2018-03-02T00:00:00Z"},{"id":2,"title":"foo","created_at":"2018-03-03T00:00:00Z","updated_at":"2018-03-03T00:00:00Z"}]}`),
+			http.StatusOK,
+		},
+		{
+			"testdata/entries.yml",
+			0,
+			2,
+			"",
+			[]byte(`{"data":[]}`),
 			http.StatusOK,
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, c.fixture)
-		res := sendRequest(t, "GET", fmt.Sprintf("%s/api/titles/%d/%d", ts.URL, c.start, c.length), nil)
+		loadFixture(t, c.fixture)
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", fmt.Sprintf("%s/api/titles/%d/%d", ts.URL, c.start, c.length), c.token, nil)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expectCode {
 			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
 		}
+		if res.Header.Get("Deprecation") != "true" {
+			t.Errorf("#%d: want the deprecation header, got %q", i, res.Header.Get("Deprecation"))
+		}
 		act, err := ioutil.ReadAll(res.Body)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
@@ -117,12 +149,148 @@ func TestGetTitlesEntry(t *testing.T) {
 	}
 }
 
+func TestGetTitlePageEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/page_entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	type page struct {
+		Data []struct {
+			ID    int    `json:"id"`
+			Title string `json:"title"`
+		} `json:"data"`
+		Total int    `json:"total"`
+		Next  string `json:"next"`
+		Prev  string `json:"prev"`
+	}
+	get := func(query, token string) (*page, int) {
+		res := sendAuthRequest(t, "GET", ts.URL+"/api/titles?"+query, token, nil)
+		defer res.Body.Close()
+		p := &page{}
+		if res.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(res.Body).Decode(p); err != nil {
+				t.Fatalf("want non error, got %#v", err)
+			}
+		}
+		return p, res.StatusCode
+	}
+	ids := func(p *page) []int {
+		ids := make([]int, 0)
+		for _, e := range p.Data {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	cases := []struct {
+		query      string
+		token      string
+		expectIDs  []int
+		expectNext []int
+	}{
+		{"limit=2", "foo", []int{1, 2}, []int{3, 4}},
+		{"limit=2&sort=title&order=desc", "foo", []int{3, 5}, []int{1, 4}},
+		{"limit=3&sort=updated_at", "", []int{2, 4, 3}, []int{1}},
+	}
+	for i, c := range cases {
+		first, code := get(c.query, c.token)
+		if code != http.StatusOK {
+			t.Fatalf("#%d: want %d, got %d", i, http.StatusOK, code)
+		}
+		if !reflect.DeepEqual(ids(first), c.expectIDs) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectIDs, ids(first))
+		}
+		next, code := get(c.query+"&cursor="+first.Next, c.token)
+		if code != http.StatusOK {
+			t.Fatalf("#%d: want %d, got %d", i, http.StatusOK, code)
+		}
+		if !reflect.DeepEqual(ids(next), c.expectNext) {
+			t.Errorf("#%d: want ids %v, got %v", i, c.expectNext, ids(next))
+		}
+		if len(next.Prev) == 0 {
+			t.Errorf("#%d: want the prev cursor, got empty", i)
+		}
+	}
+
+	p, _ := get("", "")
+	if p.Total != 4 {
+		t.Errorf("want total 4 without the private entries, got %d", p.Total)
+	}
+
+	invalids := []string{"sort=unknown", "order=random", "limit=x", "limit=1000", "cursor=invalid"}
+	for i, q := range invalids {
+		if _, code := get(q, "foo"); code != http.StatusBadRequest {
+			t.Errorf("#%d: want %d, got %d", i, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestConditionalEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	payload, err := json.Marshal(map[string][]byte{"data": []byte("# edited\n\ncontent")})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	// the steps are applied in order to the entry
+	cases := []struct {
+		method     string
+		path       string
+		ifMatch    string
+		expectCode int
+		expectETag string
+	}{
+		{"GET", "/api/entry/1", "", http.StatusOK, `"1"`},
+		{"PUT", "/api/entry/1", `"1"`, http.StatusOK, `"2"`},
+		{"PUT", "/api/entry/1", `"1"`, http.StatusPreconditionFailed, `"2"`},
+		{"PUT", "/api/entry/1", `"1", "2"`, http.StatusOK, `"3"`},
+		{"PUT", "/api/entry/1", "*", http.StatusOK, `"4"`},
+		// edits any version without the header
+		{"PUT", "/api/entry/1", "", http.StatusOK, `"5"`},
+		{"GET", "/api/entry/1", "", http.StatusOK, `"5"`},
+		{"POST", "/api/entry/1/revert/1", `"4"`, http.StatusPreconditionFailed, `"5"`},
+		{"POST", "/api/entry/1/revert/1", `"5"`, http.StatusOK, `"6"`},
+		{"DELETE", "/api/entry/1", `"5"`, http.StatusPreconditionFailed, `"6"`},
+		{"DELETE", "/api/entry/1", `"6"`, http.StatusOK, ""},
+	}
+	for i, c := range cases {
+		var body io.Reader
+		if c.method == "PUT" {
+			body = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequest(c.method, ts.URL+c.path, body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		req.Header.Set("Authorization", "Bearer foo")
+		if len(c.ifMatch) != 0 {
+			req.Header.Set("If-Match", c.ifMatch)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if etag := res.Header.Get("ETag"); etag != c.expectETag {
+			t.Errorf("#%d: want ETag %s, got %s", i, c.expectETag, etag)
+		}
+	}
+}
+
 func TestPostEntry(t *testing.T) {
 	ts := setupServer(t)
 	defer ts.Close()
 
-	helper.LoadFixture(t, "testdata/entries.yml")
-	helper.LoadFixture(t, "testdata/tokens.yml")
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
 
 	type postPayload struct {
 		Data   []byte `json:"data"`
@@ -180,7 +348,7 @@ func TestPostEntry(t *testing.T) {
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		res := sendRequest(t, "POST", fmt.Sprintf("%s/api/entry/?token=%s", ts.URL, c.token), &buf)
+		res := sendAuthRequest(t, "POST", ts.URL+"/api/entry/", c.token, &buf)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expect {
@@ -234,13 +402,13 @@ func TestEditEntry(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/entries.yml")
 		var buf bytes.Buffer
 		err := json.NewEncoder(&buf).Encode(c.inputPayload)
 		if err != nil {
 			t.Fatalf("#%d: want non error, got %#v", i, err)
 		}
-		res := sendRequest(t, "PUT", fmt.Sprintf("%s/api/entry/%d?token=%s", ts.URL, c.inputID, c.token), &buf)
+		res := sendAuthRequest(t, "PUT", fmt.Sprintf("%s/api/entry/%d", ts.URL, c.inputID), c.token, &buf)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expect {
@@ -263,8 +431,8 @@ func TestDeleteEntry(t *testing.T) {
 		{1, "", http.StatusUnauthorized},
 	}
 	for i, c := range cases {
-		helper.LoadFixture(t, "testdata/entries.yml")
-		res := sendRequest(t, "DELETE", fmt.Sprintf("%s/api/entry/%d?token=%s", ts.URL, c.input, c.token), nil)
+		loadFixture(t, "testdata/entries.yml")
+		res := sendAuthRequest(t, "DELETE", fmt.Sprintf("%s/api/entry/%d", ts.URL, c.input), c.token, nil)
 		defer res.Body.Close()
 
 		if res.StatusCode != c.expect {
@@ -272,3 +440,387 @@ func TestDeleteEntry(t *testing.T) {
 		}
 	}
 }
+
+func TestRevisionsEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(map[string][]byte{"data": []byte("# title\n\n## content")})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	res := sendAuthRequest(t, "PUT", ts.URL+"/api/entry/1", "foo", &buf)
+	res.Body.Close()
+
+	cases := []struct {
+		method     string
+		path       string
+		token      string
+		expectCode int
+	}{
+		{"GET", "/api/entry/1/revisions", "foo", http.StatusOK},
+		{"GET", "/api/entry/1/revisions", "", http.StatusUnauthorized},
+		{"GET", "/api/entry/0/revisions", "foo", http.StatusNotFound},
+		{"GET", "/api/entry/1/revisions/1", "foo", http.StatusOK},
+		{"GET", "/api/entry/1/revisions/1", "", http.StatusUnauthorized},
+		{"GET", "/api/entry/1/revisions/99", "foo", http.StatusNotFound},
+		{"GET", "/api/entry/1/diff/1/2", "foo", http.StatusOK},
+		{"GET", "/api/entry/1/diff/1/2", "", http.StatusUnauthorized},
+		{"GET", "/api/entry/1/diff/1/99", "foo", http.StatusNotFound},
+		{"POST", "/api/entry/1/revert/1", "", http.StatusUnauthorized},
+		{"POST", "/api/entry/1/revert/1", "foo", http.StatusOK},
+		{"POST", "/api/entry/1/revert/99", "foo", http.StatusNotFound},
+	}
+	for i, c := range cases {
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, c.token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
+
+func TestTagsEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		path       string
+		token      string
+		expectBody []byte
+	}{
+		{
+			"/api/tags",
+			"foo",
+			[]byte(`{"data":[{"name":"blog","count":1},{"name":"go","count":2}]}`),
+		},
+		{
+			"/api/tags",
+			"",
+			[]byte(`{"data":[]}`),
+		},
+		{
+			"/api/tags/blog/entries",
+			"foo",
+			[]byte(`{"data":[{"id":1,"title":"foo","created_at":"2018-03-01T00:00:00Z","updated_at":"2018-03-02T00:00:00Z"}]}`),
+		},
+		{
+			"/api/titles/0/10?tag=go&tag=blog",
+			"foo",
+			[]byte(`{"data":[{"id":1,"title":"foo","created_at":"2018-03-01T00:00:00Z","updated_at":"2018-03-02T00:00:00Z"}]}`),
+		},
+		{
+			"/api/titles/2/10?tag=go",
+			"foo",
+			[]byte(`{"data":[{"id":2,"title":"foo","created_at":"2018-03-03T00:00:00Z","updated_at":"2018-03-03T00:00:00Z"}]}`),
+		},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/tags.yml")
+		loadFixture(t, "testdata/entry_tags.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", ts.URL+c.path, c.token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("#%d: want %d, got %d", i, http.StatusOK, res.StatusCode)
+		}
+		act, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if !reflect.DeepEqual(act, c.expectBody) {
+			t.Errorf("#%d: want %s, got %s", i, c.expectBody, act)
+		}
+	}
+}
+
+func TestGetBySlugEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	loadFixture(t, "testdata/entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	for _, slug := range []string{"first", "renamed"} {
+		var buf bytes.Buffer
+		data := fmt.Sprintf("---\nslug: %s\n---\n# title\n\n## content", slug)
+		err := json.NewEncoder(&buf).Encode(map[string][]byte{"data": []byte(data)})
+		if err != nil {
+			t.Fatalf("want non error, got %#v", err)
+		}
+		res := sendAuthRequest(t, "PUT", ts.URL+"/api/entry/1", "foo", &buf)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("want %d, got %d", http.StatusOK, res.StatusCode)
+		}
+	}
+
+	// the slug is used by the entry 1
+	var buf bytes.Buffer
+	err := json.NewEncoder(&buf).Encode(map[string][]byte{"data": []byte("---\nslug: renamed\n---\n# other\n\ncontent")})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	res := sendAuthRequest(t, "POST", ts.URL+"/api/entry/", "foo", &buf)
+	res.Body.Close()
+	if res.StatusCode != http.StatusConflict {
+		t.Errorf("want %d, got %d", http.StatusConflict, res.StatusCode)
+	}
+
+	// check the redirect response itself
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	cases := []struct {
+		slug           string
+		token          string
+		expectCode     int
+		expectLocation string
+	}{
+		{"renamed", "foo", http.StatusOK, ""},
+		{"first", "foo", http.StatusMovedPermanently, "/api/entry/slug/renamed"},
+		{"first?lang=ja", "foo", http.StatusMovedPermanently, "/api/entry/slug/renamed?lang=ja"},
+		{"unknown", "foo", http.StatusNotFound, ""},
+		// the entry is private
+		{"renamed", "", http.StatusNotFound, ""},
+		{"first", "", http.StatusNotFound, ""},
+	}
+	for i, c := range cases {
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/entry/slug/%s", ts.URL, c.slug), nil)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if len(c.token) != 0 {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if loc := res.Header.Get("Location"); loc != c.expectLocation {
+			t.Errorf("#%d: want location %s, got %s", i, c.expectLocation, loc)
+		}
+	}
+}
+
+func TestScheduledEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		method     string
+		path       string
+		expectCode int
+		expectBody []byte
+	}{
+		{"GET", "/api/entry/2", http.StatusNotFound, []byte(`{"reason":"failed to get entry"}`)},
+		{"GET", "/api/entries", http.StatusOK, []byte(`{"ids":[1]}`)},
+		{"GET", "/api/titles/0/10", http.StatusOK, []byte(`{"data":[{"id":1,"title":"foo","created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}]}`)},
+		{"DELETE", "/api/entry/2", http.StatusOK, []byte(`null`)},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/scheduled_entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		token := ""
+		if c.method != "GET" {
+			token = "foo"
+		}
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %v", i, err)
+		}
+		if !reflect.DeepEqual(body, c.expectBody) {
+			t.Errorf("#%d: want body %s, got %s", i, c.expectBody, body)
+		}
+	}
+}
+
+func TestGetEntrySource(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		input             int
+		accept            string
+		expectCode        int
+		expectContentType string
+		expectBody        []byte
+	}{
+		{
+			2,
+			"text/markdown",
+			http.StatusOK,
+			"text/markdown; charset=UTF-8",
+			[]byte("# foo\n\nbar\n"),
+		},
+		{
+			2,
+			"text/html, text/markdown;q=0.9",
+			http.StatusOK,
+			"text/markdown; charset=UTF-8",
+			[]byte("# foo\n\nbar\n"),
+		},
+		{
+			2,
+			"application/json",
+			http.StatusOK,
+			"application/json; charset=UTF-8",
+			[]byte(`{"id":2,"title":"foo","content":"bar","status":1,"slug":"","tags":[],"version":1,"created_at":"2018-03-03T00:00:00Z","updated_at":"2018-03-03T00:00:00Z"}`),
+		},
+		{
+			1,
+			"text/markdown",
+			http.StatusNotAcceptable,
+			"application/json; charset=UTF-8",
+			[]byte(`{"reason":"not found the source of the entry"}`),
+		},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/entry/%d", ts.URL, c.input), nil)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		req.Header.Set("Accept", c.accept)
+		req.Header.Set("Authorization", "Bearer foo")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if got := res.Header.Get("Content-Type"); got != c.expectContentType {
+			t.Errorf("#%d: want content type %s, got %s", i, c.expectContentType, got)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %v", i, err)
+		}
+		if !reflect.DeepEqual(body, c.expectBody) {
+			t.Errorf("#%d: want body %q, got %q", i, c.expectBody, body)
+		}
+	}
+}
+
+func TestPreviewEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		input      int
+		token      string
+		expectCode int
+	}{
+		{2, "foo", http.StatusOK},
+		{3, "foo", http.StatusOK},
+		{2, "", http.StatusUnauthorized},
+		{99, "foo", http.StatusNotFound},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/scheduled_entries.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "GET", fmt.Sprintf("%s/api/entry/%d/preview", ts.URL, c.input), c.token, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
+
+func TestAuthorizeEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	data := []byte(`{"data":"IyB0aXRsZQoKY29udGVudA=="}`)
+	cases := []struct {
+		method     string
+		path       string
+		token      string
+		body       []byte
+		expectCode int
+	}{
+		// admin and editor edit any entries, authors edit only own entries
+		{"PUT", "/api/entry/1", "admin", data, http.StatusOK},
+		{"PUT", "/api/entry/1", "editor", data, http.StatusOK},
+		{"PUT", "/api/entry/1", "author", data, http.StatusOK},
+		{"PUT", "/api/entry/2", "author", data, http.StatusForbidden},
+		{"PUT", "/api/entry/1", "viewer", data, http.StatusForbidden},
+		{"DELETE", "/api/entry/2", "author", nil, http.StatusForbidden},
+		{"DELETE", "/api/entry/1", "author", nil, http.StatusOK},
+		{"POST", "/api/entry/2/revert/1", "author", nil, http.StatusForbidden},
+		{"POST", "/api/entry/", "viewer", data, http.StatusForbidden},
+		{"POST", "/api/entry/", "author", data, http.StatusOK},
+		// viewers read the private entries
+		{"GET", "/api/entry/1/preview", "viewer", nil, http.StatusOK},
+		{"GET", "/api/entry/1/revisions", "viewer", nil, http.StatusOK},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/authored_entries.yml")
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, c.token, bytes.NewReader(c.body))
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
+
+func TestPostEntryAuthor(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		token        string
+		expectAuthor int
+	}{
+		{"author", 3},
+		// the token without the user
+		{"foo", 0},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/truncate_entries.sql")
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, "POST", ts.URL+"/api/entry/", c.token, bytes.NewReader([]byte(`{"data":"IyB0aXRsZQoKY29udGVudA=="}`)))
+		defer res.Body.Close()
+
+		var posted struct {
+			ID int `json:"id"`
+		}
+		if err := json.NewDecoder(res.Body).Decode(&posted); err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		entry, err := entryRepository.Get(posted.ID)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		if entry.AuthorID != c.expectAuthor {
+			t.Errorf("#%d: want author %d, got %d", i, c.expectAuthor, entry.AuthorID)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package interfaces
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// FeedHandler provides handler for the feeds
+type FeedHandler struct {
+	interactor *application.FeedInteractor
+}
+
+// NewFeedHandler returns initialized FeedHandler
+func NewFeedHandler(e repository.EntryRepository, site application.Site, length int) *FeedHandler {
+	return &FeedHandler{
+		interactor: application.NewFeedInteractor(e, site, length),
+	}
+}
+
+// RSS returns the RSS feed of the public entries
+func (h *FeedHandler) RSS(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.interactor.RSS()
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to generate feed")
+		return
+	}
+	respondFeed(w, r, feed)
+}
+
+// Atom returns the Atom feed of the public entries
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	feed, err := h.interactor.Atom()
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to generate feed")
+		return
+	}
+	respondFeed(w, r, feed)
+}
+
+// respondFeed writes the feed with the validators for the conditional requests
+func respondFeed(w http.ResponseWriter, r *http.Request, feed *application.Feed) {
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(feed.Body))
+	w.Header().Set("ETag", etag)
+	if !feed.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", feed.LastModified.Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, feed.LastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", feed.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(feed.Body)
+}
+
+// notModified returns whether the client has the same feed
+// If-None-Match takes precedence over If-Modified-Since
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); len(match) != 0 {
+		return match == etag || match == "*"
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
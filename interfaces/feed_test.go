@@ -0,0 +1,51 @@
+package interfaces
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFeed(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/feed_entries.yml")
+
+	for _, path := range []string{"/feed.rss", "/feed.atom"} {
+		res := sendRequest(t, "GET", ts.URL+path, nil)
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("%s: want %d, got %d", path, http.StatusOK, res.StatusCode)
+		}
+		etag := res.Header.Get("ETag")
+		if lastModified := res.Header.Get("Last-Modified"); lastModified != "Mon, 05 Mar 2018 00:00:00 GMT" {
+			t.Errorf("%s: want Last-Modified, got %s", path, lastModified)
+		}
+
+		cases := []struct {
+			header     string
+			value      string
+			expectCode int
+		}{
+			{"If-None-Match", etag, http.StatusNotModified},
+			{"If-None-Match", `"other"`, http.StatusOK},
+			{"If-Modified-Since", "Mon, 05 Mar 2018 00:00:00 GMT", http.StatusNotModified},
+			{"If-Modified-Since", "Sun, 04 Mar 2018 00:00:00 GMT", http.StatusOK},
+		}
+		for i, c := range cases {
+			req, err := http.NewRequest("GET", ts.URL+path, nil)
+			if err != nil {
+				t.Fatalf("#%d: want non error, got %#v", i, err)
+			}
+			req.Header.Set(c.header, c.value)
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("#%d: want non error, got %#v", i, err)
+			}
+			res.Body.Close()
+
+			if res.StatusCode != c.expectCode {
+				t.Errorf("%s #%d: want %d, got %d", path, i, c.expectCode, res.StatusCode)
+			}
+		}
+	}
+}
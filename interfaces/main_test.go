@@ -4,36 +4,56 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"testing"
 
+	"github.com/takashabe/lumber/application"
 	"github.com/takashabe/lumber/helper"
-	"github.com/takashabe/lumber/infrastructure/persistence"
+	"github.com/takashabe/lumber/infrastructure/memory"
+	"github.com/takashabe/lumber/infrastructure/search"
 )
 
-func TestMain(m *testing.M) {
-	helper.SetupTables()
-	os.Exit(m.Run())
+var (
+	entryRepository  = memory.NewEntryRepository()
+	tokenRepository  = memory.NewTokenRepository()
+	userRepository   = memory.NewUserRepository()
+	searchRepository = search.NewSearchRepository()
+	assetRepository  = memory.NewAssetRepository()
+	assetStorage     = memory.NewAssetStorage()
+	auditRepository  = memory.NewAuditRepository()
+
+	renderer, _ = application.NewRenderer(application.ExtensionTables)
+)
+
+func loadFixture(t *testing.T, file string) {
+	helper.LoadMemoryFixture(t, file, entryRepository, tokenRepository, userRepository)
 }
 
 func setupServer(t *testing.T) *httptest.Server {
-	entryRepo, err := persistence.NewEntryRepository()
-	tokenRepo, err := persistence.NewTokenRepository()
-	if err != nil {
-		t.Fatalf("want non error, got %#v", err)
-	}
 	server := &Server{
-		Entry: NewEntryHandler(entryRepo, tokenRepo),
-		Token: NewTokenHandler(tokenRepo),
+		Auth:  NewAuthMiddleware(tokenRepository, userRepository, false),
+		Entry: NewEntryHandler(entryRepository, searchRepository, auditRepository, renderer),
+		Token: NewTokenHandler(tokenRepository, userRepository),
+		Feed:  NewFeedHandler(entryRepository, application.Site{Title: "lumber", URL: "http://localhost"}, 10),
+		Asset: NewAssetHandler(assetRepository, assetStorage, 1024),
+		User:  NewUserHandler(userRepository),
+		Audit: NewAuditHandler(auditRepository),
 	}
 	return httptest.NewServer(server.Routes())
 }
 
 func sendRequest(t *testing.T, method, url string, body io.Reader) *http.Response {
+	return sendAuthRequest(t, method, url, "", body)
+}
+
+// sendAuthRequest sends the request with the token of the Authorization header unless empty
+func sendAuthRequest(t *testing.T, method, url, token string, body io.Reader) *http.Response {
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
 	}
+	if len(token) != 0 {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 	res, err := http.DefaultClient.Do(req)
 	if err != nil {
 		t.Fatalf("want non error, got %#v", err)
@@ -0,0 +1,80 @@
+package interfaces
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+)
+
+// GetRevisions returns revision list of the entry
+func (h *EntryHandler) GetRevisions(w http.ResponseWriter, r *http.Request, id int) {
+	revs, err := h.entry.GetRevisions(id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, fmt.Sprintf("not found entry. id:%d", id))
+		return
+	}
+
+	type response struct {
+		Data []*domain.Revision `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: revs})
+}
+
+// GetRevision returns the revision of the entry
+func (h *EntryHandler) GetRevision(w http.ResponseWriter, r *http.Request, id, revisionID int) {
+	rev, err := h.entry.GetRevision(id, revisionID)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get revision")
+		return
+	}
+	JSON(w, http.StatusOK, rev)
+}
+
+// Diff returns the unified diff between two revisions of the entry
+func (h *EntryHandler) Diff(w http.ResponseWriter, r *http.Request, id, from, to int) {
+	diff, err := h.entry.Diff(id, from, to)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get revision")
+		return
+	}
+
+	response := struct {
+		From int    `json:"from"`
+		To   int    `json:"to"`
+		Diff string `json:"diff"`
+	}{
+		From: from,
+		To:   to,
+		Diff: diff,
+	}
+	JSON(w, http.StatusOK, response)
+}
+
+// Revert rolls back the entry to the revision
+// Reverts only the version of the If-Match header when specified
+func (h *EntryHandler) Revert(w http.ResponseWriter, r *http.Request, id, revisionID int) {
+	entry, ok := h.lookupEditable(w, r, id)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(w, r, entry)
+	if !ok {
+		return
+	}
+
+	version, err := h.entry.WithActor(requestActor(r)).Revert(id, revisionID, version)
+	if err != nil {
+		if errors.Cause(err) == config.ErrEntryVersionConflict {
+			Error(w, http.StatusPreconditionFailed, err, "the entry has been changed")
+			return
+		}
+		Error(w, http.StatusNotFound, err, fmt.Sprintf("failed to revert entry. id:%d", id))
+		return
+	}
+	entry.Version = version
+	w.Header().Set("ETag", entry.ETag())
+	JSON(w, http.StatusOK, nil)
+}
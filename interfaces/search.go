@@ -0,0 +1,48 @@
+package interfaces
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Search returns the entries matched by the "q" query parameter
+// Paging by the "start" offset and the "length" query parameters
+// Filtered by the "tag", "since" and "until" query parameters
+func (h *EntryHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	q := query.Get("q")
+	if len(q) == 0 {
+		Error(w, http.StatusBadRequest, nil, "require the query")
+		return
+	}
+	start, err := intParam(query.Get("start"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid start parameter")
+		return
+	}
+	length, err := intParam(query.Get("length"))
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid length parameter")
+		return
+	}
+
+	f, err := h.listFilter(r)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	res, err := h.entry.Search(q, f, start, length)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to search entries")
+		return
+	}
+	JSON(w, http.StatusOK, res)
+}
+
+// intParam returns zero when the parameter is empty
+func intParam(s string) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
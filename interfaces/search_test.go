@@ -0,0 +1,59 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestSearchEntry(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	loadFixture(t, "testdata/scheduled_entries.yml")
+	loadFixture(t, "testdata/tokens.yml")
+	err := application.NewEntryInteractor(entryRepository, searchRepository, auditRepository).RebuildIndex()
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	cases := []struct {
+		path        string
+		expectCode  int
+		expectTotal int
+		expectIDs   []int
+	}{
+		{"/api/search?q=bar", http.StatusOK, 1, []int{1}},
+		{"/api/search?q=bar&start=1", http.StatusOK, 1, []int{}},
+		{"/api/search?q=unknown", http.StatusOK, 0, []int{}},
+		{"/api/search", http.StatusBadRequest, 0, nil},
+		{"/api/search?q=bar&length=abc", http.StatusBadRequest, 0, nil},
+	}
+	for i, c := range cases {
+		res := sendRequest(t, "GET", ts.URL+c.path, nil)
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		if res.StatusCode != http.StatusOK {
+			continue
+		}
+
+		act := &domain.SearchResult{}
+		if err := json.NewDecoder(res.Body).Decode(act); err != nil {
+			t.Fatalf("#%d: want non error, got %v", i, err)
+		}
+		ids := make([]int, 0)
+		for _, h := range act.Hits {
+			ids = append(ids, h.ID)
+		}
+		if act.Total != c.expectTotal || !reflect.DeepEqual(ids, c.expectIDs) {
+			t.Errorf("#%d: want total %d and ids %v, got %d and %v", i, c.expectTotal, c.expectIDs, act.Total, ids)
+		}
+	}
+}
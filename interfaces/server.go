@@ -81,25 +81,77 @@ func JSON(w http.ResponseWriter, code int, src interface{}) {
 
 // Server supply HTTP server
 type Server struct {
-	Entry *EntryHandler
-	Token *TokenHandler
+	Auth    *AuthMiddleware
+	Entry   *EntryHandler
+	Token   *TokenHandler
+	Feed    *FeedHandler
+	Asset   *AssetHandler
+	Webhook *WebhookHandler
+	User    *UserHandler
+	Audit   *AuditHandler
 }
 
 // Routes returns router
-func (s *Server) Routes() *router.Router {
+// The mutating routes and the revisions require the user authenticated by the Auth, except for the signed webhook
+func (s *Server) Routes() http.Handler {
 	r := router.NewRouter()
 
 	// For entries
-	r.Post("/api/entry/", s.Entry.Post)
+	r.Post("/api/entry/", requireUser(s.Entry.Post))
 	r.Get("/api/entry/:id", s.Entry.Get)
+	r.Get("/api/entry/slug/:slug", s.Entry.GetBySlug)
+	r.Get("/api/entry/:id/preview", s.Entry.Preview)
 	r.Get("/api/entries", s.Entry.GetIDs)
+	r.Get("/api/titles", s.Entry.GetTitlePage)
 	r.Get("/api/titles/:start/:length", s.Entry.GetTitles)
-	r.Put("/api/entry/:id", s.Entry.Edit)
-	r.Delete("/api/entry/:id", s.Entry.Delete)
-
-	// For tokens
-	// expect generate/get tokens, accesses from CLI on the server
-	// TODO(takashabe): Want token API to public with authenticate
+	r.Put("/api/entry/:id", requireUser(s.Entry.Edit))
+	r.Delete("/api/entry/:id", requireUser(s.Entry.Delete))
+
+	// For tags
+	r.Get("/api/tags", s.Entry.GetTags)
+	r.Get("/api/tags/:name/entries", s.Entry.GetTaggedTitles)
+
+	// For archives of the entries by the created month
+	r.Get("/api/archive", s.Entry.GetArchives)
+	r.Get("/api/archive/:year/:month", s.Entry.GetArchive)
+
+	// For searching entries
+	r.Get("/api/search", s.Entry.Search)
+
+	// For feeds of the public entries
+	r.Get("/feed.rss", s.Feed.RSS)
+	r.Get("/feed.atom", s.Feed.Atom)
+
+	// For revisions of the entry, including the private ones
+	r.Get("/api/entry/:id/revisions", requireUser(s.Entry.GetRevisions))
+	r.Get("/api/entry/:id/revisions/:revision", requireUser(s.Entry.GetRevision))
+	r.Get("/api/entry/:id/diff/:from/:to", requireUser(s.Entry.Diff))
+	r.Post("/api/entry/:id/revert/:revision", requireUser(s.Entry.Revert))
+
+	// For uploaded files
+	r.Post("/api/assets", requireUser(s.Asset.Post))
+	r.Get("/api/assets/:hash", s.Asset.GetInfo)
+	r.Get("/assets/:hash", s.Asset.Get)
+
+	// For the push to the source repository
+	r.Post("/api/webhook", s.Webhook.Push)
+
+	// For users, only for the admin
+	r.Get("/api/users", s.User.List)
+	r.Post("/api/users", requireUser(s.User.Post))
+	r.Get("/api/users/:id", s.User.Get)
+	r.Put("/api/users/:id", requireUser(s.User.Edit))
+	r.Delete("/api/users/:id", requireUser(s.User.Delete))
+
+	// For tokens, only for the admin
+	r.Get("/api/tokens", s.Token.List)
+	r.Post("/api/tokens", requireUser(s.Token.Post))
+	r.Get("/api/tokens/:id", s.Token.Get)
+	r.Delete("/api/tokens/:id", requireUser(s.Token.Delete))
+	r.Post("/api/tokens/:id/rotate", requireUser(s.Token.Rotate))
+
+	// For audit logs of the entries, only for the admin
+	r.Get("/api/audit", s.Audit.List)
 
 	// Routing of the frontend
 	// TODO(takashabe): Want to proxy SPA traffic using a web server.
@@ -111,7 +163,7 @@ func (s *Server) Routes() *router.Router {
 		http.ServeFile(w, req, fmt.Sprintf("%s/index.html", webRoot))
 	})
 
-	return r
+	return s.Auth.Handler(r)
 }
 
 // Run start server
@@ -0,0 +1,38 @@
+package interfaces
+
+import (
+	"net/http"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+// GetTags returns all tags with the number of the entries
+func (h *EntryHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.entry.GetTags(h.entryFilter(r))
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get tags")
+		return
+	}
+
+	type response struct {
+		Data []*domain.Tag `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: tags})
+}
+
+// GetTaggedTitles returns entries which have the tag
+// Filtered by the "since" and "until" query parameters
+func (h *EntryHandler) GetTaggedTitles(w http.ResponseWriter, r *http.Request, name string) {
+	f, err := h.listFilter(r)
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "invalid query parameter")
+		return
+	}
+	f.Tags = []string{name}
+	es, err := h.entry.GetTitles(f, 0, 0)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get entry")
+		return
+	}
+	respondTitles(w, es)
+}
@@ -1,26 +1,53 @@
 package interfaces
 
 import (
+	"encoding/json"
 	"net/http"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
 	"github.com/takashabe/lumber/domain/repository"
 )
 
-// TokenHandler provides handler for the token
+// TokenHandler provides handler for the token, only for the admin
 type TokenHandler struct {
 	interactor *application.TokenInteractor
 }
 
 // NewTokenHandler returns initialized TokenHandler
-func NewTokenHandler(repo repository.TokenRepository) *TokenHandler {
+func NewTokenHandler(t repository.TokenRepository, u repository.UserRepository) *TokenHandler {
 	return &TokenHandler{
-		interactor: application.NewTokenInteractor(repo),
+		interactor: application.NewTokenInteractor(t, u),
 	}
 }
 
+// List returns all tokens
+func (h *TokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	tokens, err := h.interactor.List()
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to get tokens")
+		return
+	}
+
+	type response struct {
+		Data []*domain.Token `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: tokens})
+}
+
 // Get returns token when mached id
 func (h *TokenHandler) Get(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
 	token, err := h.interactor.Get(id)
 	if err != nil {
 		Error(w, http.StatusNotFound, err, "failed to get token")
@@ -29,22 +56,68 @@ func (h *TokenHandler) Get(w http.ResponseWriter, r *http.Request, id int) {
 	JSON(w, http.StatusOK, token)
 }
 
-// FindByValue returns token when mached value
-func (h *TokenHandler) FindByValue(w http.ResponseWriter, r *http.Request, value string) {
-	token, err := h.interactor.FindByValue(value)
+// Post returns a generated token
+// The value of the token is responded only here and by the rotation
+func (h *TokenHandler) Post(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	raw := struct {
+		UserID    int        `json:"user_id"`
+		Label     string     `json:"label"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		Error(w, http.StatusBadRequest, err, "failed to parse request")
+		return
+	}
+	token, err := h.interactor.Create(raw.UserID, raw.Label, raw.ExpiresAt)
 	if err != nil {
-		Error(w, http.StatusNotFound, err, "failed to get token")
+		switch errors.Cause(err) {
+		case domain.ErrNotFoundUser:
+			Error(w, http.StatusNotFound, err, "not found the user")
+		case config.ErrInvalidTokenLabel, config.ErrInvalidTokenExpiry:
+			Error(w, http.StatusBadRequest, err, "failed to create token")
+		default:
+			Error(w, http.StatusInternalServerError, err, "failed to create token")
+		}
 		return
 	}
-	JSON(w, http.StatusOK, token)
+	JSON(w, http.StatusCreated, token)
+}
+
+// Delete revokes the token
+func (h *TokenHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	if err := h.interactor.Revoke(id); err != nil {
+		if err == domain.ErrNotFoundToken {
+			Error(w, http.StatusNotFound, err, "failed to revoke token")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to revoke token")
+		return
+	}
+	JSON(w, http.StatusOK, nil)
 }
 
-// New returns a generated token
-func (h *TokenHandler) New(w http.ResponseWriter, r *http.Request) {
-	token, err := h.interactor.New()
+// Rotate returns the token with a new value, the previous value is no longer authenticated
+func (h *TokenHandler) Rotate(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	token, err := h.interactor.Rotate(id)
 	if err != nil {
-		Error(w, http.StatusNotFound, err, "failed to create token")
+		if err == domain.ErrNotFoundToken {
+			Error(w, http.StatusNotFound, err, "failed to rotate token")
+			return
+		}
+		Error(w, http.StatusInternalServerError, err, "failed to rotate token")
 		return
 	}
-	JSON(w, http.StatusCreated, token)
+	JSON(w, http.StatusOK, token)
 }
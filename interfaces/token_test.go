@@ -0,0 +1,118 @@
+package interfaces
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/takashabe/lumber/domain"
+)
+
+func TestToken(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		method     string
+		path       string
+		token      string
+		body       string
+		expectCode int
+	}{
+		{"GET", "/api/tokens", "admin", "", http.StatusOK},
+		{"GET", "/api/tokens", "editor", "", http.StatusForbidden},
+		{"GET", "/api/tokens", "", "", http.StatusUnauthorized},
+		{"GET", "/api/tokens/5", "admin", "", http.StatusOK},
+		{"GET", "/api/tokens/99", "admin", "", http.StatusNotFound},
+		{"POST", "/api/tokens", "admin", `{"user_id":3,"label":"laptop"}`, http.StatusCreated},
+		{"POST", "/api/tokens", "admin", `{"user_id":99}`, http.StatusNotFound},
+		{"POST", "/api/tokens", "admin", `{"expires_at":"2018-01-01T00:00:00Z"}`, http.StatusBadRequest},
+		{"POST", "/api/tokens", "author", `{}`, http.StatusForbidden},
+		{"POST", "/api/tokens/5/rotate", "admin", "", http.StatusOK},
+		{"POST", "/api/tokens/99/rotate", "admin", "", http.StatusNotFound},
+		{"DELETE", "/api/tokens/5", "admin", "", http.StatusOK},
+		{"DELETE", "/api/tokens/99", "admin", "", http.StatusNotFound},
+		{"DELETE", "/api/tokens/5", "viewer", "", http.StatusForbidden},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, c.token, bytes.NewBufferString(c.body))
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
+
+func TestListToken(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	res := sendAuthRequest(t, "GET", ts.URL+"/api/tokens", "admin", nil)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	var tokens struct {
+		Data []*domain.Token `json:"data"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	if len(tokens.Data) != 6 {
+		t.Fatalf("want 6 tokens, got %s", body)
+	}
+	for i, token := range tokens.Data {
+		if len(token.Value) != 0 {
+			t.Errorf("#%d: want token without the value, got %#v", i, token)
+		}
+	}
+	// the token of the request is used
+	if tokens.Data[2].LastUsedAt == nil {
+		t.Errorf("want last_used_at of the token, got %#v", tokens.Data[2])
+	}
+}
+
+func TestCreateToken(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/authored_entries.yml")
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	res := sendAuthRequest(t, "POST", ts.URL+"/api/tokens", "admin", bytes.NewBufferString(`{"user_id":3,"label":"laptop","expires_at":"2099-01-01T00:00:00Z"}`))
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("want %d, got %d", http.StatusCreated, res.StatusCode)
+	}
+	token := &domain.Token{}
+	if err := json.NewDecoder(res.Body).Decode(token); err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	if len(token.Value) == 0 || token.UserID != 3 || token.Label != "laptop" || token.ExpiresAt == nil {
+		t.Errorf("want the token of the user, got %#v", token)
+	}
+
+	// the created token has the privileges of the user
+	cases := []struct {
+		path       string
+		expectCode int
+	}{
+		{"/api/entry/1", http.StatusOK},
+		{"/api/entry/2", http.StatusForbidden},
+	}
+	for i, c := range cases {
+		res := sendAuthRequest(t, "DELETE", ts.URL+c.path, token.Value, nil)
+		defer res.Body.Close()
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package interfaces
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/config"
+	"github.com/takashabe/lumber/domain"
+	"github.com/takashabe/lumber/domain/repository"
+)
+
+// UserHandler provides handler for the users, only for the admin
+type UserHandler struct {
+	user *application.UserInteractor
+}
+
+// NewUserHandler returns initialized UserHandler
+func NewUserHandler(u repository.UserRepository) *UserHandler {
+	return &UserHandler{
+		user: application.NewUserInteractor(u),
+	}
+}
+
+// List returns all users
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	users, err := h.user.List()
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to get users")
+		return
+	}
+
+	type response struct {
+		Data []*domain.User `json:"data"`
+	}
+	JSON(w, http.StatusOK, response{Data: users})
+}
+
+// Get returns the user matched id
+func (h *UserHandler) Get(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	user, err := h.user.Get(id)
+	if err != nil {
+		Error(w, http.StatusNotFound, err, "failed to get user")
+		return
+	}
+	JSON(w, http.StatusOK, user)
+}
+
+// userRequest represent the request body to create or update the user
+type userRequest struct {
+	Name string      `json:"name"`
+	Role domain.Role `json:"role"`
+}
+
+// Post creates a new user
+func (h *UserHandler) Post(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	raw := userRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		Error(w, http.StatusBadRequest, err, "failed to parse request")
+		return
+	}
+	user, err := h.user.Create(raw.Name, raw.Role)
+	if err != nil {
+		respondUserError(w, err, "failed to create user")
+		return
+	}
+	JSON(w, http.StatusCreated, user)
+}
+
+// Edit changes the name and the role of the user
+func (h *UserHandler) Edit(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	raw := userRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		Error(w, http.StatusBadRequest, err, "failed to parse request")
+		return
+	}
+	user, err := h.user.Update(id, raw.Name, raw.Role)
+	if err != nil {
+		respondUserError(w, err, "failed to edit user")
+		return
+	}
+	JSON(w, http.StatusOK, user)
+}
+
+// Delete deletes the user, the tokens of the user are revoked
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request, id int) {
+	if !authorizeAdmin(w, r) {
+		return
+	}
+
+	if err := h.user.Delete(id); err != nil {
+		respondUserError(w, err, "failed to delete user")
+		return
+	}
+	JSON(w, http.StatusOK, nil)
+}
+
+func respondUserError(w http.ResponseWriter, err error, msg string) {
+	switch errors.Cause(err) {
+	case domain.ErrNotFoundUser:
+		Error(w, http.StatusNotFound, err, msg)
+	case config.ErrInvalidUserName, config.ErrInvalidUserRole:
+		Error(w, http.StatusBadRequest, err, msg)
+	case domain.ErrUserAlreadyExistSameName:
+		Error(w, http.StatusConflict, err, msg)
+	default:
+		Error(w, http.StatusInternalServerError, err, msg)
+	}
+}
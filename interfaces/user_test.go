@@ -0,0 +1,75 @@
+package interfaces
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestUser(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		method     string
+		path       string
+		token      string
+		body       string
+		expectCode int
+		expectBody string
+	}{
+		{"GET", "/api/users/2", "admin", "", http.StatusOK, `{"id":2,"name":"bob","role":"editor"}`},
+		{"GET", "/api/users/9", "admin", "", http.StatusNotFound, `{"reason":"failed to get user"}`},
+		{"GET", "/api/users/2", "editor", "", http.StatusForbidden, `{"reason":"insufficient privileges"}`},
+		{"GET", "/api/users/2", "", "", http.StatusUnauthorized, `{"reason":"failed to authorized"}`},
+		// the token without the user has the privileges of the admin
+		{"GET", "/api/users/2", "foo", "", http.StatusOK, `{"id":2,"name":"bob","role":"editor"}`},
+		{"POST", "/api/users", "admin", `{"name":"erin","role":"author"}`, http.StatusCreated, `{"id":5,"name":"erin","role":"author"}`},
+		{"POST", "/api/users", "admin", `{"name":"bob","role":"author"}`, http.StatusConflict, `{"reason":"failed to create user"}`},
+		{"POST", "/api/users", "admin", `{"name":"erin","role":"owner"}`, http.StatusBadRequest, `{"reason":"failed to create user"}`},
+		{"POST", "/api/users", "author", `{"name":"erin","role":"admin"}`, http.StatusForbidden, `{"reason":"insufficient privileges"}`},
+		{"PUT", "/api/users/3", "admin", `{"name":"carol","role":"editor"}`, http.StatusOK, `{"id":3,"name":"carol","role":"editor"}`},
+		{"PUT", "/api/users/9", "admin", `{"name":"carol","role":"editor"}`, http.StatusNotFound, `{"reason":"failed to edit user"}`},
+		{"DELETE", "/api/users/3", "admin", "", http.StatusOK, `null`},
+		{"DELETE", "/api/users/9", "admin", "", http.StatusNotFound, `{"reason":"failed to delete user"}`},
+	}
+	for i, c := range cases {
+		loadFixture(t, "testdata/users.yml")
+		loadFixture(t, "testdata/tokens.yml")
+		res := sendAuthRequest(t, c.method, ts.URL+c.path, c.token, bytes.NewBufferString(c.body))
+		defer res.Body.Close()
+
+		if res.StatusCode != c.expectCode {
+			t.Errorf("#%d: want %d, got %d", i, c.expectCode, res.StatusCode)
+		}
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %v", i, err)
+		}
+		if string(body) != c.expectBody {
+			t.Errorf("#%d: want body %s, got %s", i, c.expectBody, body)
+		}
+	}
+}
+
+func TestListUser(t *testing.T) {
+	ts := setupServer(t)
+	defer ts.Close()
+	loadFixture(t, "testdata/users.yml")
+	loadFixture(t, "testdata/tokens.yml")
+
+	res := sendAuthRequest(t, "GET", ts.URL+"/api/users", "admin", nil)
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("want %d, got %d", http.StatusOK, res.StatusCode)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("want non error, got %v", err)
+	}
+	expect := `{"data":[{"id":1,"name":"alice","role":"admin"},{"id":2,"name":"bob","role":"editor"},{"id":3,"name":"carol","role":"author"},{"id":4,"name":"dave","role":"viewer"}]}`
+	if string(body) != expect {
+		t.Errorf("want body %s, got %s", expect, body)
+	}
+}
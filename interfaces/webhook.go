@@ -0,0 +1,101 @@
+package interfaces
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/takashabe/lumber/application"
+)
+
+// maxWebhookBytes is the maximum size of the webhook payload
+const maxWebhookBytes = 5 << 20
+
+// WebhookHandler provides handler for the webhooks of the git hosting services
+type WebhookHandler struct {
+	source *application.SourceInteractor
+	secret string
+}
+
+// NewWebhookHandler returns initialized WebhookHandler
+// The webhook is disabled when the source or the secret is empty
+func NewWebhookHandler(s *application.SourceInteractor, secret string) *WebhookHandler {
+	return &WebhookHandler{
+		source: s,
+		secret: secret,
+	}
+}
+
+// Push applies the push event of the source repository to the entries
+// The payload must be signed by the secret in the GitHub or Gitea format
+func (h *WebhookHandler) Push(w http.ResponseWriter, r *http.Request) {
+	if h.source == nil || len(h.secret) == 0 {
+		Error(w, http.StatusNotFound, nil, "the webhook is disabled")
+		return
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBytes+1))
+	if err != nil {
+		Error(w, http.StatusBadRequest, err, "failed to read request")
+		return
+	}
+	if len(body) > maxWebhookBytes {
+		Error(w, http.StatusRequestEntityTooLarge, nil, "the payload is too large")
+		return
+	}
+	if !verifySignature(h.secret, body, r.Header) {
+		Error(w, http.StatusUnauthorized, nil, "invalid signature")
+		return
+	}
+	// e.g. the ping event on registering the webhook
+	if event := webhookEvent(r.Header); len(event) != 0 && event != "push" {
+		JSON(w, http.StatusOK, &application.PushResult{Ignored: true})
+		return
+	}
+
+	event := &application.PushEvent{}
+	if err := json.Unmarshal(body, event); err != nil {
+		Error(w, http.StatusBadRequest, err, "failed to parse request")
+		return
+	}
+	res, err := h.source.ApplyPush(event)
+	if err != nil {
+		Error(w, http.StatusInternalServerError, err, "failed to apply the push")
+		return
+	}
+	JSON(w, http.StatusOK, res)
+}
+
+// verifySignature reports whether the body is signed by the HMAC-SHA256 of the secret
+// Accepts "X-Hub-Signature-256: sha256=<hex>" of GitHub and "X-Gitea-Signature: <hex>" of Gitea
+func verifySignature(secret string, body []byte, header http.Header) bool {
+	sig := header.Get("X-Hub-Signature-256")
+	if len(sig) != 0 {
+		if !strings.HasPrefix(sig, "sha256=") {
+			return false
+		}
+		sig = strings.TrimPrefix(sig, "sha256=")
+	} else {
+		sig = header.Get("X-Gitea-Signature")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func webhookEvent(header http.Header) string {
+	if event := header.Get("X-GitHub-Event"); len(event) != 0 {
+		return event
+	}
+	return header.Get("X-Gitea-Event")
+}
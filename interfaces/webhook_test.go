@@ -0,0 +1,113 @@
+package interfaces
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/takashabe/lumber/application"
+	"github.com/takashabe/lumber/domain/repository"
+	"github.com/takashabe/lumber/helper"
+	"github.com/takashabe/lumber/infrastructure/git"
+	"github.com/takashabe/lumber/infrastructure/memory"
+)
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhookPush(t *testing.T) {
+	loadFixture(t, "testdata/truncate_entries.sql")
+	fixture := helper.NewGitFixture(t)
+	defer fixture.Close()
+	revision := fixture.Commit(map[string]string{"a.md": "webhook_title\n\ncontent"})
+	source, err := git.NewRepository(fixture.Bare)
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+
+	entry := application.NewEntryInteractor(entryRepository, searchRepository, auditRepository)
+	interactor := application.NewSourceInteractor(
+		entry,
+		application.NewAssetInteractor(assetRepository, assetStorage, 1024),
+		memory.NewEntryPathRepository(),
+		source,
+		renderer,
+		"master",
+	)
+	auth := NewAuthMiddleware(tokenRepository, userRepository, false)
+	enabled := httptest.NewServer((&Server{Auth: auth, Webhook: NewWebhookHandler(interactor, "secret")}).Routes())
+	defer enabled.Close()
+	disabled := httptest.NewServer((&Server{Auth: auth, Webhook: NewWebhookHandler(nil, "")}).Routes())
+	defer disabled.Close()
+
+	body := []byte(fmt.Sprintf(`{"ref":"refs/heads/master","after":"%s","commits":[{"added":["a.md"]}]}`, revision))
+	cases := []struct {
+		url        string
+		header     map[string]string
+		expectCode int
+		expectBody string
+	}{
+		{disabled.URL, map[string]string{"X-Hub-Signature-256": "sha256=" + sign("secret", body)}, http.StatusNotFound, `{"reason":"the webhook is disabled"}`},
+		{enabled.URL, map[string]string{}, http.StatusUnauthorized, `{"reason":"invalid signature"}`},
+		{enabled.URL, map[string]string{"X-Hub-Signature-256": "sha256=" + sign("invalid", body)}, http.StatusUnauthorized, `{"reason":"invalid signature"}`},
+		{enabled.URL, map[string]string{"X-Hub-Signature-256": sign("secret", body)}, http.StatusUnauthorized, `{"reason":"invalid signature"}`},
+		{
+			enabled.URL,
+			map[string]string{"X-Hub-Signature-256": "sha256=" + sign("secret", body), "X-GitHub-Event": "ping"},
+			http.StatusOK,
+			`{"created":0,"edited":0,"deleted":0,"ignored":true}`,
+		},
+		{
+			enabled.URL,
+			map[string]string{"X-Hub-Signature-256": "sha256=" + sign("secret", body), "X-GitHub-Event": "push"},
+			http.StatusOK,
+			`{"created":1,"edited":0,"deleted":0,"ignored":false}`,
+		},
+		{
+			enabled.URL,
+			map[string]string{"X-Gitea-Signature": sign("secret", body), "X-Gitea-Event": "push"},
+			http.StatusOK,
+			`{"created":0,"edited":1,"deleted":0,"ignored":false}`,
+		},
+	}
+	for i, c := range cases {
+		req, err := http.NewRequest("POST", c.url+"/api/webhook", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		for k, v := range c.header {
+			req.Header.Set(k, v)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("#%d: want non error, got %#v", i, err)
+		}
+		defer res.Body.Close()
+
+		var buf bytes.Buffer
+		buf.ReadFrom(res.Body)
+		if res.StatusCode != c.expectCode || buf.String() != c.expectBody {
+			t.Errorf("#%d: want %d and %s, got %d and %s", i, c.expectCode, c.expectBody, res.StatusCode, buf.String())
+		}
+	}
+
+	ids, err := entry.GetIDs(repository.EntryFilter{IncludePrivate: true})
+	if err != nil {
+		t.Fatalf("want non error, got %#v", err)
+	}
+	if len(ids) != 1 {
+		t.Fatalf("want an entry, got %v", ids)
+	}
+	e, err := entry.Lookup(ids[0])
+	if err != nil || e.Title != "webhook_title" {
+		t.Errorf("want title webhook_title, got %#v and %#v", e, err)
+	}
+}
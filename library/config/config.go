@@ -10,6 +10,9 @@ import (
 // Config represent configuration
 var Config = struct {
 	DB struct {
+		// Driver is either "mysql" or "sqlite3"
+		Driver   string `default:"mysql" env:"LUMBER_DB_DRIVER"`
+		Path     string `env:"LUMBER_DB_PATH"` // only used by sqlite3
 		Name     string `env:"LUMBER_DB_NAME"`
 		Host     string `env:"LUMBER_DB_HOST"`
 		User     string `env:"LUMBER_DB_USER"`
@@ -19,6 +22,60 @@ var Config = struct {
 
 	Server struct {
 		Port int `default:"8080" env:"LUMBER_SERVER_PORT"`
+		// PublishInterval is seconds to check the scheduled entries
+		PublishInterval int `default:"60" env:"LUMBER_SERVER_PUBLISH_INTERVAL"`
+		// AllowQueryToken accepts the token of the "token" query parameter in addition to the Authorization header
+		// Deprecated: the token in the URL is recorded by the access logs and the proxies
+		AllowQueryToken bool `env:"LUMBER_SERVER_ALLOW_QUERY_TOKEN"`
+	}
+
+	// Site is the metadata of the blog used by the feeds
+	Site struct {
+		Title       string `default:"lumber" env:"LUMBER_SITE_TITLE"`
+		Description string `env:"LUMBER_SITE_DESCRIPTION"`
+		URL         string `default:"http://localhost:8080" env:"LUMBER_SITE_URL"`
+		Author      string `env:"LUMBER_SITE_AUTHOR"`
+		// FeedLength is the number of the entries in the feeds
+		FeedLength int `default:"20" env:"LUMBER_SITE_FEED_LENGTH"`
+	}
+
+	// Renderer is the markdown rendering of the entries
+	Renderer struct {
+		// Extensions is the comma separated markdown extensions
+		// "tables", "footnotes", "anchors", "toc", "highlight" and "emoji" are available
+		Extensions string `default:"tables" env:"LUMBER_RENDERER_EXTENSIONS"`
+	}
+
+	// Sanitizer is the policy of the HTML allowed in the entries
+	Sanitizer struct {
+		// Elements is the comma separated elements allowed in addition to the defaults
+		// The attributes are specified with colons like "abbr:title"
+		Elements string `env:"LUMBER_SANITIZER_ELEMENTS"`
+		// IframeHosts is the comma separated hosts of the iframes like "www.youtube.com,*.vimeo.com"
+		IframeHosts string `env:"LUMBER_SANITIZER_IFRAME_HOSTS"`
+		// EmbedHosts is the comma separated hosts of the embed and object elements
+		EmbedHosts string `env:"LUMBER_SANITIZER_EMBED_HOSTS"`
+	}
+
+	// Asset is the storage of the uploaded files
+	Asset struct {
+		// Storage is the backend of the files, only "local" is available
+		Storage string `default:"local" env:"LUMBER_ASSET_STORAGE"`
+		// Dir is the directory of the files used by the local storage
+		Dir string `default:"assets" env:"LUMBER_ASSET_DIR"`
+		// MaxBytes is the maximum size of a file
+		MaxBytes int64 `default:"10485760" env:"LUMBER_ASSET_MAX_BYTES"`
+	}
+
+	// Webhook is the push from the git repository of the entries
+	// The webhook is enabled when both of the secret and the repository are specified
+	Webhook struct {
+		// Secret is the key to verify the signature of the payload
+		Secret string `env:"LUMBER_WEBHOOK_SECRET"`
+		// Repository is the path of the local repository to read the files, usually bare
+		Repository string `env:"LUMBER_WEBHOOK_REPOSITORY"`
+		// Branch is the branch to apply the push
+		Branch string `default:"master" env:"LUMBER_WEBHOOK_BRANCH"`
 	}
 }{}
 